@@ -0,0 +1,84 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/service"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+type IGlossaryApi interface {
+	CreateGlossary(c *gin.Context) error
+	UpdateGlossary(c *gin.Context) error
+	DeleteGlossary(c *gin.Context) error
+	GetGlossaryDetail(c *gin.Context) error
+	ListGlossary(c *gin.Context) error
+}
+
+func NewGlossaryApi(glossaryService service.IGlossaryService) IGlossaryApi {
+	return &glossaryApi{glossaryService: glossaryService}
+}
+
+type glossaryApi struct {
+	glossaryService service.IGlossaryService
+}
+
+func (g *glossaryApi) CreateGlossary(c *gin.Context) error {
+	var req = &request_mapping.CreateGlossaryReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	err := g.glossaryService.CreateGlossary(c.GetString("username"), req)
+	if err != nil {
+		return err
+	}
+	return unify_response.NewOk()
+}
+
+func (g *glossaryApi) UpdateGlossary(c *gin.Context) error {
+	var req = &request_mapping.UpdateGlossaryReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	err := g.glossaryService.UpdateGlossary(c.GetString("username"), req)
+	if err != nil {
+		return err
+	}
+	return unify_response.NewOk()
+}
+
+func (g *glossaryApi) DeleteGlossary(c *gin.Context) error {
+	var req = &request_mapping.DeleteGlossaryReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	err := g.glossaryService.DeleteGlossary(c.GetString("username"), req.Id)
+	if err != nil {
+		return err
+	}
+	return unify_response.NewOk()
+}
+
+func (g *glossaryApi) GetGlossaryDetail(c *gin.Context) error {
+	var req = &request_mapping.GlossaryDetailReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	detail, err := g.glossaryService.GetGlossaryDetail(c.GetString("username"), req.Id)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetObjectSuccess(detail)
+}
+
+func (g *glossaryApi) ListGlossary(c *gin.Context) error {
+	var req = &request_mapping.ListGlossaryReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	list, count, err := g.glossaryService.ListGlossary(c.GetString("username"), req)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetListSuccess(list, count, "")
+}
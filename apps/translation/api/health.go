@@ -0,0 +1,23 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/service"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+type IHealthApi interface {
+	GetLLMHealth(c *gin.Context) error
+}
+
+func NewHealthApi(healthService service.IHealthService) IHealthApi {
+	return &healthApi{healthService: healthService}
+}
+
+type healthApi struct {
+	healthService service.IHealthService
+}
+
+func (h *healthApi) GetLLMHealth(c *gin.Context) error {
+	return unify_response.GetObjectSuccess(h.healthService.GetLLMHealth())
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/language"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+type ILanguageApi interface {
+	ListLanguages(c *gin.Context) error
+}
+
+func NewLanguageApi() ILanguageApi {
+	return &languageApi{}
+}
+
+type languageApi struct{}
+
+// ListLanguages 返回支持的常用语言目录
+func (l *languageApi) ListLanguages(c *gin.Context) error {
+	list := language.Catalog()
+	return unify_response.GetListSuccess(list, int64(len(list)), "")
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/service"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+type IPromptTemplateApi interface {
+	CreatePromptTemplate(c *gin.Context) error
+	GetPromptTemplateDetail(c *gin.Context) error
+	ListPromptTemplate(c *gin.Context) error
+	ListTemplateTasks(c *gin.Context) error
+	GetStyleOptions(c *gin.Context) error
+}
+
+func NewPromptTemplateApi(templateService service.IPromptTemplateService) IPromptTemplateApi {
+	return &promptTemplateApi{templateService: templateService}
+}
+
+type promptTemplateApi struct {
+	templateService service.IPromptTemplateService
+}
+
+func (p *promptTemplateApi) CreatePromptTemplate(c *gin.Context) error {
+	var req = &request_mapping.CreatePromptTemplateReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	template, err := p.templateService.CreatePromptTemplate(c.GetString("username"), req)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetObjectSuccess(template)
+}
+
+func (p *promptTemplateApi) GetPromptTemplateDetail(c *gin.Context) error {
+	var req = &request_mapping.PromptTemplateDetailReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	template, err := p.templateService.GetPromptTemplate(req.Name, req.Version)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetObjectSuccess(template)
+}
+
+func (p *promptTemplateApi) ListPromptTemplate(c *gin.Context) error {
+	var req = &request_mapping.ListPromptTemplateReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	list, count, err := p.templateService.ListPromptTemplates(req)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetListSuccess(list, count, "")
+}
+
+func (p *promptTemplateApi) ListTemplateTasks(c *gin.Context) error {
+	var req = &request_mapping.ListTemplateTasksReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	list, count, err := p.templateService.ListTemplateTasks(req)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetListSuccess(list, count, "")
+}
+
+func (p *promptTemplateApi) GetStyleOptions(c *gin.Context) error {
+	return unify_response.GetObjectSuccess(p.templateService.GetStyleOptions())
+}
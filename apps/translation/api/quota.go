@@ -0,0 +1,78 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/service"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+type IQuotaApi interface {
+	CreatePlan(c *gin.Context) error
+	UpdatePlan(c *gin.Context) error
+	ListPlans(c *gin.Context) error
+	AssignPlan(c *gin.Context) error
+	GetBalance(c *gin.Context) error
+}
+
+func NewQuotaApi(quotaService service.IQuotaService) IQuotaApi {
+	return &quotaApi{quotaService: quotaService}
+}
+
+type quotaApi struct {
+	quotaService service.IQuotaService
+}
+
+func (q *quotaApi) CreatePlan(c *gin.Context) error {
+	var req = &request_mapping.CreateQuotaPlanReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	plan, err := q.quotaService.CreatePlan(req)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetObjectSuccess(plan)
+}
+
+func (q *quotaApi) UpdatePlan(c *gin.Context) error {
+	var req = &request_mapping.UpdateQuotaPlanReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	if err := q.quotaService.UpdatePlan(req); err != nil {
+		return err
+	}
+	return unify_response.NewOk()
+}
+
+func (q *quotaApi) ListPlans(c *gin.Context) error {
+	list, err := q.quotaService.ListPlans()
+	if err != nil {
+		return err
+	}
+	return unify_response.GetListSuccess(list, int64(len(list)), "")
+}
+
+func (q *quotaApi) AssignPlan(c *gin.Context) error {
+	var req = &request_mapping.AssignQuotaPlanReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	if err := q.quotaService.AssignPlan(req); err != nil {
+		return err
+	}
+	return unify_response.NewOk()
+}
+
+func (q *quotaApi) GetBalance(c *gin.Context) error {
+	var req = &request_mapping.QuotaBalanceReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	balance, err := q.quotaService.GetBalance(c.GetString("username"), req)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetObjectSuccess(balance)
+}
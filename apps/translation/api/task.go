@@ -1,23 +1,32 @@
 package api
 
 import (
+	"archive/zip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
 	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/service"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/strutil"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"go.uber.org/zap"
+	"io"
 	"net/http"
+	"os"
 	"strconv"
 	"sync"
+	"time"
 )
 
 type ITaskApi interface {
 	CreateTask(c *gin.Context) error
+	UploadTask(c *gin.Context) error
 	ExecTask(c *gin.Context) error
 	GetTaskDetail(c *gin.Context) error
+	ListTasks(c *gin.Context) error
 	DownloadTask(c *gin.Context) error
 	WatchTaskStatus(c *gin.Context) error
 }
@@ -38,10 +47,31 @@ type taskApi struct {
 	connections            map[string]*webSocketClient
 }
 
+const (
+	// wsSendBuffer 每个连接待发送的消息数，写满说明客户端过慢，断开连接
+	wsSendBuffer = 256
+	// wsWriteTimeout 单条消息的写超时
+	wsWriteTimeout = 10 * time.Second
+)
+
 type webSocketClient struct {
 	Conn     *websocket.Conn
 	Username string
 	ClientId string
+	// send 待发送的消息，由 writeLoop 写入连接，广播时不直接写连接，避免慢客户端阻塞其它客户端
+	send chan []byte
+}
+
+// writeLoop 依次发送消息，写失败时关闭连接，读协程随之退出并移除该客户端
+func (w *webSocketClient) writeLoop() {
+	for data := range w.send {
+		_ = w.Conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := w.Conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			logger.Warn("write websocket message failed", zap.String("client_id", w.ClientId), zap.Error(err))
+			w.Conn.Close()
+			return
+		}
+	}
 }
 
 func (t *taskApi) CreateTask(c *gin.Context) error {
@@ -50,11 +80,23 @@ func (t *taskApi) CreateTask(c *gin.Context) error {
 	if err != nil {
 		return err
 	}
-	err = t.taskService.CreateTask(c.GetString("username"), req)
+	task, err := t.taskService.CreateTask(c.GetString("username"), req)
 	if err != nil {
 		return err
 	}
-	return unify_response.NewOk()
+	return unify_response.GetObjectSuccess(task)
+}
+
+func (t *taskApi) UploadTask(c *gin.Context) error {
+	var req = &request_mapping.UploadTaskReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	task, err := t.taskService.UploadTask(c.GetString("username"), req)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetObjectSuccess(task)
 }
 
 func (t *taskApi) ExecTask(c *gin.Context) error {
@@ -70,6 +112,18 @@ func (t *taskApi) ExecTask(c *gin.Context) error {
 	}
 	return unify_response.NewOk()
 }
+func (t *taskApi) ListTasks(c *gin.Context) error {
+	var req = &request_mapping.ListTaskReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	list, count, err := t.taskService.ListTasks(c.GetString("username"), req)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetListSuccess(list, count, "")
+}
+
 func (t *taskApi) GetTaskDetail(c *gin.Context) error {
 	id := c.Query("id")
 	username := c.GetString("username")
@@ -87,27 +141,77 @@ func (t *taskApi) GetTaskDetail(c *gin.Context) error {
 	return unify_response.GetObjectSuccess(detail)
 }
 
+// DownloadTask 下载译文，多目标语言任务未指定 lang 时返回所有已完成语言的 zip 压缩包
 func (t *taskApi) DownloadTask(c *gin.Context) error {
 	username := c.GetString("username")
-	id := c.Query("id")
-	idInt, err := strconv.ParseInt(id, 10, 64)
-	if err != nil {
-		return unify_response.ParameterError("任务ID不能为空")
+	req := &request_mapping.DownloadTaskReq{}
+	if err := req.Validate(c); err != nil {
+		return err
 	}
-	done, resultPath, err := t.taskService.GetTaskIsDoneAndFilePath(idInt, username)
+	files, archive, err := t.taskService.GetTaskResultFiles(username, req.Id, req.Lang, req.Format)
 	if err != nil {
 		return err
 	}
-	if done {
-		// 设置响应头，提示下载文件
+	if !archive {
+		file := files[0]
+		if file.Content != nil {
+			c.Header("Content-Description", "File Transfer")
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Filename))
+			c.Data(http.StatusOK, file.ContentType, file.Content)
+			return nil
+		}
+		if _, err := os.Stat(file.Path); err != nil {
+			return unify_response.NotFound()
+		}
 		c.Header("Content-Description", "File Transfer")
-		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", resultPath))
-		c.Header("Content-Type", "application/octet-stream")
-		c.Data(http.StatusOK, "application/octet-stream", []byte(resultPath))
+		c.FileAttachment(file.Path, file.Filename)
 		return nil
-	} else {
-		return unify_response.ParameterError("任务未完成")
 	}
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=task-%d.zip", req.Id))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+	if err := writeResultArchive(c.Writer, files); err != nil {
+		// 响应头已发出，只能记录日志
+		logger.Error("write result archive failed", zap.Int64("task_id", req.Id), zap.Error(err))
+	}
+	return nil
+}
+
+// writeResultArchive 把各语言的译文写入 zip，缺失的文件跳过
+func writeResultArchive(w io.Writer, files []*service.ResultFile) error {
+	zw := zip.NewWriter(w)
+	for _, file := range files {
+		if err := addArchiveFile(zw, file); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func addArchiveFile(zw *zip.Writer, file *service.ResultFile) error {
+	if file.Content != nil {
+		dst, err := zw.Create(file.Filename)
+		if err != nil {
+			return err
+		}
+		_, err = dst.Write(file.Content)
+		return err
+	}
+	src, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := zw.Create(file.Filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
 }
 
 var upgrader = websocket.Upgrader{
@@ -125,7 +229,15 @@ var locker = &sync.Mutex{}
 func (t *taskApi) removeConnection(id string) {
 	locker.Lock()
 	defer locker.Unlock()
-	delete(t.connections, id)
+	t.dropConnection(id)
+}
+
+// dropConnection 调用方需持有 locker
+func (t *taskApi) dropConnection(id string) {
+	if client, ok := t.connections[id]; ok {
+		delete(t.connections, id)
+		close(client.send)
+	}
 }
 
 func (t *taskApi) addConnection(id string, conn *webSocketClient) {
@@ -135,14 +247,29 @@ func (t *taskApi) addConnection(id string, conn *webSocketClient) {
 }
 
 // 定义一个用于广播消息的函数
+// 消息包括任务状态(type=status)、分段增量译文(type=delta)以及增量作废(type=reset)，只推送给任务所属用户
 func (t *taskApi) broadcastMessage(message map[string]any) {
-	for _, wsConn := range t.connections {
-		username, ok := message["username"].(string)
-		if ok && username == wsConn.Username {
-			dataBytes, err := json.Marshal(message)
-			if err == nil {
-				wsConn.Conn.WriteMessage(websocket.TextMessage, dataBytes)
-			}
+	username, ok := message["username"].(string)
+	if !ok {
+		return
+	}
+	dataBytes, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	locker.Lock()
+	defer locker.Unlock()
+	for id, wsConn := range t.connections {
+		if username != wsConn.Username {
+			continue
+		}
+		select {
+		case wsConn.send <- dataBytes:
+		default:
+			// 客户端跟不上推送，断开后由客户端重连并重新查询任务状态
+			logger.Warn("websocket client too slow, disconnect", zap.String("client_id", id))
+			t.dropConnection(id)
+			wsConn.Conn.Close()
 		}
 	}
 }
@@ -160,8 +287,10 @@ func (t *taskApi) WatchTaskStatus(c *gin.Context) error {
 		Conn:     conn,
 		Username: username,
 		ClientId: clientId,
+		send:     make(chan []byte, wsSendBuffer),
 	}
 	t.addConnection(clientId, wsClient)
+	go wsClient.writeLoop()
 	ch := make(chan struct{}, 1)
 	go func() {
 		for {
@@ -175,6 +304,8 @@ func (t *taskApi) WatchTaskStatus(c *gin.Context) error {
 
 		}
 	}()
+	// 保持连接直到客户端断开，推送由 pushMessage 完成
+	<-ch
 	return nil
 }
 
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/service"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+type IUsageApi interface {
+	GetUsage(c *gin.Context) error
+}
+
+func NewUsageApi(usageService service.IUsageService) IUsageApi {
+	return &usageApi{usageService: usageService}
+}
+
+type usageApi struct {
+	usageService service.IUsageService
+}
+
+func (u *usageApi) GetUsage(c *gin.Context) error {
+	var req = &request_mapping.UsageReq{}
+	if err := req.Validate(c); err != nil {
+		return err
+	}
+	report, err := u.usageService.GetUsage(c.GetString("username"), req)
+	if err != nil {
+		return err
+	}
+	return unify_response.GetObjectSuccess(report)
+}
@@ -6,6 +6,12 @@ import (
 	"io/ioutil"
 )
 
+// RunModeDev 开发环境，ProviderTypeFake 为不发出网络请求的服务商类型
+const (
+	RunModeDev       = "dev"
+	ProviderTypeFake = "fake"
+)
+
 type Config struct {
 	Addr          string           `yaml:"port"`
 	RunMode       string           `yaml:"run_mode"`
@@ -14,6 +20,33 @@ type Config struct {
 	RedisConfig   *redisConfig     `yaml:"redis_config"`
 	MysqlConfig   *mysqlConfig     `yaml:"mysql_config"`
 	RateLimit     *rateLimitConfig `yaml:"rate_limit"`
+	LLMConfig     *llmConfig       `yaml:"llm_config"`
+	LLMRouter     *llmRouterConfig `yaml:"llm_router"`
+	Chunk         *chunkConfig     `yaml:"chunk"`
+	// TranslationMemory 翻译记忆配置，未配置时使用默认值并启用
+	TranslationMemory *translationMemoryConfig `yaml:"translation_memory"`
+	// LangDetect 源语言自动识别配置
+	LangDetect *langDetectConfig `yaml:"lang_detect"`
+	// PromptTemplates 配置文件中的提示词模板，版本号视为 0，数据库中存在同名模板时以数据库为准
+	PromptTemplates []*promptTemplateConfig `yaml:"prompt_templates"`
+	// Quality 任务完成后的译文质量评估，默认关闭
+	Quality *qualityConfig `yaml:"quality"`
+	// Pricing 模型价格表，用于计算每次调用的费用
+	Pricing *pricingConfig `yaml:"pricing"`
+	// Quota 用户配额配置
+	Quota *quotaConfig `yaml:"quota"`
+	// LLMCache 译文缓存，默认使用进程内 LRU
+	LLMCache *llmCacheConfig `yaml:"llm_cache"`
+	// LLMFixture 录制或回放服务商的请求与响应，回放时不发出网络请求
+	LLMFixture *llmFixtureConfig `yaml:"llm_fixture"`
+	// DocumentContext 引用前序任务作为示例时的 token 预算
+	DocumentContext *documentContextConfig `yaml:"document_context"`
+	// Upload 上传文件创建任务的限制
+	Upload *uploadConfig `yaml:"upload"`
+	// TaskWorker 执行翻译任务的协程数与排队长度
+	TaskWorker *taskWorkerConfig `yaml:"task_worker"`
+	// LLMResilience 服务商调用的超时、重试与熔断策略，默认开启
+	LLMResilience *llmResilienceConfig `yaml:"llm_resilience"`
 }
 
 type redisConfig struct {
@@ -36,12 +69,148 @@ type rateLimitConfig struct {
 	Limit int `yaml:"limit"`
 }
 
+type llmConfig struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type"`     // 为空时为 OpenAI 兼容接口，fake 为不发出网络请求的确定性译文，用于开发与离线测试
+	BaseURL string `yaml:"base_url"` // 需包含版本前缀，如 https://api.openai.com/v1
+	Model   string `yaml:"model"`
+	ApiKey  string `yaml:"api_key"`
+	Timeout int    `yaml:"timeout"` // 单次请求超时时间，单位秒
+	// RPM、TPM 每分钟的请求数与 token 数上限，MaxInFlight 为同时进行中的请求数上限，0 表示不限制
+	// 超出时请求在本地排队，而不是触发服务商的 429
+	RPM         int `yaml:"rpm"`
+	TPM         int `yaml:"tpm"`
+	MaxInFlight int `yaml:"max_in_flight"`
+}
+
+// llmRouterConfig 多服务商配置，配置后 llm_config 将被忽略
+type llmRouterConfig struct {
+	Providers        []*llmConfig      `yaml:"providers"`
+	Routes           []*llmRouteConfig `yaml:"routes"`
+	DefaultProviders []string          `yaml:"default_providers"`
+}
+
+type llmRouteConfig struct {
+	Name        string   `yaml:"name"`
+	SourceLangs []string `yaml:"source_langs"`
+	TargetLangs []string `yaml:"target_langs"`
+	Users       []string `yaml:"users"`
+	MinLength   int      `yaml:"min_length"` // 内容字符数下限，0 表示不限制
+	MaxLength   int      `yaml:"max_length"` // 内容字符数上限，0 表示不限制
+	Providers   []string `yaml:"providers"`  // 按顺序尝试，前一个失败时回退到下一个
+}
+
+// chunkConfig 长文本分段翻译配置，未配置的字段使用默认值
+type chunkConfig struct {
+	MaxTokens     int  `yaml:"max_tokens"`     // 每段的 token 预算，0 使用默认值
+	OverlapTokens *int `yaml:"overlap_tokens"` // 提供给下一段作为上下文的 token 数，0 表示不提供
+	Concurrency   int  `yaml:"concurrency"`    // 同一任务并行翻译的段数，0 使用默认值
+	MaxRetries    *int `yaml:"max_retries"`    // 单段译文不符合要求(占位符、字幕分隔)时重新生成的次数，0 表示不重试
+}
+
+type translationMemoryConfig struct {
+	Disabled       bool    `yaml:"disabled"`
+	FuzzyThreshold float64 `yaml:"fuzzy_threshold"` // 模糊匹配的相似度阈值，默认 0.85
+	CandidateLimit int     `yaml:"candidate_limit"` // 每个分段参与模糊匹配的候选记忆数
+	MaxReferences  int     `yaml:"max_references"`  // 每个分段最多提供给模型的参考译文数
+}
+
+type promptTemplateConfig struct {
+	Name        string `yaml:"name"`
+	Content     string `yaml:"content"`
+	Description string `yaml:"description"`
+}
+
+type langDetectConfig struct {
+	MinConfidence float64 `yaml:"min_confidence"` // 置信度低于该值时仍交给模型自行判断，默认 0.8
+	MinLength     int     `yaml:"min_length"`     // 内容字符数低于该值时仍交给模型自行判断，默认 20
+}
+
+// qualityConfig 回译与模型评分都会额外调用模型，可分别关闭
+type qualityConfig struct {
+	Enabled                bool `yaml:"enabled"`
+	DisableBackTranslation bool `yaml:"disable_back_translation"`
+	DisableJudge           bool `yaml:"disable_judge"`
+	JudgeMaxChars          int  `yaml:"judge_max_chars"` // 提交给模型评分的原文字符数上限，默认 4000
+}
+
+type pricingConfig struct {
+	Currency string         `yaml:"currency"` // 默认 USD
+	Prices   []*priceConfig `yaml:"prices"`
+}
+
+// priceConfig 单价为每百万 token 的价格，Model 为空时匹配该服务商的所有模型
+// Model 按前缀匹配，例如 gpt-4o-mini 可匹配 gpt-4o-mini-2024-07-18，多条匹配时取最长的
+type priceConfig struct {
+	Provider        string  `yaml:"provider"`
+	Model           string  `yaml:"model"`
+	PromptPrice     float64 `yaml:"prompt_price"`
+	CompletionPrice float64 `yaml:"completion_price"`
+}
+
+type quotaConfig struct {
+	Disabled    bool   `yaml:"disabled"`
+	DefaultPlan string `yaml:"default_plan"` // 未分配套餐的用户使用的套餐名称，为空时不限制
+}
+
+type llmCacheConfig struct {
+	Disabled bool `yaml:"disabled"`
+	Size     int  `yaml:"size"`  // 进程内缓存的条目数，默认 10000
+	TTL      int  `yaml:"ttl"`   // 过期时间，单位秒，默认 7 天
+	Redis    bool `yaml:"redis"` // 同时使用 redis_config 作为二级缓存，多个实例之间共享
+}
+
+// llmResilienceConfig 时间单位为毫秒，为 0 时使用默认值
+type llmResilienceConfig struct {
+	Disabled        bool `yaml:"disabled"`
+	CallTimeout     int  `yaml:"call_timeout"`     // 单次调用超时，不含限流排队的时间，默认只受服务商 timeout 限制
+	MaxRetries      int  `yaml:"max_retries"`      // 429、5xx 与网络错误的重试次数，默认 2，-1 表示不重试
+	BaseDelay       int  `yaml:"base_delay"`       // 指数退避初始间隔，默认 500
+	MaxDelay        int  `yaml:"max_delay"`        // 指数退避最大间隔，默认 10000
+	MaxRetryAfter   int  `yaml:"max_retry_after"`  // Retry-After 超过该值时不再重试而是切换服务商，默认 60000
+	BreakerFailures int  `yaml:"breaker_failures"` // 连续失败多少次后熔断，默认 5
+	BreakerCooldown int  `yaml:"breaker_cooldown"` // 熔断后多久放行一次探测请求，默认 30000
+}
+
+type llmFixtureConfig struct {
+	Mode string `yaml:"mode"` // record 或 replay
+	Dir  string `yaml:"dir"`  // 录制文件目录
+}
+
+type documentContextConfig struct {
+	MaxTokens int `yaml:"max_tokens"` // 示例原文与译文合计的 token 上限，超出时先舍弃最早的任务，默认 2000
+}
+
+type uploadConfig struct {
+	MaxSize int64 `yaml:"max_size"` // 文件大小上限，单位字节，默认 2MB
+}
+
+type taskWorkerConfig struct {
+	Workers   int `yaml:"workers"`    // 同时执行的任务数，默认 8
+	QueueSize int `yaml:"queue_size"` // 等待执行的任务数上限，队列满时拒绝执行，默认 1000
+}
+
 var c *Config
 
 func GetConfig() *Config {
 	return c
 }
 
+// LLMProviders 返回所有翻译服务商配置，未配置 llm_router 时退化为 llm_config 单个服务商，
+// 开发环境两者都未配置时使用不发出网络请求的 fake 服务商
+func (c *Config) LLMProviders() []*llmConfig {
+	if c.LLMRouter != nil && len(c.LLMRouter.Providers) > 0 {
+		return c.LLMRouter.Providers
+	}
+	if c.LLMConfig != nil {
+		return []*llmConfig{c.LLMConfig}
+	}
+	if c.RunMode == RunModeDev {
+		return []*llmConfig{{Name: ProviderTypeFake, Type: ProviderTypeFake}}
+	}
+	return nil
+}
+
 func ParseConfig(dist string) error {
 	data, err := ioutil.ReadFile(dist)
 	if err != nil {
@@ -0,0 +1,145 @@
+package dao
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/language"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/mysql_tool"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+type IGlossaryDao interface {
+	CreateGlossary(glossary *models.GlossaryModel) error
+	UpdateGlossary(username string, id int64, updates map[string]any) error
+	DeleteGlossary(username string, id int64) error
+	GetGlossaryByIdAndUsername(username string, id int64) (*models.GlossaryModel, error)
+	ListGlossary(username, sourceLang, targetLang string, page, size int) ([]*models.GlossaryModel, int64, error)
+	// FindGlossaryByLangPair 查询任务可用的术语，包括未限定源语言的术语
+	// 语言按主语言匹配，例如 zh-CN 的任务会查出 zh 与 zh-TW 的术语，调用方再按 language.Same 过滤
+	FindGlossaryByLangPair(username, sourceLang, targetLang string) ([]*models.GlossaryModel, error)
+}
+
+type glossaryDao struct {
+	dbClientName string
+	db           *mysql_tool.DB
+}
+
+func NewGlossaryDao(dbClientName string) IGlossaryDao {
+	return &glossaryDao{
+		dbClientName: dbClientName,
+	}
+}
+
+func (g *glossaryDao) CreateGlossary(glossary *models.GlossaryModel) error {
+	var count int64
+	err := g.getDBClient().
+		Model(&models.GlossaryModel{}).
+		Where("create_by = ?", glossary.CreateBy).
+		Where("term = ?", glossary.Term).
+		Where("source_lang = ?", glossary.SourceLang).
+		Where("target_lang = ?", glossary.TargetLang).
+		Count(&count).Error
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	if count > 0 {
+		return unify_response.ParameterError("术语已存在")
+	}
+	err = g.getDBClient().Create(glossary).Error
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+func (g *glossaryDao) UpdateGlossary(username string, id int64, updates map[string]any) error {
+	result := g.getDBClient().
+		Model(&models.GlossaryModel{}).
+		Where("id = ?", id).
+		Where("create_by = ?", username).
+		Updates(updates)
+	if result.Error != nil {
+		return unify_response.DBError(result.Error.Error())
+	}
+	return nil
+}
+
+func (g *glossaryDao) DeleteGlossary(username string, id int64) error {
+	result := g.getDBClient().
+		Where("id = ?", id).
+		Where("create_by = ?", username).
+		Delete(&models.GlossaryModel{})
+	if result.Error != nil {
+		return unify_response.DBError(result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return unify_response.NotFound()
+	}
+	return nil
+}
+
+func (g *glossaryDao) GetGlossaryByIdAndUsername(username string, id int64) (*models.GlossaryModel, error) {
+	var glossary models.GlossaryModel
+	err := g.getDBClient().
+		Where("id = ?", id).
+		Where("create_by = ?", username).
+		Find(&glossary).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	if glossary.ID == 0 {
+		return nil, unify_response.NotFound()
+	}
+	return &glossary, nil
+}
+
+func (g *glossaryDao) ListGlossary(
+	username, sourceLang, targetLang string, page, size int) ([]*models.GlossaryModel, int64, error) {
+	db := g.getDBClient().
+		Model(&models.GlossaryModel{}).
+		Where("create_by = ?", username)
+	if sourceLang != "" {
+		db = db.Where("source_lang = ?", sourceLang)
+	}
+	if targetLang != "" {
+		db = db.Where("target_lang = ?", targetLang)
+	}
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, unify_response.DBError(err.Error())
+	}
+	var list []*models.GlossaryModel
+	err := db.Order("id desc").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, unify_response.DBError(err.Error())
+	}
+	return list, count, nil
+}
+
+func (g *glossaryDao) FindGlossaryByLangPair(
+	username, sourceLang, targetLang string) ([]*models.GlossaryModel, error) {
+	targetBase := language.Base(targetLang)
+	db := g.getDBClient().
+		Where("create_by = ?", username).
+		Where("target_lang = ? or target_lang like ?", targetBase, targetBase+"-%")
+	// 自动检测源语言时无法按源语言过滤
+	if sourceLang != "" && sourceLang != models.AutoDetect {
+		sourceBase := language.Base(sourceLang)
+		db = db.Where("source_lang = ? or source_lang like ? or source_lang = ''", sourceBase, sourceBase+"-%")
+	}
+	var list []*models.GlossaryModel
+	if err := db.Find(&list).Error; err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	return list, nil
+}
+
+func (g *glossaryDao) getDBClient() *mysql_tool.DB {
+	if g.db != nil {
+		return g.db
+	}
+	g.db = mysql_tool.GetMysqlClient(g.dbClientName)
+	return g.db
+}
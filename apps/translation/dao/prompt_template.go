@@ -0,0 +1,109 @@
+package dao
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/mysql_tool"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"gorm.io/gorm"
+)
+
+type IPromptTemplateDao interface {
+	// CreatePromptTemplate 以同名模板当前最大版本号加一保存新版本
+	CreatePromptTemplate(template *models.PromptTemplateModel) error
+	// GetLatestPromptTemplate 返回最新版本，不存在时返回 nil
+	GetLatestPromptTemplate(name string) (*models.PromptTemplateModel, error)
+	// GetPromptTemplate 返回指定版本，不存在时返回 nil
+	GetPromptTemplate(name string, version int) (*models.PromptTemplateModel, error)
+	ListPromptTemplates(name string, page, size int) ([]*models.PromptTemplateModel, int64, error)
+}
+
+type promptTemplateDao struct {
+	dbClientName string
+	db           *mysql_tool.DB
+}
+
+func NewPromptTemplateDao(dbClientName string) IPromptTemplateDao {
+	return &promptTemplateDao{
+		dbClientName: dbClientName,
+	}
+}
+
+func (p *promptTemplateDao) CreatePromptTemplate(template *models.PromptTemplateModel) error {
+	err := p.getDBClient().Transaction(func(tx *gorm.DB) error {
+		var maxVersion int
+		err := tx.Model(&models.PromptTemplateModel{}).
+			Where("name = ?", template.Name).
+			Select("coalesce(max(version), 0)").
+			Scan(&maxVersion).Error
+		if err != nil {
+			return err
+		}
+		template.Version = maxVersion + 1
+		return tx.Create(template).Error
+	})
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+func (p *promptTemplateDao) GetLatestPromptTemplate(name string) (*models.PromptTemplateModel, error) {
+	var template models.PromptTemplateModel
+	err := p.getDBClient().
+		Where("name = ?", name).
+		Order("version desc").
+		Limit(1).
+		Find(&template).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	if template.ID == 0 {
+		return nil, nil
+	}
+	return &template, nil
+}
+
+func (p *promptTemplateDao) GetPromptTemplate(name string, version int) (*models.PromptTemplateModel, error) {
+	var template models.PromptTemplateModel
+	err := p.getDBClient().
+		Where("name = ?", name).
+		Where("version = ?", version).
+		Limit(1).
+		Find(&template).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	if template.ID == 0 {
+		return nil, nil
+	}
+	return &template, nil
+}
+
+func (p *promptTemplateDao) ListPromptTemplates(
+	name string, page, size int) ([]*models.PromptTemplateModel, int64, error) {
+	db := p.getDBClient().Model(&models.PromptTemplateModel{})
+	if name != "" {
+		db = db.Where("name = ?", name)
+	}
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, unify_response.DBError(err.Error())
+	}
+	var list []*models.PromptTemplateModel
+	err := db.Order("name asc, version desc").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, unify_response.DBError(err.Error())
+	}
+	return list, count, nil
+}
+
+func (p *promptTemplateDao) getDBClient() *mysql_tool.DB {
+	if p.db != nil {
+		return p.db
+	}
+	p.db = mysql_tool.GetMysqlClient(p.dbClientName)
+	return p.db
+}
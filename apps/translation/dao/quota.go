@@ -0,0 +1,212 @@
+package dao
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/mysql_tool"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type IQuotaDao interface {
+	CreatePlan(plan *models.QuotaPlanModel) error
+	UpdatePlan(id uint, updates map[string]any) error
+	// GetPlan、GetPlanByName 套餐不存在时返回 nil
+	GetPlan(id uint) (*models.QuotaPlanModel, error)
+	GetPlanByName(name string) (*models.QuotaPlanModel, error)
+	ListPlans() ([]*models.QuotaPlanModel, error)
+	// AssignPlan 为用户分配套餐，planId 为 0 表示恢复使用默认套餐，day、month 用于首次创建记录
+	AssignPlan(username string, planId uint, day, month time.Time) error
+	// GetUserQuota day、month 为当前日、月周期的起始时间，已过期周期的已用量按 0 返回，从未使用过时返回 nil
+	GetUserQuota(username string, day, month time.Time) (*models.UserQuotaModel, error)
+	// ConsumeQuota 在事务中锁定用户配额后累加用量，用量为负数时为退还，
+	// check 不为 nil 时先检查，检查失败则原样返回其错误且不累加
+	ConsumeQuota(username string, chars, tokens int64, day, month time.Time,
+		check func(quota *models.UserQuotaModel) error) error
+	// ResetExpiredQuotas 清零周期已过期的已用量
+	ResetExpiredQuotas(day, month time.Time) error
+}
+
+type quotaDao struct {
+	dbClientName string
+	db           *mysql_tool.DB
+}
+
+func NewQuotaDao(dbClientName string) IQuotaDao {
+	return &quotaDao{
+		dbClientName: dbClientName,
+	}
+}
+
+func (q *quotaDao) CreatePlan(plan *models.QuotaPlanModel) error {
+	err := q.getDBClient().Create(plan).Error
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+func (q *quotaDao) UpdatePlan(id uint, updates map[string]any) error {
+	err := q.getDBClient().
+		Model(&models.QuotaPlanModel{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+func (q *quotaDao) GetPlan(id uint) (*models.QuotaPlanModel, error) {
+	var plan models.QuotaPlanModel
+	err := q.getDBClient().
+		Where("id = ?", id).
+		Limit(1).
+		Find(&plan).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	if plan.ID == 0 {
+		return nil, nil
+	}
+	return &plan, nil
+}
+
+func (q *quotaDao) GetPlanByName(name string) (*models.QuotaPlanModel, error) {
+	var plan models.QuotaPlanModel
+	err := q.getDBClient().
+		Where("name = ?", name).
+		Limit(1).
+		Find(&plan).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	if plan.ID == 0 {
+		return nil, nil
+	}
+	return &plan, nil
+}
+
+func (q *quotaDao) ListPlans() ([]*models.QuotaPlanModel, error) {
+	var list []*models.QuotaPlanModel
+	err := q.getDBClient().
+		Order("id asc").
+		Find(&list).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	return list, nil
+}
+
+func (q *quotaDao) AssignPlan(username string, planId uint, day, month time.Time) error {
+	err := q.getDBClient().
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "username"}},
+			DoUpdates: clause.AssignmentColumns([]string{"plan_id", "updated_at"}),
+		}).
+		Create(&models.UserQuotaModel{
+			Username:      username,
+			PlanId:        planId,
+			DailyPeriod:   day,
+			MonthlyPeriod: month,
+		}).Error
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+func (q *quotaDao) GetUserQuota(username string, day, month time.Time) (*models.UserQuotaModel, error) {
+	var quota models.UserQuotaModel
+	err := q.getDBClient().
+		Where("username = ?", username).
+		Limit(1).
+		Find(&quota).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	if quota.ID == 0 {
+		return nil, nil
+	}
+	rollQuotaPeriods(&quota, day, month)
+	return &quota, nil
+}
+
+func (q *quotaDao) ConsumeQuota(username string, chars, tokens int64, day, month time.Time,
+	check func(quota *models.UserQuotaModel) error) error {
+	var checkErr error
+	err := q.getDBClient().Transaction(func(tx *gorm.DB) error {
+		// 首次使用时创建记录，并发创建时忽略唯一索引冲突
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserQuotaModel{Username: username, DailyPeriod: day, MonthlyPeriod: month}).Error
+		if err != nil {
+			return err
+		}
+		var quota models.UserQuotaModel
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("username = ?", username).
+			Limit(1).
+			Find(&quota).Error
+		if err != nil {
+			return err
+		}
+		rollQuotaPeriods(&quota, day, month)
+		if check != nil {
+			if checkErr = check(&quota); checkErr != nil {
+				return checkErr
+			}
+		}
+		return tx.Model(&quota).Updates(map[string]any{
+			// 退还时用量可能已跨周期清零，不减到负数
+			"daily_chars":    max(quota.DailyChars+chars, 0),
+			"daily_tokens":   max(quota.DailyTokens+tokens, 0),
+			"daily_period":   quota.DailyPeriod,
+			"monthly_chars":  max(quota.MonthlyChars+chars, 0),
+			"monthly_tokens": max(quota.MonthlyTokens+tokens, 0),
+			"monthly_period": quota.MonthlyPeriod,
+		}).Error
+	})
+	if checkErr != nil {
+		return checkErr
+	}
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+func (q *quotaDao) ResetExpiredQuotas(day, month time.Time) error {
+	db := q.getDBClient()
+	err := db.Model(&models.UserQuotaModel{}).
+		Where("daily_period < ?", day).
+		Updates(map[string]any{"daily_chars": 0, "daily_tokens": 0, "daily_period": day}).Error
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	err = db.Model(&models.UserQuotaModel{}).
+		Where("monthly_period < ?", month).
+		Updates(map[string]any{"monthly_chars": 0, "monthly_tokens": 0, "monthly_period": month}).Error
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+// rollQuotaPeriods 已用量所属周期早于当前周期时清零，定时清零之前的请求也能得到正确的余量
+func rollQuotaPeriods(quota *models.UserQuotaModel, day, month time.Time) {
+	if quota.DailyPeriod.Before(day) {
+		quota.DailyChars, quota.DailyTokens, quota.DailyPeriod = 0, 0, day
+	}
+	if quota.MonthlyPeriod.Before(month) {
+		quota.MonthlyChars, quota.MonthlyTokens, quota.MonthlyPeriod = 0, 0, month
+	}
+}
+
+func (q *quotaDao) getDBClient() *mysql_tool.DB {
+	if q.db != nil {
+		return q.db
+	}
+	q.db = mysql_tool.GetMysqlClient(q.dbClientName)
+	return q.db
+}
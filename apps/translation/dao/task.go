@@ -4,15 +4,24 @@ import (
 	"github.com/jovian1994/cxh-1207-be-interview/models"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/mysql_tool"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"gorm.io/gorm"
 )
 
 type ITaskDao interface {
-	CreateTask(
-		createBy string,
-		lang string,
-		content string, targetLang string) error
+	CreateTask(task *models.TaskModel) error
+	// CreateTaskWithChildren 在同一事务中创建父任务及各目标语言的子任务
+	CreateTaskWithChildren(parent *models.TaskModel, children []*models.TaskModel) error
+	ListChildTasks(parentId int64) ([]*models.TaskModel, error)
+	// ListTasksByTemplate 查询使用指定提示词模板版本的任务
+	ListTasksByTemplate(name string, version int, page, size int) ([]*models.TaskModel, int64, error)
+	// ListTasks 分页查询用户的任务，status 为 nil 时不限状态
+	ListTasks(username string, status *int, sort string, page, size int) ([]*models.TaskModel, int64, error)
 	GetTaskByIdAndUsername(username string, taskId int64) (*models.TaskModel, error)
+	// ListTasksByIds 查询用户的多个任务及其子任务，按 id 升序
+	ListTasksByIds(username string, ids []int64) ([]*models.TaskModel, error)
 	UpdateTaskStatus(taskId int64, updates map[string]any) error
+	// StartTask 任务不在执行中时更新为执行中，started 为 false 表示任务已经在执行
+	StartTask(taskId int64, updates map[string]any) (started bool, err error)
 }
 
 type taskDao struct {
@@ -21,28 +30,101 @@ type taskDao struct {
 }
 
 func NewTaskDao(dbClientName string) ITaskDao {
-	return &taskDao{}
+	return &taskDao{
+		dbClientName: dbClientName,
+	}
 }
 
-func (t *taskDao) CreateTask(createBy string, lang string,
-	content string, targetLang string) error {
-
-	task := models.TaskModel{}
-	task.CreateBy = createBy
-	task.Lang = lang
-	task.Content = content
-	task.TargetLang = targetLang
-	task.Status = 0
-	if lang == "" {
-		lang = models.AutoDetect
+func (t *taskDao) CreateTask(task *models.TaskModel) error {
+	task.Status = models.TaskStatusCreated
+	if task.Lang == "" {
+		task.Lang = models.AutoDetect
 	}
-	err := t.getDBClient().Create(&task).Error
+	err := t.getDBClient().Create(task).Error
 	if err != nil {
 		return unify_response.DBError(err.Error())
 	}
 	return nil
 }
 
+func (t *taskDao) CreateTaskWithChildren(parent *models.TaskModel, children []*models.TaskModel) error {
+	err := t.getDBClient().Transaction(func(tx *gorm.DB) error {
+		parent.Status = models.TaskStatusCreated
+		if err := tx.Create(parent).Error; err != nil {
+			return err
+		}
+		for _, child := range children {
+			child.ParentId = parent.ID
+			child.Status = models.TaskStatusCreated
+		}
+		return tx.Create(children).Error
+	})
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+func (t *taskDao) ListChildTasks(parentId int64) ([]*models.TaskModel, error) {
+	var list []*models.TaskModel
+	err := t.getDBClient().
+		Where("parent_id = ?", parentId).
+		Order("id asc").
+		Find(&list).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	return list, nil
+}
+
+func (t *taskDao) ListTasksByTemplate(
+	name string, version int, page, size int) ([]*models.TaskModel, int64, error) {
+	db := t.getDBClient().
+		Model(&models.TaskModel{}).
+		Where("template_name = ?", name).
+		Where("template_version = ?", version)
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, unify_response.DBError(err.Error())
+	}
+	var list []*models.TaskModel
+	err := db.Order("id desc").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, unify_response.DBError(err.Error())
+	}
+	return list, count, nil
+}
+
+func (t *taskDao) ListTasks(
+	username string, status *int, sort string, page, size int) ([]*models.TaskModel, int64, error) {
+	db := t.getDBClient().
+		Model(&models.TaskModel{}).
+		Where("create_by = ?", username)
+	if status != nil {
+		db = db.Where("status = ?", *status)
+	}
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, unify_response.DBError(err.Error())
+	}
+	if sort == models.TaskSortQuality {
+		db = db.Order("quality_score is null").Order("quality_score asc")
+	}
+	var list []*models.TaskModel
+	err := db.Order("id desc").
+		Omit("content").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&list).Error
+	if err != nil {
+		return nil, 0, unify_response.DBError(err.Error())
+	}
+	return list, count, nil
+}
+
 func (t *taskDao) GetTaskByIdAndUsername(username string, taskId int64) (*models.TaskModel, error) {
 
 	var task models.TaskModel
@@ -60,6 +142,22 @@ func (t *taskDao) GetTaskByIdAndUsername(username string, taskId int64) (*models
 
 }
 
+func (t *taskDao) ListTasksByIds(username string, ids []int64) ([]*models.TaskModel, error) {
+	var list []*models.TaskModel
+	if len(ids) == 0 {
+		return list, nil
+	}
+	err := t.getDBClient().
+		Where("create_by = ?", username).
+		Where("id in ? or parent_id in ?", ids, ids).
+		Order("id asc").
+		Find(&list).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	return list, nil
+}
+
 func (t *taskDao) GetTaskDetail(taskId int64) (*models.TaskModel, error) {
 
 	var task models.TaskModel
@@ -85,7 +183,7 @@ func (t *taskDao) getDBClient() *mysql_tool.DB {
 
 func (t *taskDao) UpdateTaskStatus(taskId int64, updates map[string]any) error {
 	err := t.getDBClient().
-		Model(updates).
+		Model(&models.TaskModel{}).
 		Where("id = ?", taskId).
 		Updates(updates).Error
 	if err != nil {
@@ -93,3 +191,15 @@ func (t *taskDao) UpdateTaskStatus(taskId int64, updates map[string]any) error {
 	}
 	return nil
 }
+
+func (t *taskDao) StartTask(taskId int64, updates map[string]any) (bool, error) {
+	updates["status"] = models.TaskStatusRunning
+	result := t.getDBClient().
+		Model(&models.TaskModel{}).
+		Where("id = ? AND status <> ?", taskId, models.TaskStatusRunning).
+		Updates(updates)
+	if result.Error != nil {
+		return false, unify_response.DBError(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
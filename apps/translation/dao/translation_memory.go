@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/mysql_tool"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITranslationMemoryDao interface {
+	// SaveTranslationMemory 保存记忆，同一用户、语言对下原文相同的记录会被新译文覆盖
+	SaveTranslationMemory(memories []*models.TranslationMemoryModel) error
+	// FindExactMemory 按原文哈希精确查找，不存在时返回 nil
+	FindExactMemory(username, sourceHash, lang, targetLang string) (*models.TranslationMemoryModel, error)
+	// FindMemoryCandidates 查找原文长度在 [minLength, maxLength] 之间的记忆，用于模糊匹配
+	FindMemoryCandidates(username, lang, targetLang string,
+		minLength, maxLength, limit int) ([]*models.TranslationMemoryModel, error)
+}
+
+type translationMemoryDao struct {
+	dbClientName string
+	db           *mysql_tool.DB
+}
+
+func NewTranslationMemoryDao(dbClientName string) ITranslationMemoryDao {
+	return &translationMemoryDao{
+		dbClientName: dbClientName,
+	}
+}
+
+func (m *translationMemoryDao) SaveTranslationMemory(memories []*models.TranslationMemoryModel) error {
+	err := m.getDBClient().Transaction(func(tx *gorm.DB) error {
+		// 依赖 (create_by, source_hash, lang, target_lang) 唯一索引，并发保存相同原文时不会产生重复记录
+		for _, memory := range memories {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{
+					{Name: "create_by"}, {Name: "source_hash"}, {Name: "lang"}, {Name: "target_lang"},
+				},
+				DoUpdates: clause.AssignmentColumns([]string{"target", "task_id", "updated_at"}),
+			}).Create(memory).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+func (m *translationMemoryDao) FindExactMemory(
+	username, sourceHash, lang, targetLang string) (*models.TranslationMemoryModel, error) {
+	var memory models.TranslationMemoryModel
+	err := m.getDBClient().
+		Where("create_by = ?", username).
+		Where("source_hash = ?", sourceHash).
+		Where("lang = ?", lang).
+		Where("target_lang = ?", targetLang).
+		Limit(1).
+		Find(&memory).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	if memory.ID == 0 {
+		return nil, nil
+	}
+	return &memory, nil
+}
+
+func (m *translationMemoryDao) FindMemoryCandidates(username, lang, targetLang string,
+	minLength, maxLength, limit int) ([]*models.TranslationMemoryModel, error) {
+	var list []*models.TranslationMemoryModel
+	err := m.getDBClient().
+		Where("create_by = ?", username).
+		Where("lang = ?", lang).
+		Where("target_lang = ?", targetLang).
+		Where("source_length between ? and ?", minLength, maxLength).
+		Order("id desc").
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	return list, nil
+}
+
+func (m *translationMemoryDao) getDBClient() *mysql_tool.DB {
+	if m.db != nil {
+		return m.db
+	}
+	m.db = mysql_tool.GetMysqlClient(m.dbClientName)
+	return m.db
+}
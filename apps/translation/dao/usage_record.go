@@ -0,0 +1,91 @@
+package dao
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/mysql_tool"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"time"
+)
+
+type IUsageRecordDao interface {
+	CreateUsageRecord(record *models.UsageRecordModel) error
+	// AggregateUsage 统计 [start, end) 内的用量，username 为空时统计全部用户
+	AggregateUsage(username string, start, end time.Time, groupBy string) ([]*UsageAggregate, error)
+}
+
+// UsageAggregate 按分组汇总的用量，未参与分组的列为空
+type UsageAggregate struct {
+	Lang             string  `gorm:"column:lang"`
+	TargetLang       string  `gorm:"column:target_lang"`
+	Provider         string  `gorm:"column:provider"`
+	Model            string  `gorm:"column:model"`
+	CreateBy         string  `gorm:"column:create_by"`
+	Day              string  `gorm:"column:day"`
+	Currency         string  `gorm:"column:currency"`
+	Calls            int64   `gorm:"column:calls"`
+	PromptTokens     int64   `gorm:"column:prompt_tokens"`
+	CompletionTokens int64   `gorm:"column:completion_tokens"`
+	TotalTokens      int64   `gorm:"column:total_tokens"`
+	Cost             float64 `gorm:"column:cost"`
+}
+
+// usageGroupColumns 各分组方式对应的查询列与分组列，不同币种的费用不能相加，始终按币种分组
+var usageGroupColumns = map[string][2]string{
+	models.UsageGroupLangPair: {"lang, target_lang", "lang, target_lang"},
+	models.UsageGroupProvider: {"provider, model", "provider, model"},
+	models.UsageGroupUser:     {"create_by", "create_by"},
+	models.UsageGroupDay:      {"date_format(created_at, '%Y-%m-%d') as day", "day"},
+}
+
+type usageRecordDao struct {
+	dbClientName string
+	db           *mysql_tool.DB
+}
+
+func NewUsageRecordDao(dbClientName string) IUsageRecordDao {
+	return &usageRecordDao{
+		dbClientName: dbClientName,
+	}
+}
+
+func (u *usageRecordDao) CreateUsageRecord(record *models.UsageRecordModel) error {
+	err := u.getDBClient().Create(record).Error
+	if err != nil {
+		return unify_response.DBError(err.Error())
+	}
+	return nil
+}
+
+func (u *usageRecordDao) AggregateUsage(
+	username string, start, end time.Time, groupBy string) ([]*UsageAggregate, error) {
+	columns, ok := usageGroupColumns[groupBy]
+	if !ok {
+		columns = usageGroupColumns[models.UsageGroupLangPair]
+	}
+	db := u.getDBClient().
+		Model(&models.UsageRecordModel{}).
+		Where("created_at >= ?", start).
+		Where("created_at < ?", end)
+	if username != "" {
+		db = db.Where("create_by = ?", username)
+	}
+	var list []*UsageAggregate
+	err := db.Select(columns[0] + ", currency, count(*) as calls" +
+		", sum(prompt_tokens) as prompt_tokens, sum(completion_tokens) as completion_tokens" +
+		", sum(total_tokens) as total_tokens, sum(cost) as cost").
+		Group(columns[1] + ", currency").
+		Order("cost desc").
+		Scan(&list).Error
+	if err != nil {
+		return nil, unify_response.DBError(err.Error())
+	}
+	return list, nil
+}
+
+func (u *usageRecordDao) getDBClient() *mysql_tool.DB {
+	if u.db != nil {
+		return u.db
+	}
+	u.db = mysql_tool.GetMysqlClient(u.dbClientName)
+	return u.db
+}
@@ -13,6 +13,7 @@ import (
 type IUserDao interface {
 	CreateUser(username, password string, role int) (id int64, err error)
 	AuthUser(username, password string) (jwtString string, err error)
+	GetUserRole(username string) (int, error)
 }
 
 func NewUserDao(dbClientName string, jwtVerify jwt.ITokenVerify) IUserDao {
@@ -64,6 +65,17 @@ func (u *userDao) userExisted(username string) (bool, *models.UserModel, error)
 	return count > 0, userModel, nil
 }
 
+func (u *userDao) GetUserRole(username string) (int, error) {
+	existed, userModel, err := u.userExisted(username)
+	if err != nil {
+		return 0, err
+	}
+	if !existed || userModel == nil {
+		return 0, unify_response.UseNotExist("用户不存在")
+	}
+	return userModel.Role, nil
+}
+
 func (u *userDao) CreateUser(username, password string, role int) (int64, error) {
 	existed, _, err := u.userExisted(username)
 	if err != nil {
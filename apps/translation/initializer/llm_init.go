@@ -0,0 +1,166 @@
+package initializer
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/cache"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultProviderName = "default"
+	defaultLLMCacheTTL  = 7 * 24 * time.Hour
+	llmCacheRedisPrefix = "translation:"
+)
+
+func initLLMClient() llm.ILLMClient {
+	conf := config.GetConfig()
+	providers := conf.LLMProviders()
+	if len(providers) == 0 {
+		panic("llm配置为空")
+	}
+	fixtureTransport := initLLMFixture()
+
+	translationCache, cacheTTL := initLLMCache()
+	registry := llm.NewRegistry()
+	for _, p := range providers {
+		name := p.Name
+		if name == "" {
+			name = defaultProviderName
+		}
+		client := newProviderClient(p.Type, llm.ClientConfig{
+			Name:    name,
+			BaseURL: p.BaseURL,
+			Model:   p.Model,
+			ApiKey:  p.ApiKey,
+			Timeout: time.Duration(p.Timeout) * time.Second,
+		}, fixtureTransport)
+		if p.RPM > 0 || p.TPM > 0 || p.MaxInFlight > 0 {
+			client = llm.NewLimitedClient(client, llm.LimitConfig{
+				Provider:    name,
+				RPM:         p.RPM,
+				TPM:         p.TPM,
+				MaxInFlight: p.MaxInFlight,
+			})
+		}
+		if resilience := getResilienceConfig(name); resilience != nil {
+			client = llm.NewResilientClient(client, *resilience)
+		}
+		if translationCache != nil {
+			client = llm.NewCachedClient(client, translationCache, llm.CacheConfig{
+				Provider: name,
+				Model:    p.Model,
+				TTL:      cacheTTL,
+			})
+		}
+		err := registry.Register(name, client)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if conf.LLMRouter == nil {
+		return llm.NewRouter(registry, nil, nil)
+	}
+	routes := make([]llm.Route, 0, len(conf.LLMRouter.Routes))
+	for _, r := range conf.LLMRouter.Routes {
+		for _, name := range r.Providers {
+			if _, ok := registry.Get(name); !ok {
+				panic("路由 " + r.Name + " 引用了未配置的服务商: " + name)
+			}
+		}
+		routes = append(routes, llm.Route{
+			Name:        r.Name,
+			SourceLangs: r.SourceLangs,
+			TargetLangs: r.TargetLangs,
+			Users:       r.Users,
+			MinLength:   r.MinLength,
+			MaxLength:   r.MaxLength,
+			Providers:   r.Providers,
+		})
+	}
+	return llm.NewRouter(registry, routes, conf.LLMRouter.DefaultProviders)
+}
+
+// newProviderClient 按服务商类型创建客户端，transport 不为空时用于录制或回放
+func newProviderClient(providerType string, conf llm.ClientConfig, transport http.RoundTripper) llm.ILLMClient {
+	switch providerType {
+	case "":
+	case config.ProviderTypeFake:
+		return llm.NewFakeClient(conf.Name)
+	default:
+		panic("服务商 " + conf.Name + " 的类型不支持: " + providerType)
+	}
+	var opts []llm.Option
+	if transport != nil {
+		opts = append(opts, llm.WithTransport(transport))
+	}
+	return llm.NewLLMClient(conf, opts...)
+}
+
+// initLLMFixture 配置 llm_fixture 后，所有 OpenAI 兼容服务商的请求都经过录制或回放
+func initLLMFixture() http.RoundTripper {
+	fixtureConfig := config.GetConfig().LLMFixture
+	if fixtureConfig == nil || fixtureConfig.Mode == "" {
+		return nil
+	}
+	if fixtureConfig.Dir == "" {
+		panic("llm_fixture 未配置 dir")
+	}
+	transport, err := llm.NewFixtureTransport(fixtureConfig.Dir, fixtureConfig.Mode, nil)
+	if err != nil {
+		panic(err)
+	}
+	return transport
+}
+
+// initLLMCache 未禁用时使用进程内 LRU，配置 redis 后以 redis 作为二级缓存
+func initLLMCache() (cache.ICache, time.Duration) {
+	cacheConfig := config.GetConfig().LLMCache
+	if cacheConfig == nil {
+		return cache.NewLRU(0), defaultLLMCacheTTL
+	}
+	if cacheConfig.Disabled {
+		return nil, 0
+	}
+	ttl := defaultLLMCacheTTL
+	if cacheConfig.TTL > 0 {
+		ttl = time.Duration(cacheConfig.TTL) * time.Second
+	}
+	lru := cache.NewLRU(cacheConfig.Size)
+	if !cacheConfig.Redis {
+		return lru, ttl
+	}
+	redisClient := initRedis()
+	if redisClient == nil {
+		panic("llm_cache 启用了 redis，但未配置 redis_config")
+	}
+	return cache.NewTiered(ttl, lru, cache.NewRedis(redisClient, llmCacheRedisPrefix)), ttl
+}
+
+// getResilienceConfig 返回服务商的超时、重试与熔断策略，禁用时返回 nil
+func getResilienceConfig(provider string) *llm.ResilienceConfig {
+	resilienceConfig := config.GetConfig().LLMResilience
+	if resilienceConfig == nil {
+		return &llm.ResilienceConfig{
+			Provider: provider,
+			Breaker:  llm.NewCircuitBreaker(provider, llm.BreakerConfig{}),
+		}
+	}
+	if resilienceConfig.Disabled {
+		return nil
+	}
+	return &llm.ResilienceConfig{
+		Provider:      provider,
+		CallTimeout:   time.Duration(resilienceConfig.CallTimeout) * time.Millisecond,
+		MaxRetries:    resilienceConfig.MaxRetries,
+		BaseDelay:     time.Duration(resilienceConfig.BaseDelay) * time.Millisecond,
+		MaxDelay:      time.Duration(resilienceConfig.MaxDelay) * time.Millisecond,
+		MaxRetryAfter: time.Duration(resilienceConfig.MaxRetryAfter) * time.Millisecond,
+		Breaker: llm.NewCircuitBreaker(provider, llm.BreakerConfig{
+			FailureThreshold: resilienceConfig.BreakerFailures,
+			Cooldown:         time.Duration(resilienceConfig.BreakerCooldown) * time.Millisecond,
+		}),
+	}
+}
@@ -0,0 +1,30 @@
+package initializer
+
+import (
+	"context"
+	"fmt"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+const redisPingTimeout = 3 * time.Second
+
+// initRedis 未配置 redis_config 时返回 nil
+func initRedis() *redis.Client {
+	redisConfig := config.GetConfig().RedisConfig
+	if redisConfig == nil {
+		return nil
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", redisConfig.Host, redisConfig.Port),
+		Password: redisConfig.Pass,
+		DB:       redisConfig.Db,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), redisPingTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		panic(fmt.Sprintf("连接 redis 失败: %s", err.Error()))
+	}
+	return client
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/service"
 	"github.com/jovian1994/cxh-1207-be-interview/middlewares"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/jwt"
-	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/mysql_tool"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
 	"strconv"
@@ -49,15 +48,33 @@ func ServerInit(e *gin.Engine) {
 	notifyChannel := make(chan map[string]any, 10000)
 
 	tokenVerify := jwt.NewTokenVerify()
-	llmClient := llm.NewLLMClient()
+	llmClient := initLLMClient()
 	userDao := dao.NewUserDao(dbClientName, tokenVerify)
 	taskDao := dao.NewTaskDao(dbClientName)
+	glossaryDao := dao.NewGlossaryDao(dbClientName)
+	memoryDao := dao.NewTranslationMemoryDao(dbClientName)
+	templateDao := dao.NewPromptTemplateDao(dbClientName)
+	usageDao := dao.NewUsageRecordDao(dbClientName)
+	quotaDao := dao.NewQuotaDao(dbClientName)
 
 	userService := service.NewUserService(userDao)
-	taskService := service.NewTaskService(taskDao, llmClient)
+	taskService := service.NewTaskService(taskDao, glossaryDao, memoryDao, templateDao, usageDao, quotaDao, llmClient, notifyChannel)
+	taskService.StartWorkers()
+	glossaryService := service.NewGlossaryService(glossaryDao)
+	templateService := service.NewPromptTemplateService(templateDao, taskDao)
+	usageService := service.NewUsageService(usageDao, userDao)
+	quotaService := service.NewQuotaService(quotaDao, userDao)
+	quotaService.StartResetScheduler()
+	healthService := service.NewHealthService(llmClient)
 
 	userApi := api.NewUserApi(userService)
 	taskApi := api.NewTaskApi(taskService, notifyChannel)
+	glossaryApi := api.NewGlossaryApi(glossaryService)
+	languageApi := api.NewLanguageApi()
+	templateApi := api.NewPromptTemplateApi(templateService)
+	usageApi := api.NewUsageApi(usageService)
+	quotaApi := api.NewQuotaApi(quotaService)
+	healthApi := api.NewHealthApi(healthService)
 
 	rateLimit := getRateLimit()
 	r := e.Group("/v1")
@@ -65,9 +82,30 @@ func ServerInit(e *gin.Engine) {
 		r.POST("/user/login", unify_response.UnifyResponseWrapper(userApi.Login))
 		r.POST("/user/register", unify_response.UnifyResponseWrapper(userApi.Register))
 		r.POST("/task/create", middlewares.RateLimitMiddleware(rateLimit), middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(taskApi.CreateTask))
+		r.POST("/task/upload", middlewares.RateLimitMiddleware(rateLimit), middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(taskApi.UploadTask))
 		r.POST("/task/execute", middlewares.RateLimitMiddleware(rateLimit), middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(taskApi.ExecTask))
 		r.GET("/task/detail", middlewares.RateLimitMiddleware(rateLimit), middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(taskApi.GetTaskDetail))
+		r.GET("/task/list", middlewares.RateLimitMiddleware(rateLimit), middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(taskApi.ListTasks))
 		r.GET("/task/download", middlewares.RateLimitMiddleware(rateLimit), middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(taskApi.DownloadTask))
+		r.GET("/task/watch", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(taskApi.WatchTaskStatus))
+		r.GET("/languages", middlewares.RateLimitMiddleware(rateLimit), unify_response.UnifyResponseWrapper(languageApi.ListLanguages))
+		r.POST("/glossary/create", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(glossaryApi.CreateGlossary))
+		r.POST("/glossary/update", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(glossaryApi.UpdateGlossary))
+		r.POST("/glossary/delete", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(glossaryApi.DeleteGlossary))
+		r.GET("/glossary/detail", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(glossaryApi.GetGlossaryDetail))
+		r.GET("/glossary/list", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(glossaryApi.ListGlossary))
+		r.POST("/template/create", middlewares.LoginRequired(tokenVerify), middlewares.AdminRequired(userService), unify_response.UnifyResponseWrapper(templateApi.CreatePromptTemplate))
+		r.GET("/template/detail", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(templateApi.GetPromptTemplateDetail))
+		r.GET("/template/list", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(templateApi.ListPromptTemplate))
+		r.GET("/template/tasks", middlewares.LoginRequired(tokenVerify), middlewares.AdminRequired(userService), unify_response.UnifyResponseWrapper(templateApi.ListTemplateTasks))
+		r.GET("/template/styles", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(templateApi.GetStyleOptions))
+		r.GET("/usage", middlewares.RateLimitMiddleware(rateLimit), middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(usageApi.GetUsage))
+		r.POST("/quota/plan/create", middlewares.LoginRequired(tokenVerify), middlewares.AdminRequired(userService), unify_response.UnifyResponseWrapper(quotaApi.CreatePlan))
+		r.POST("/quota/plan/update", middlewares.LoginRequired(tokenVerify), middlewares.AdminRequired(userService), unify_response.UnifyResponseWrapper(quotaApi.UpdatePlan))
+		r.GET("/quota/plan/list", middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(quotaApi.ListPlans))
+		r.POST("/quota/assign", middlewares.LoginRequired(tokenVerify), middlewares.AdminRequired(userService), unify_response.UnifyResponseWrapper(quotaApi.AssignPlan))
+		r.GET("/quota/balance", middlewares.RateLimitMiddleware(rateLimit), middlewares.LoginRequired(tokenVerify), unify_response.UnifyResponseWrapper(quotaApi.GetBalance))
+		r.GET("/health/llm", middlewares.RateLimitMiddleware(rateLimit), unify_response.UnifyResponseWrapper(healthApi.GetLLMHealth))
 	}
 }
 
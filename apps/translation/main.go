@@ -25,7 +25,7 @@ import (
 func main() {
 
 	currentAbPath := getCurrentAbPath()
-	runConfigPath := path.Join(currentAbPath, "etc", "run.yml")
+	runConfigPath := path.Join(currentAbPath, "etc", "run.yaml")
 	runConfigItem, err := parseRunConfig(runConfigPath)
 	if err != nil {
 		fmt.Println(fmt.Sprintf("parse run config failed, err:%s", err.Error()))
@@ -33,7 +33,7 @@ func main() {
 	}
 	var ginRunMode string
 	var configPath string
-	if runConfigItem.RunMode == "dev" {
+	if runConfigItem.RunMode == config.RunModeDev {
 		ginRunMode = "debug"
 		logger.InitLogger(logger.WithDebugLevel())
 		configPath = path.Join(currentAbPath, "etc", "app-dev.yaml")
@@ -50,6 +50,9 @@ func main() {
 		fmt.Println(fmt.Sprintf("parse config failed, err:%s", err.Error()))
 		panic(err)
 	}
+	if config.GetConfig().RunMode == "" {
+		config.GetConfig().RunMode = runConfigItem.RunMode
+	}
 	gin.SetMode(ginRunMode)
 	engine := gin.Default()
 	initializer.ServerInit(engine)
@@ -65,7 +68,7 @@ func main() {
 		}
 	}()
 	//等待一个INT或TERM信号
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("收到退出信号 ...")
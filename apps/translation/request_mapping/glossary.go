@@ -0,0 +1,140 @@
+package request_mapping
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type CreateGlossaryReq struct {
+	Term           string `json:"term"`
+	SourceLang     string `json:"source_lang"`
+	TargetLang     string `json:"target_lang"`
+	Translation    string `json:"translation"`
+	CaseSensitive  bool   `json:"case_sensitive"`
+	DoNotTranslate bool   `json:"do_not_translate"`
+}
+
+func (req *CreateGlossaryReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	return req.check()
+}
+
+func (req *CreateGlossaryReq) check() error {
+	req.Term = strings.TrimSpace(req.Term)
+	req.Translation = strings.TrimSpace(req.Translation)
+	if req.Term == "" {
+		return unify_response.ParameterError("术语不可以为空")
+	}
+	if req.TargetLang == "" {
+		return unify_response.ParameterError("目标语言不可以为空")
+	}
+	if req.Translation == "" && !req.DoNotTranslate {
+		return unify_response.ParameterError("术语译文不可以为空")
+	}
+	var err error
+	if req.SourceLang, err = canonicalOptionalLang("source_lang", req.SourceLang); err != nil {
+		return err
+	}
+	if req.TargetLang, err = canonicalLang("target_lang", req.TargetLang, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+type UpdateGlossaryReq struct {
+	Id int64 `json:"id"`
+	CreateGlossaryReq
+}
+
+func (req *UpdateGlossaryReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	if req.Id == 0 {
+		return unify_response.ParameterError("术语ID不可以为空")
+	}
+	return req.check()
+}
+
+type DeleteGlossaryReq struct {
+	Id int64 `json:"id"`
+}
+
+func (req *DeleteGlossaryReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	if req.Id == 0 {
+		return unify_response.ParameterError("术语ID不可以为空")
+	}
+	return nil
+}
+
+type ListGlossaryReq struct {
+	SourceLang string `form:"source_lang"`
+	TargetLang string `form:"target_lang"`
+	Page       int    `form:"page"`
+	Size       int    `form:"size"`
+}
+
+func (req *ListGlossaryReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindQuery(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	normalizePage(&req.Page, &req.Size)
+	if req.SourceLang, err = canonicalOptionalLang("source_lang", req.SourceLang); err != nil {
+		return err
+	}
+	if req.TargetLang, err = canonicalOptionalLang("target_lang", req.TargetLang); err != nil {
+		return err
+	}
+	return nil
+}
+
+// normalizePage 分页参数缺省时取第一页，每页条数限制在 maxPageSize 以内
+func normalizePage(page, size *int) {
+	if *page <= 0 {
+		*page = 1
+	}
+	if *size <= 0 {
+		*size = defaultPageSize
+	}
+	if *size > maxPageSize {
+		*size = maxPageSize
+	}
+}
+
+// parseQueryId 解析查询参数中的 ID
+func parseQueryId(c *gin.Context, key string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Query(key), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+type GlossaryDetailReq struct {
+	Id int64
+}
+
+func (req *GlossaryDetailReq) Validate(c *gin.Context) error {
+	id, ok := parseQueryId(c, "id")
+	if !ok {
+		return unify_response.ParameterError("术语ID不能为空")
+	}
+	req.Id = id
+	return nil
+}
@@ -0,0 +1,31 @@
+package request_mapping
+
+import (
+	"fmt"
+
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/language"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+// canonicalLang 校验并规范化语言代码，allowAuto 为 true 时允许 auto-detect
+func canonicalLang(field string, code string, allowAuto bool) (string, error) {
+	if allowAuto && code == models.AutoDetect {
+		return code, nil
+	}
+	canonical, err := language.Canonicalize(code)
+	if err != nil {
+		return "", unify_response.ParameterError("语言代码不合法", map[string]string{
+			field: fmt.Sprintf("无法识别的语言代码: %s", code),
+		})
+	}
+	return canonical, nil
+}
+
+// canonicalOptionalLang 语言代码为空时不校验
+func canonicalOptionalLang(field string, code string) (string, error) {
+	if code == "" {
+		return "", nil
+	}
+	return canonicalLang(field, code, false)
+}
@@ -0,0 +1,100 @@
+package request_mapping
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// templateNamePattern 模板名称只允许小写字母、数字、下划线和中划线
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+func checkTemplateName(name string) error {
+	if !templateNamePattern.MatchString(name) {
+		return unify_response.ParameterError("模板名称不合法", map[string]string{
+			"name": "只允许小写字母、数字、下划线和中划线，长度不超过 64",
+		})
+	}
+	return nil
+}
+
+type CreatePromptTemplateReq struct {
+	Name        string `json:"name"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+}
+
+func (req *CreatePromptTemplateReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err = checkTemplateName(req.Name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return unify_response.ParameterError("模板内容不可以为空")
+	}
+	return nil
+}
+
+type PromptTemplateDetailReq struct {
+	Name string
+	// Version 为空时返回最新版本
+	Version int
+}
+
+func (req *PromptTemplateDetailReq) Validate(c *gin.Context) error {
+	req.Name = c.Query("name")
+	if err := checkTemplateName(req.Name); err != nil {
+		return err
+	}
+	if v := c.Query("version"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil || version < 0 {
+			return unify_response.ParameterError("模板版本不合法")
+		}
+		req.Version = version
+	}
+	return nil
+}
+
+type ListPromptTemplateReq struct {
+	Name string `form:"name"`
+	Page int    `form:"page"`
+	Size int    `form:"size"`
+}
+
+func (req *ListPromptTemplateReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindQuery(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	normalizePage(&req.Page, &req.Size)
+	return nil
+}
+
+type ListTemplateTasksReq struct {
+	Name    string `form:"name"`
+	Version int    `form:"version"`
+	Page    int    `form:"page"`
+	Size    int    `form:"size"`
+}
+
+func (req *ListTemplateTasksReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindQuery(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	if err = checkTemplateName(req.Name); err != nil {
+		return err
+	}
+	if req.Version < 0 {
+		return unify_response.ParameterError("模板版本不合法")
+	}
+	normalizePage(&req.Page, &req.Size)
+	return nil
+}
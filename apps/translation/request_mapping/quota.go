@@ -0,0 +1,101 @@
+package request_mapping
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"strings"
+)
+
+// CreateQuotaPlanReq 各项上限为 0 表示不限制
+type CreateQuotaPlanReq struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	DailyChars    int64  `json:"daily_chars"`
+	MonthlyChars  int64  `json:"monthly_chars"`
+	DailyTokens   int64  `json:"daily_tokens"`
+	MonthlyTokens int64  `json:"monthly_tokens"`
+}
+
+func (req *CreateQuotaPlanReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !templateNamePattern.MatchString(req.Name) {
+		return unify_response.ParameterError("套餐名称不合法", map[string]string{
+			"name": "只允许小写字母、数字、下划线和中划线，长度不超过 64",
+		})
+	}
+	return req.checkLimits()
+}
+
+func (req *CreateQuotaPlanReq) checkLimits() error {
+	fields := make(map[string]string)
+	for field, value := range map[string]int64{
+		"daily_chars":    req.DailyChars,
+		"monthly_chars":  req.MonthlyChars,
+		"daily_tokens":   req.DailyTokens,
+		"monthly_tokens": req.MonthlyTokens,
+	} {
+		if value < 0 {
+			fields[field] = "不能为负数，0 表示不限制"
+		}
+	}
+	if len(fields) > 0 {
+		return unify_response.ParameterError("配额上限不合法", fields)
+	}
+	return nil
+}
+
+// UpdateQuotaPlanReq 套餐名称不可修改，其余字段整体覆盖
+type UpdateQuotaPlanReq struct {
+	Id int64 `json:"id"`
+	CreateQuotaPlanReq
+}
+
+func (req *UpdateQuotaPlanReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	if req.Id <= 0 {
+		return unify_response.ParameterError("套餐ID不可以为空")
+	}
+	return req.checkLimits()
+}
+
+// AssignQuotaPlanReq PlanId 为 0 表示恢复使用默认套餐
+type AssignQuotaPlanReq struct {
+	Username string `json:"username"`
+	PlanId   int64  `json:"plan_id"`
+}
+
+func (req *AssignQuotaPlanReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" {
+		return unify_response.ParameterError("用户名不可以为空")
+	}
+	if req.PlanId < 0 {
+		return unify_response.ParameterError("套餐ID不合法")
+	}
+	return nil
+}
+
+// QuotaBalanceReq Username 仅管理员可指定，为空时查询自己
+type QuotaBalanceReq struct {
+	Username string `form:"username"`
+}
+
+func (req *QuotaBalanceReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindQuery(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	return nil
+}
@@ -1,14 +1,42 @@
 package request_mapping
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/formats"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/placeholder"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"slices"
+	"strings"
 )
 
+const (
+	// maxTargetLangs 单个任务最多的目标语言数
+	maxTargetLangs = 20
+	// maxContextTasks 单个任务最多引用的前序任务数
+	maxContextTasks = 10
+)
+
+// CreateTaskReq 同时用于上传文件创建任务，此时除 Content 外的字段来自表单
 type CreateTaskReq struct {
-	Content    string `json:"content"`
-	Lang       string `json:"lang"`
-	TargetLang string `json:"target_lang"`
+	Content    string `json:"content" form:"-"`
+	Lang       string `json:"lang" form:"lang"`
+	TargetLang string `json:"target_lang" form:"target_lang"`
+	// TargetLangs 多个目标语言，与 TargetLang 合并去重后超过一个时创建多目标语言任务
+	TargetLangs []string `json:"target_langs" form:"target_langs"`
+	// Template 提示词模板名称，为空时使用默认模板
+	Template  string `json:"template" form:"template"`
+	Tone      string `json:"tone" form:"tone"`
+	Formality string `json:"formality" form:"formality"`
+	Domain    string `json:"domain" form:"domain"`
+	// NoCache 不使用译文缓存，例如需要重新生成译文时
+	NoCache bool `json:"no_cache" form:"no_cache"`
+	// PlaceholderMode 占位符保护：off 不保护，warn 记录不一致的占位符(默认)，strict 不一致时任务失败
+	PlaceholderMode string `json:"placeholder_mode" form:"placeholder_mode"`
+	// ContextTaskIds 同一系列中已完成的任务，其原文与译文作为示例提供给模型，保持术语与风格一致
+	ContextTaskIds []int64 `json:"context_task_ids" form:"context_task_ids"`
 }
 
 func (req *CreateTaskReq) Validate(c *gin.Context) error {
@@ -16,15 +44,114 @@ func (req *CreateTaskReq) Validate(c *gin.Context) error {
 	if err != nil {
 		return unify_response.ParameterError("参数错误")
 	}
+	return req.check()
+}
+
+// check 校验并规范化绑定后的参数
+func (req *CreateTaskReq) check() (err error) {
 	if req.Content == "" {
 		return unify_response.ParameterError("内容不可以为空")
 	}
 	if req.Lang == "" {
-		req.Lang = "auto-detect"
+		req.Lang = models.AutoDetect
+	}
+	if req.Lang, err = canonicalLang("lang", req.Lang, true); err != nil {
+		return err
+	}
+	if req.Template != "" {
+		if err = checkTemplateName(req.Template); err != nil {
+			return err
+		}
+	}
+	if err = req.checkStyle(); err != nil {
+		return err
+	}
+	if req.PlaceholderMode == "" {
+		req.PlaceholderMode = placeholder.ModeWarn
+	}
+	if !slices.Contains(placeholder.Modes(), req.PlaceholderMode) {
+		return unify_response.ParameterError("占位符保护模式不合法", map[string]string{
+			"placeholder_mode": fmt.Sprintf("可选值: %s", strings.Join(placeholder.Modes(), ", ")),
+		})
+	}
+	if err = req.checkContextTasks(); err != nil {
+		return err
+	}
+	return req.checkTargetLangs()
+}
+
+// checkContextTasks 去重并校验引用的任务数量，任务归属在创建时校验
+func (req *CreateTaskReq) checkContextTasks() error {
+	var ids []int64
+	for _, id := range req.ContextTaskIds {
+		if id <= 0 {
+			return unify_response.ParameterError("引用的任务ID不合法")
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > maxContextTasks {
+		return unify_response.ParameterError(fmt.Sprintf("最多引用 %d 个任务", maxContextTasks))
+	}
+	req.ContextTaskIds = ids
+	return nil
+}
+
+// checkStyle 校验语气、正式程度与领域的取值
+func (req *CreateTaskReq) checkStyle() error {
+	options := llm.StyleOptions()
+	fields := make(map[string]string)
+	for field, value := range map[string]string{
+		"tone":      req.Tone,
+		"formality": req.Formality,
+		"domain":    req.Domain,
+	} {
+		if value != "" && !slices.Contains(options[field], value) {
+			fields[field] = fmt.Sprintf("可选值: %s", strings.Join(options[field], ", "))
+		}
+	}
+	if len(fields) > 0 {
+		return unify_response.ParameterError("翻译风格参数不合法", fields)
+	}
+	return nil
+}
+
+// checkTargetLangs 规范化并合并目标语言，结果只有一个时放在 TargetLang，否则放在 TargetLangs
+func (req *CreateTaskReq) checkTargetLangs() error {
+	var codes []string
+	fields := make(map[string]string)
+	if req.TargetLang != "" {
+		code, err := canonicalLang("target_lang", req.TargetLang, false)
+		if err != nil {
+			return err
+		}
+		codes = append(codes, code)
 	}
-	if req.TargetLang == "" {
+	for i, lang := range req.TargetLangs {
+		code, err := canonicalLang("target_langs", lang, false)
+		if err != nil {
+			fields[fmt.Sprintf("target_langs[%d]", i)] = fmt.Sprintf("无法识别的语言代码: %s", lang)
+			continue
+		}
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	if len(fields) > 0 {
+		return unify_response.ParameterError("语言代码不合法", fields)
+	}
+	if len(codes) == 0 {
 		return unify_response.ParameterError("目标语言不可以为空")
 	}
+	if len(codes) > maxTargetLangs {
+		return unify_response.ParameterError(fmt.Sprintf("目标语言最多 %d 个", maxTargetLangs))
+	}
+	if len(codes) == 1 {
+		req.TargetLang, req.TargetLangs = codes[0], nil
+		return nil
+	}
+	req.TargetLang, req.TargetLangs = "", codes
 	return nil
 }
 
@@ -42,3 +169,56 @@ func (req *ExecuteTaskReq) Validate(c *gin.Context) error {
 	}
 	return nil
 }
+
+type DownloadTaskReq struct {
+	Id int64
+	// Lang 多目标语言任务只下载指定语言，为空时下载全部已完成语言的压缩包
+	Lang string
+	// Format 导出格式，为空时下载译文文件，xliff/xliff2 把纯文本任务导出为双语 XLIFF 1.2/2.0
+	Format string
+}
+
+func (req *DownloadTaskReq) Validate(c *gin.Context) error {
+	id, ok := parseQueryId(c, "id")
+	if !ok {
+		return unify_response.ParameterError("任务ID不能为空")
+	}
+	req.Id = id
+	lang, err := canonicalOptionalLang("lang", c.Query("lang"))
+	if err != nil {
+		return err
+	}
+	req.Lang = lang
+	req.Format = c.Query("format")
+	if _, ok := formats.XLIFFVersion(req.Format); req.Format != "" && !ok {
+		return unify_response.ParameterError("不支持的导出格式", map[string]string{
+			"format": fmt.Sprintf("可选值: %s, %s", formats.ExportXLIFF12, formats.ExportXLIFF20),
+		})
+	}
+	return nil
+}
+
+type ListTaskReq struct {
+	Status *int   `form:"status"`
+	Sort   string `form:"sort"`
+	Page   int    `form:"page"`
+	Size   int    `form:"size"`
+}
+
+func (req *ListTaskReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindQuery(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	switch req.Sort {
+	case "":
+		req.Sort = models.TaskSortCreated
+	case models.TaskSortCreated, models.TaskSortQuality:
+	default:
+		return unify_response.ParameterError("排序方式不合法", map[string]string{
+			"sort": fmt.Sprintf("可选值: %s, %s", models.TaskSortCreated, models.TaskSortQuality),
+		})
+	}
+	normalizePage(&req.Page, &req.Size)
+	return nil
+}
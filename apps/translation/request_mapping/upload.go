@@ -0,0 +1,132 @@
+package request_mapping
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/formats"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxUploadSize = 2 << 20
+	// multipartOverhead 表单中文件以外的字段与分隔符
+	multipartOverhead = 64 << 10
+	maxFilenameLength = 255
+	// minLineChars、maxLineChars 字幕每行字符数的取值范围
+	minLineChars = 10
+	maxLineChars = 200
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// UploadTaskReq 上传文件创建任务，文件内容保存在 Content 中，其余参数与 CreateTaskReq 相同
+type UploadTaskReq struct {
+	CreateTaskReq
+	// MaxLineChars 字幕译文每行最多的字符数，超出时重新换行，0 为保留模型返回的换行
+	MaxLineChars int `form:"max_line_chars"`
+	// TranslateAll PO、XLIFF 文件中已有译文的条目也重新翻译
+	TranslateAll bool   `form:"translate_all"`
+	Filename     string `form:"-"`
+	Format       string `form:"-"`
+}
+
+func (req *UploadTaskReq) Validate(c *gin.Context) error {
+	maxSize := int64(defaultMaxUploadSize)
+	if uploadConfig := config.GetConfig().Upload; uploadConfig != nil && uploadConfig.MaxSize > 0 {
+		maxSize = uploadConfig.MaxSize
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	if err := c.ShouldBind(req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return unify_response.ParameterError(fmt.Sprintf("文件不能超过 %d KB", maxSize>>10))
+		}
+		return unify_response.ParameterError("参数错误")
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return unify_response.ParameterError("请上传文件")
+	}
+	if fileHeader.Size > maxSize {
+		return unify_response.ParameterError(fmt.Sprintf("文件不能超过 %d KB", maxSize>>10))
+	}
+	req.Filename = filepath.Base(strings.ReplaceAll(fileHeader.Filename, "\\", "/"))
+	if req.Filename == "" || req.Filename == "." || len(req.Filename) > maxFilenameLength {
+		return unify_response.ParameterError("文件名不合法")
+	}
+	handler, ok := formats.ForFilename(req.Filename)
+	if !ok {
+		return unify_response.ParameterError("不支持的文件类型", map[string]string{
+			"file": fmt.Sprintf("支持的扩展名: %s", strings.Join(formats.Extensions(), ", ")),
+		})
+	}
+	req.Format = handler.Name()
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return unify_response.ParameterError("读取文件失败")
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxSize))
+	if err != nil {
+		return unify_response.ParameterError("读取文件失败")
+	}
+	if err = checkTextContent(data); err != nil {
+		return err
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+	doc, err := handler.Parse(data, formats.Options{TranslateAll: req.TranslateAll})
+	if err != nil {
+		return unify_response.ParameterError("文件内容与格式不符", map[string]string{
+			"file": err.Error(),
+		})
+	}
+	if len(doc.Units()) == 0 {
+		return unify_response.ParameterError("文件中没有需要翻译的内容")
+	}
+	if req.MaxLineChars != 0 {
+		if !formats.WrapsLines(req.Format) {
+			return unify_response.ParameterError("只有字幕文件可以限制每行字符数")
+		}
+		if req.MaxLineChars < minLineChars || req.MaxLineChars > maxLineChars {
+			return unify_response.ParameterError(fmt.Sprintf("每行字符数应在 %d 到 %d 之间", minLineChars, maxLineChars))
+		}
+	}
+	req.Content = string(data)
+	req.TargetLangs = splitFormValues(req.TargetLangs)
+	return req.check()
+}
+
+// checkTextContent 按内容识别文件类型，只接受 UTF-8 编码的文本，不信任客户端声明的 Content-Type
+func checkTextContent(data []byte) error {
+	mediaType, params, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || !strings.HasPrefix(mediaType, "text/") {
+		return unify_response.ParameterError("文件不是文本文件")
+	}
+	if charset := params["charset"]; (charset != "" && !strings.EqualFold(charset, "utf-8")) || !utf8.Valid(data) {
+		return unify_response.ParameterError("文件必须使用 UTF-8 编码")
+	}
+	return nil
+}
+
+// splitFormValues 表单中的多个值可以重复传递，也可以用逗号分隔
+func splitFormValues(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,67 @@
+package request_mapping
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"time"
+)
+
+const (
+	usageDateLayout = "2006-01-02"
+	// 未指定日期范围时统计最近 30 天，单次最多统计 366 天
+	defaultUsageDays = 30
+	maxUsageDays     = 366
+)
+
+type UsageReq struct {
+	// Start、End 为 yyyy-mm-dd，包含首尾两天
+	Start   string `form:"start"`
+	End     string `form:"end"`
+	GroupBy string `form:"group_by"`
+	// Username 仅管理员可指定，管理员未指定时统计全部用户
+	Username string `form:"username"`
+	// StartTime、EndTime 解析后的时间范围 [StartTime, EndTime)
+	StartTime time.Time `form:"-"`
+	EndTime   time.Time `form:"-"`
+}
+
+func (req *UsageReq) Validate(c *gin.Context) error {
+	err := c.ShouldBindQuery(req)
+	if err != nil {
+		return unify_response.ParameterError("参数错误")
+	}
+	switch req.GroupBy {
+	case "":
+		req.GroupBy = models.UsageGroupLangPair
+	case models.UsageGroupLangPair, models.UsageGroupProvider, models.UsageGroupUser, models.UsageGroupDay:
+	default:
+		return unify_response.ParameterError("分组方式不合法", map[string]string{
+			"group_by": fmt.Sprintf("可选值: %s, %s, %s, %s", models.UsageGroupLangPair,
+				models.UsageGroupProvider, models.UsageGroupUser, models.UsageGroupDay),
+		})
+	}
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if req.End != "" {
+		if end, err = time.ParseInLocation(usageDateLayout, req.End, time.Local); err != nil {
+			return unify_response.ParameterError("日期格式错误", map[string]string{"end": "格式为 yyyy-mm-dd"})
+		}
+	}
+	start := end.AddDate(0, 0, 1-defaultUsageDays)
+	if req.Start != "" {
+		if start, err = time.ParseInLocation(usageDateLayout, req.Start, time.Local); err != nil {
+			return unify_response.ParameterError("日期格式错误", map[string]string{"start": "格式为 yyyy-mm-dd"})
+		}
+	}
+	if start.After(end) {
+		return unify_response.ParameterError("开始日期不能晚于结束日期")
+	}
+	if start.AddDate(0, 0, maxUsageDays).Before(end.AddDate(0, 0, 1)) {
+		return unify_response.ParameterError(fmt.Sprintf("统计范围最多 %d 天", maxUsageDays))
+	}
+	req.StartTime, req.EndTime = start, end.AddDate(0, 0, 1)
+	req.Start, req.End = start.Format(usageDateLayout), end.Format(usageDateLayout)
+	return nil
+}
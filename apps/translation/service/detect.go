@@ -0,0 +1,47 @@
+package service
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/langdetect"
+	"strings"
+	"unicode/utf8"
+)
+
+// 短文本中相近语言(例如俄语与保加利亚语)的区分度很低，置信度与长度都足够时才采用识别结果
+const (
+	defaultMinDetectConfidence = 0.8
+	defaultMinDetectLength     = 20
+)
+
+func getMinDetectConfidence() float64 {
+	detectConfig := config.GetConfig().LangDetect
+	if detectConfig != nil && detectConfig.MinConfidence > 0 && detectConfig.MinConfidence <= 1 {
+		return detectConfig.MinConfidence
+	}
+	return defaultMinDetectConfidence
+}
+
+func getMinDetectLength() int {
+	detectConfig := config.GetConfig().LangDetect
+	if detectConfig != nil && detectConfig.MinLength > 0 {
+		return detectConfig.MinLength
+	}
+	return defaultMinDetectLength
+}
+
+// detectSourceLang 源语言为 auto-detect 时识别 text 的语言并记录结果
+// 置信度足够且内容不太短时直接作为任务的源语言，否则保留 auto-detect 交给模型判断，
+// 未被采用的识别结果只用于展示，术语、回译等按源语言处理的地方都以 Lang 为准
+func detectSourceLang(task *models.TaskModel, text string) {
+	if task.Lang != models.AutoDetect {
+		return
+	}
+	result := langdetect.Detect(text)
+	task.DetectedLang = result.Lang
+	task.DetectConfidence = result.Confidence
+	if result.Lang != "" && result.Confidence >= getMinDetectConfidence() &&
+		utf8.RuneCountInString(strings.TrimSpace(text)) >= getMinDetectLength() {
+		task.Lang = result.Lang
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+)
+
+func TestDetectSourceLang(t *testing.T) {
+	newTestTaskService(t)
+	cases := []struct {
+		text string
+		want string
+	}{
+		// 短俄语会被识别为保加利亚语，置信度与长度都不足时保留 auto-detect
+		{"Привет, как дела?", models.AutoDetect},
+		{"Привет, как дела? Сегодня хорошая погода, пойдём гулять в парк.", "ru"},
+		{"Hello there", models.AutoDetect},
+		{"This is a longer English sentence about the weather.", "en"},
+	}
+	for _, c := range cases {
+		task := &models.TaskModel{Lang: models.AutoDetect}
+		detectSourceLang(task, c.text)
+		if task.Lang != c.want {
+			t.Errorf("%q: lang = %q (detected %q at %.2f), want %q",
+				c.text, task.Lang, task.DetectedLang, task.DetectConfidence, c.want)
+		}
+	}
+}
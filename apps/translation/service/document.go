@@ -0,0 +1,171 @@
+package service
+
+import (
+	"fmt"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/formats"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 任务原文按格式解析为若干翻译单元，每个单元再按长度切分为分段，所有分段统一并发翻译，
+// 译文按单元拼接后交给格式生成目标文件；纯文本只有一个单元，与直接切分原文等价
+
+// parseTaskDocument 按任务格式解析原文，未记录格式的任务为纯文本
+func parseTaskDocument(task *models.TaskModel) (formats.IHandler, formats.IDocument, error) {
+	handler, ok := formats.Get(task.Format)
+	if !ok {
+		return nil, nil, fmt.Errorf("不支持的文件格式: %s", task.Format)
+	}
+	doc, err := handler.Parse([]byte(task.Content), formats.Options{
+		TargetLang:   task.TargetLang,
+		MaxLineChars: task.MaxLineChars,
+		TranslateAll: task.TranslateAll,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return handler, doc, nil
+}
+
+// unitSpan 一个单元在分段列表中的区间 [start, end)
+type unitSpan struct {
+	start, end int
+}
+
+// splitUnits 切分各单元并重新编号，单元的上下文作为其第一个分段的上下文
+func splitUnits(units []formats.Unit, opt segmenter.Options) ([]segmenter.Segment, []unitSpan) {
+	var segments []segmenter.Segment
+	spans := make([]unitSpan, 0, len(units))
+	for _, unit := range units {
+		start := len(segments)
+		parts := segmenter.Split(unit.Text, opt)
+		if len(parts) > 0 && parts[0].Context == "" {
+			parts[0].Context = unit.Context
+		}
+		segments = append(segments, parts...)
+		spans = append(spans, unitSpan{start: start, end: len(segments)})
+	}
+	for i := range segments {
+		segments[i].Index = i
+	}
+	return segments, spans
+}
+
+// unitValidator 文档对单元译文有结构要求时返回分段译文的检查函数，
+// 只检查单独成段的单元，被切分的单元由 Render 检查拼接后的译文
+func unitValidator(doc formats.IDocument, spans []unitSpan) func(int, string) error {
+	validator, ok := doc.(formats.IUnitValidator)
+	if !ok {
+		return nil
+	}
+	units := make(map[int]int, len(spans))
+	for i, span := range spans {
+		if span.end-span.start == 1 {
+			units[span.start] = i
+		}
+	}
+	return func(segment int, translation string) error {
+		unit, ok := units[segment]
+		if !ok {
+			return nil
+		}
+		return validator.ValidateUnit(unit, translation)
+	}
+}
+
+// joinUnits 按单元拼接分段译文
+func joinUnits(segments []segmenter.Segment, spans []unitSpan, translations []string) []string {
+	list := make([]string, 0, len(spans))
+	for _, span := range spans {
+		list = append(list, segmenter.Join(segments[span.start:span.end], translations[span.start:span.end]))
+	}
+	return list
+}
+
+// documentText 各单元的原文，用于识别语言等只关心文字内容的场景
+func documentText(doc formats.IDocument) string {
+	units := doc.Units()
+	texts := make([]string, 0, len(units))
+	for _, unit := range units {
+		texts = append(texts, unit.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+// taskText 任务需要翻译的文字，与创建任务时预估配额使用的内容相同，解析失败时为原文
+func taskText(task *models.TaskModel) string {
+	_, doc, err := parseTaskDocument(task)
+	if err != nil {
+		return task.Content
+	}
+	return documentText(doc)
+}
+
+// exampleText 文件任务的原文与译文去掉格式标记后作为示例，双语文件或解析失败时不使用该任务
+func exampleText(task *models.TaskModel, result []byte) (string, string, bool) {
+	handler, doc, err := parseTaskDocument(task)
+	if err != nil || formats.Bilingual(handler.Name()) {
+		return "", "", false
+	}
+	if handler.Name() == formats.FormatText {
+		return task.Content, string(result), true
+	}
+	translated, err := handler.Parse(result, formats.Options{})
+	if err != nil {
+		return "", "", false
+	}
+	return documentText(doc), documentText(translated), true
+}
+
+// resultExtension 译文文件沿用上传文件的扩展名，纯文本任务为 .txt
+func resultExtension(task *models.TaskModel, handler formats.IHandler) string {
+	if ext := strings.ToLower(filepath.Ext(task.OriginalFilename)); ext != "" {
+		return ext
+	}
+	return handler.Extensions()[0]
+}
+
+// resultFilename 下载时的文件名，上传的文件在扩展名前加上目标语言
+func resultFilename(task *models.TaskModel) string {
+	if task.OriginalFilename != "" {
+		return formats.TargetFilename(task.OriginalFilename, task.TargetLang)
+	}
+	return fmt.Sprintf("task-%d-%s.txt", task.ID, task.TargetLang)
+}
+
+// exportBilingual 把纯文本任务的原文与译文导出为双语 XLIFF，按段落对应
+func exportBilingual(task *models.TaskModel, file *ResultFile, format string) error {
+	version, ok := formats.XLIFFVersion(format)
+	if !ok {
+		return unify_response.ParameterError("不支持的导出格式")
+	}
+	if task.Format != "" && task.Format != formats.FormatText {
+		return unify_response.ParameterError("只有纯文本任务可以导出为 XLIFF")
+	}
+	result, err := os.ReadFile(task.ResultKey)
+	if err != nil {
+		return unify_response.NotFound()
+	}
+	source := task.Lang
+	if source == models.AutoDetect {
+		// 未识别出源语言时使用 BCP 47 的 und
+		source = "und"
+	}
+	original := task.OriginalFilename
+	if original == "" {
+		original = fmt.Sprintf("task-%d.txt", task.ID)
+	}
+	content, err := formats.ExportXLIFF(version, source, task.TargetLang, original,
+		formats.AlignParagraphs(task.Content, string(result)))
+	if err != nil {
+		return err
+	}
+	file.Content = content
+	file.ContentType = formats.XLIFFContentType
+	file.Filename = strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename)) + ".xlf"
+	return nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/language"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"go.uber.org/zap"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const defaultContextMaxTokens = 2000
+
+func getContextMaxTokens() int {
+	contextConfig := config.GetConfig().DocumentContext
+	if contextConfig == nil || contextConfig.MaxTokens <= 0 {
+		return defaultContextMaxTokens
+	}
+	return contextConfig.MaxTokens
+}
+
+// checkContextTasks 引用的任务必须属于当前用户
+func checkContextTasks(taskDao dao.ITaskDao, username string, ids []int64) error {
+	list, err := taskDao.ListTasksByIds(username, ids)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, id := range ids {
+		if !slices.ContainsFunc(list, func(task *models.TaskModel) bool { return int64(task.ID) == id }) {
+			missing = append(missing, strconv.FormatInt(id, 10))
+		}
+	}
+	if len(missing) > 0 {
+		return unify_response.ParameterError("引用的任务不存在", map[string]string{
+			"context_task_ids": strings.Join(missing, ","),
+		})
+	}
+	return nil
+}
+
+// contextExample 引用任务中与当前目标语言一致的已完成译文
+type contextExample struct {
+	taskId  int64
+	example llm.Example
+}
+
+// loadContextExamples 加载引用任务的原文与译文，多目标语言任务取相同目标语言的子任务，
+// 未完成或目标语言不同的任务跳过；从最新的任务开始放入，超出 token 预算时舍弃更早的任务
+// 返回的示例按时间从早到晚排列，used 为实际使用的任务
+func loadContextExamples(taskDao dao.ITaskDao, task *models.TaskModel) ([]llm.Example, []int64, error) {
+	ids := splitTaskIds(task.ContextTaskIds)
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+	list, err := taskDao.ListTasksByIds(task.CreateBy, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	var candidates []contextExample
+	for _, ref := range list {
+		// 引用的是子任务本身，或是引用了父任务时对应目标语言的子任务
+		referenced := slices.Contains(ids, int64(ref.ID)) || slices.Contains(ids, int64(ref.ParentId))
+		if !referenced || isParentTask(ref) || ref.Status != models.TaskStatusDone ||
+			!language.Same(ref.TargetLang, task.TargetLang) {
+			continue
+		}
+		data, err := os.ReadFile(ref.ResultKey)
+		if err != nil {
+			logger.Warn("read context task result failed", zap.Uint("task_id", ref.ID), zap.Error(err))
+			continue
+		}
+		source, target, ok := exampleText(ref, data)
+		if !ok {
+			continue
+		}
+		taskId := int64(ref.ID)
+		if ref.ParentId != 0 && !slices.Contains(ids, taskId) {
+			taskId = int64(ref.ParentId)
+		}
+		candidates = append(candidates, contextExample{
+			taskId:  taskId,
+			example: llm.Example{Source: source, Target: target},
+		})
+	}
+	budget := getContextMaxTokens()
+	start := len(candidates)
+	for start > 0 {
+		e := candidates[start-1].example
+		tokens := segmenter.EstimateTokens(e.Source) + segmenter.EstimateTokens(e.Target)
+		if tokens > budget {
+			break
+		}
+		budget -= tokens
+		start--
+	}
+	var examples []llm.Example
+	var used []int64
+	for _, c := range candidates[start:] {
+		examples = append(examples, c.example)
+		used = append(used, c.taskId)
+	}
+	return examples, used, nil
+}
+
+func joinTaskIds(ids []int64) string {
+	items := make([]string, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(items, ",")
+}
+
+func splitTaskIds(s string) []int64 {
+	if s == "" {
+		return nil
+	}
+	var ids []int64
+	for _, item := range strings.Split(s, ",") {
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("invalid task id: %s", item))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/glossary"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/language"
+)
+
+type IGlossaryService interface {
+	CreateGlossary(username string, req *request_mapping.CreateGlossaryReq) error
+	UpdateGlossary(username string, req *request_mapping.UpdateGlossaryReq) error
+	DeleteGlossary(username string, id int64) error
+	GetGlossaryDetail(username string, id int64) (*GlossaryData, error)
+	ListGlossary(username string, req *request_mapping.ListGlossaryReq) ([]*GlossaryData, int64, error)
+}
+
+type glossaryService struct {
+	glossaryDao dao.IGlossaryDao
+}
+
+func NewGlossaryService(glossaryDao dao.IGlossaryDao) IGlossaryService {
+	return &glossaryService{
+		glossaryDao: glossaryDao,
+	}
+}
+
+func (g *glossaryService) CreateGlossary(username string, req *request_mapping.CreateGlossaryReq) error {
+	return g.glossaryDao.CreateGlossary(&models.GlossaryModel{
+		CreateBy:       username,
+		Term:           req.Term,
+		SourceLang:     req.SourceLang,
+		TargetLang:     req.TargetLang,
+		Translation:    req.Translation,
+		CaseSensitive:  req.CaseSensitive,
+		DoNotTranslate: req.DoNotTranslate,
+	})
+}
+
+func (g *glossaryService) UpdateGlossary(username string, req *request_mapping.UpdateGlossaryReq) error {
+	_, err := g.glossaryDao.GetGlossaryByIdAndUsername(username, req.Id)
+	if err != nil {
+		return err
+	}
+	return g.glossaryDao.UpdateGlossary(username, req.Id, map[string]any{
+		"term":             req.Term,
+		"source_lang":      req.SourceLang,
+		"target_lang":      req.TargetLang,
+		"translation":      req.Translation,
+		"case_sensitive":   req.CaseSensitive,
+		"do_not_translate": req.DoNotTranslate,
+	})
+}
+
+func (g *glossaryService) DeleteGlossary(username string, id int64) error {
+	return g.glossaryDao.DeleteGlossary(username, id)
+}
+
+func (g *glossaryService) GetGlossaryDetail(username string, id int64) (*GlossaryData, error) {
+	data, err := g.glossaryDao.GetGlossaryByIdAndUsername(username, id)
+	if err != nil {
+		return nil, err
+	}
+	return toGlossaryData(data), nil
+}
+
+func (g *glossaryService) ListGlossary(
+	username string, req *request_mapping.ListGlossaryReq) ([]*GlossaryData, int64, error) {
+	list, count, err := g.glossaryDao.ListGlossary(username, req.SourceLang, req.TargetLang, req.Page, req.Size)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]*GlossaryData, 0, len(list))
+	for _, data := range list {
+		items = append(items, toGlossaryData(data))
+	}
+	return items, count, nil
+}
+
+func toGlossaryData(data *models.GlossaryModel) *GlossaryData {
+	return &GlossaryData{
+		Id:             int(data.ID),
+		Term:           data.Term,
+		SourceLang:     data.SourceLang,
+		TargetLang:     data.TargetLang,
+		Translation:    data.Translation,
+		CaseSensitive:  data.CaseSensitive,
+		DoNotTranslate: data.DoNotTranslate,
+	}
+}
+
+// loadGlossary 加载任务可用的术语，语言相同即可使用，例如 zh 的术语用于 zh-CN 的任务，源语言未确定时不按源语言筛选
+func loadGlossary(glossaryDao dao.IGlossaryDao, taskData *models.TaskModel) ([]glossary.Entry, error) {
+	lang := taskData.Lang
+	list, err := glossaryDao.FindGlossaryByLangPair(taskData.CreateBy, lang, taskData.TargetLang)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]glossary.Entry, 0, len(list))
+	for _, item := range list {
+		if !language.Same(item.TargetLang, taskData.TargetLang) {
+			continue
+		}
+		if item.SourceLang != "" && lang != models.AutoDetect && !language.Same(item.SourceLang, lang) {
+			continue
+		}
+		entries = append(entries, glossary.Entry{
+			Term:           item.Term,
+			Translation:    item.Translation,
+			CaseSensitive:  item.CaseSensitive,
+			DoNotTranslate: item.DoNotTranslate,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+)
+
+func TestLoadGlossaryMatchesBaseLanguage(t *testing.T) {
+	glossaryDao := &memGlossaryDao{entries: []*models.GlossaryModel{
+		{Term: "cloud", Translation: "云", TargetLang: "zh"},
+		{Term: "server", Translation: "服务器", SourceLang: "en", TargetLang: "zh-CN"},
+		{Term: "file", Translation: "檔案", TargetLang: "zh-TW"},
+		{Term: "queue", Translation: "cola", TargetLang: "es"},
+		{Term: "Maus", Translation: "鼠标", SourceLang: "de", TargetLang: "zh"},
+	}}
+	cases := []struct {
+		task *models.TaskModel
+		want []string
+	}{
+		// 识别结果被采用时按源语言筛选
+		{&models.TaskModel{Lang: "en-US", DetectedLang: "en-US", TargetLang: "zh-CN"}, []string{"cloud", "server"}},
+		// 置信度不足未被采用的识别结果不参与筛选
+		{&models.TaskModel{Lang: models.AutoDetect, DetectedLang: "de", TargetLang: "zh-CN"},
+			[]string{"cloud", "server", "Maus"}},
+	}
+	for _, c := range cases {
+		entries, err := loadGlossary(glossaryDao, c.task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var terms []string
+		for _, entry := range entries {
+			terms = append(terms, entry.Term)
+		}
+		if !slices.Equal(terms, c.want) {
+			t.Errorf("lang %s: terms = %v, want %v", c.task.Lang, terms, c.want)
+		}
+	}
+}
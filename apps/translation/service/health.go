@@ -0,0 +1,48 @@
+package service
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+)
+
+// 服务商整体状态：全部可用、部分熔断、全部熔断
+const (
+	llmHealthUp       = "up"
+	llmHealthDegraded = "degraded"
+	llmHealthDown     = "down"
+)
+
+type IHealthService interface {
+	// GetLLMHealth 返回各服务商的熔断状态
+	GetLLMHealth() *LLMHealth
+}
+
+type healthService struct {
+	llmClient llm.ILLMClient
+}
+
+func NewHealthService(llmClient llm.ILLMClient) IHealthService {
+	return &healthService{llmClient: llmClient}
+}
+
+func (h *healthService) GetLLMHealth() *LLMHealth {
+	health := &LLMHealth{Status: llmHealthUp}
+	switch client := h.llmClient.(type) {
+	case llm.IHealthReporter:
+		health.Providers = client.ProvidersHealth()
+	case llm.IHealthLLMClient:
+		health.Providers = []llm.ProviderHealth{client.Health()}
+	}
+	open := 0
+	for _, p := range health.Providers {
+		if p.Breaker != nil && p.Breaker.State == llm.BreakerOpen {
+			open++
+		}
+	}
+	if open > 0 {
+		health.Status = llmHealthDegraded
+		if open == len(health.Providers) {
+			health.Status = llmHealthDown
+		}
+	}
+	return health
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/textsim"
+	"go.uber.org/zap"
+	"sort"
+)
+
+const (
+	defaultFuzzyThreshold = 0.85
+	defaultCandidateLimit = 200
+	defaultMaxReferences  = 3
+)
+
+type memoryOptions struct {
+	fuzzyThreshold float64
+	candidateLimit int
+	maxReferences  int
+}
+
+// getMemoryOptions 返回翻译记忆配置，禁用时返回 nil
+func getMemoryOptions() *memoryOptions {
+	opt := &memoryOptions{
+		fuzzyThreshold: defaultFuzzyThreshold,
+		candidateLimit: defaultCandidateLimit,
+		maxReferences:  defaultMaxReferences,
+	}
+	memoryConfig := config.GetConfig().TranslationMemory
+	if memoryConfig == nil {
+		return opt
+	}
+	if memoryConfig.Disabled {
+		return nil
+	}
+	if memoryConfig.FuzzyThreshold > 0 && memoryConfig.FuzzyThreshold <= 1 {
+		opt.fuzzyThreshold = memoryConfig.FuzzyThreshold
+	}
+	if memoryConfig.CandidateLimit > 0 {
+		opt.candidateLimit = memoryConfig.CandidateLimit
+	}
+	if memoryConfig.MaxReferences > 0 {
+		opt.maxReferences = memoryConfig.MaxReferences
+	}
+	return opt
+}
+
+// translationMemory 单个任务对翻译记忆的读写，查询失败只记录日志，不影响翻译
+// 按任务的源语言读写，只用于源语言已确定(指定或识别结果已被采用)的任务
+type translationMemory struct {
+	dao  dao.ITranslationMemoryDao
+	opt  *memoryOptions
+	task *models.TaskModel
+}
+
+// exact 原文归一化后完全一致时直接复用译文
+func (m *translationMemory) exact(text string) (string, bool) {
+	normalized := textsim.Normalize(text)
+	memory, err := m.dao.FindExactMemory(
+		m.task.CreateBy, textsim.Hash(normalized), m.task.Lang, m.task.TargetLang)
+	if err != nil {
+		logger.Warn("find translation memory failed", zap.Uint("task_id", m.task.ID), zap.Error(err))
+		return "", false
+	}
+	if memory == nil || memory.SourceText != normalized {
+		return "", false
+	}
+	return memory.Target, true
+}
+
+// fuzzy 返回相似度达到阈值的记忆，按相似度降序
+func (m *translationMemory) fuzzy(text string) []llm.Reference {
+	normalized := textsim.Normalize(text)
+	length := textsim.RuneLen(normalized)
+	// 长度相差超过 (1-threshold) 的记忆不可能达到阈值
+	minLength := int(float64(length) * m.opt.fuzzyThreshold)
+	maxLength := int(float64(length)/m.opt.fuzzyThreshold) + 1
+	candidates, err := m.dao.FindMemoryCandidates(
+		m.task.CreateBy, m.task.Lang, m.task.TargetLang, minLength, maxLength, m.opt.candidateLimit)
+	if err != nil {
+		logger.Warn("find translation memory candidates failed", zap.Uint("task_id", m.task.ID), zap.Error(err))
+		return nil
+	}
+	var refs []llm.Reference
+	for _, c := range candidates {
+		score, ok := textsim.SimilarityAtLeast(normalized, c.SourceText, m.opt.fuzzyThreshold)
+		if ok {
+			refs = append(refs, llm.Reference{
+				Source:     c.SourceText,
+				Target:     c.Target,
+				Similarity: score,
+			})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Similarity > refs[j].Similarity
+	})
+	if len(refs) > m.opt.maxReferences {
+		refs = refs[:m.opt.maxReferences]
+	}
+	return refs
+}
+
+// save 任务完成后保存本次新翻译的分段
+func (m *translationMemory) save(segments []segmenter.Segment, outcome *segmentOutcome) {
+	var memories []*models.TranslationMemoryModel
+	for i, seg := range segments {
+		if seg.Text == "" || outcome.fromMemory[i] || outcome.translations[i] == "" {
+			continue
+		}
+		normalized := textsim.Normalize(seg.Text)
+		memories = append(memories, &models.TranslationMemoryModel{
+			CreateBy:     m.task.CreateBy,
+			SourceHash:   textsim.Hash(normalized),
+			SourceText:   normalized,
+			SourceLength: textsim.RuneLen(normalized),
+			Lang:         m.task.Lang,
+			TargetLang:   m.task.TargetLang,
+			Target:       outcome.translations[i],
+			TaskId:       m.task.ID,
+		})
+	}
+	if len(memories) == 0 {
+		return
+	}
+	if err := m.dao.SaveTranslationMemory(memories); err != nil {
+		logger.Warn("save translation memory failed", zap.Uint("task_id", m.task.ID), zap.Error(err))
+	}
+}
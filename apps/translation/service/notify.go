@@ -0,0 +1,86 @@
+package service
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
+	"go.uber.org/zap"
+	"sync/atomic"
+)
+
+// 推送给 WebSocket 客户端的消息类型
+const (
+	messageTypeStatus = "status"
+	// messageTypeDelta 某一分段新生成的增量译文，seq 为任务内递增的序号，
+	// 序号不连续说明有增量因通道拥堵被丢弃，客户端应丢弃该任务已拼接的内容，以任务完成后的结果为准
+	messageTypeDelta = "delta"
+	// messageTypeReset 某一分段此前推送的增量作废，客户端应清空该分段后重新拼接，该消息不会丢弃
+	messageTypeReset = "reset"
+	// messageTypeQuality 任务完成后的译文质量评估结果
+	messageTypeQuality = "quality"
+)
+
+// notifyStatus 推送任务状态变更，状态消息不可丢失，通道满时阻塞等待
+func (t *taskService) notifyStatus(taskData *models.TaskModel, status int, extra map[string]any) {
+	message := map[string]any{
+		"type":     messageTypeStatus,
+		"username": taskData.CreateBy,
+		"task_id":  int64(taskData.ID),
+		"status":   status,
+	}
+	if taskData.ParentId != 0 {
+		message["parent_id"] = int64(taskData.ParentId)
+	}
+	for k, v := range extra {
+		message[k] = v
+	}
+	t.notifyChannel <- message
+}
+
+func (t *taskService) notifyQuality(taskData *models.TaskModel, data *QualityData) {
+	t.sendNonBlocking(map[string]any{
+		"type":     messageTypeQuality,
+		"username": taskData.CreateBy,
+		"task_id":  int64(taskData.ID),
+		"quality":  data,
+	})
+}
+
+// notifyDelta 推送分段的增量译文，通道满时丢弃，避免拖慢翻译
+// 最终结果仍以落盘的文件为准
+func (t *taskService) notifyDelta(taskData *models.TaskModel, segment int, delta string) {
+	t.sendNonBlocking(map[string]any{
+		"type":     messageTypeDelta,
+		"username": taskData.CreateBy,
+		"task_id":  int64(taskData.ID),
+		"segment":  segment,
+		"seq":      t.nextStreamSeq(taskData),
+		"delta":    delta,
+	})
+}
+
+// notifyReset 丢失作废通知会让客户端保留错误的内容，与状态消息一样阻塞等待
+func (t *taskService) notifyReset(taskData *models.TaskModel, segment int) {
+	t.notifyChannel <- map[string]any{
+		"type":     messageTypeReset,
+		"username": taskData.CreateBy,
+		"task_id":  int64(taskData.ID),
+		"segment":  segment,
+		"seq":      t.nextStreamSeq(taskData),
+	}
+}
+
+// nextStreamSeq 被丢弃的增量同样占用序号，客户端据此发现缺失
+func (t *taskService) nextStreamSeq(taskData *models.TaskModel) int64 {
+	seq, _ := t.streamSeq.LoadOrStore(taskData.ID, &atomic.Int64{})
+	return seq.(*atomic.Int64).Add(1)
+}
+
+func (t *taskService) sendNonBlocking(message map[string]any) {
+	select {
+	case t.notifyChannel <- message:
+	default:
+		logger.Warn("notify channel is full, drop message",
+			zap.Any("task_id", message["task_id"]),
+			zap.Any("type", message["type"]))
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"fmt"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/language"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// 多目标语言任务由一个父任务和每个目标语言一个子任务组成
+// 父任务只记录原文与目标语言列表，翻译、结果文件与状态都在子任务上，父任务状态由子任务汇总
+
+func isParentTask(task *models.TaskModel) bool {
+	return task.TargetLangs != ""
+}
+
+func splitTargetLangs(targetLangs string) []string {
+	if targetLangs == "" {
+		return nil
+	}
+	return strings.Split(targetLangs, ",")
+}
+
+func (t *taskService) createParentTask(parent *models.TaskModel, targetLangs []string) (*TaskData, error) {
+	var children []*models.TaskModel
+	var sameLangs []string
+	for _, lang := range targetLangs {
+		if language.Same(parent.Lang, lang) {
+			sameLangs = append(sameLangs, lang)
+			continue
+		}
+		children = append(children, &models.TaskModel{
+			CreateBy:         parent.CreateBy,
+			Content:          parent.Content,
+			Lang:             parent.Lang,
+			TargetLang:       lang,
+			DetectedLang:     parent.DetectedLang,
+			DetectConfidence: parent.DetectConfidence,
+			TemplateName:     parent.TemplateName,
+			TemplateVersion:  parent.TemplateVersion,
+			Tone:             parent.Tone,
+			Formality:        parent.Formality,
+			Domain:           parent.Domain,
+			NoCache:          parent.NoCache,
+			PlaceholderMode:  parent.PlaceholderMode,
+			ContextTaskIds:   parent.ContextTaskIds,
+			Format:           parent.Format,
+			OriginalFilename: parent.OriginalFilename,
+			MaxLineChars:     parent.MaxLineChars,
+			TranslateAll:     parent.TranslateAll,
+		})
+	}
+	if len(sameLangs) > 0 {
+		return nil, unify_response.ParameterError("源语言与目标语言相同", map[string]string{
+			"target_langs": fmt.Sprintf("源语言已是 %s，无需翻译为 %s", parent.Lang, strings.Join(sameLangs, ",")),
+		})
+	}
+	parent.TargetLangs = strings.Join(targetLangs, ",")
+	err := t.taskDao.CreateTaskWithChildren(parent, children)
+	if err != nil {
+		return nil, err
+	}
+	item := toTaskData(parent)
+	for _, child := range children {
+		item.Children = append(item.Children, toTaskData(child))
+	}
+	return item, nil
+}
+
+// pendingChildren 返回尚未完成的子任务，已完成或执行中的语言跳过，便于只重试失败的语言
+func (t *taskService) pendingChildren(parent *models.TaskModel) ([]*models.TaskModel, error) {
+	children, err := t.taskDao.ListChildTasks(int64(parent.ID))
+	if err != nil {
+		return nil, err
+	}
+	var pending []*models.TaskModel
+	for _, child := range children {
+		if child.Status != models.TaskStatusDone && child.Status != models.TaskStatusRunning {
+			pending = append(pending, child)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, unify_response.ParameterError("没有需要执行的目标语言")
+	}
+	return pending, nil
+}
+
+// executeChildren 并发执行尚未完成的子任务，返回进入队列的子任务数
+// 某个子任务未能进入队列时不再继续，已进入队列的子任务照常执行，没有任何子任务开始时恢复父任务状态
+func (t *taskService) executeChildren(parent *models.TaskModel, pending []*models.TaskModel) (int, error) {
+	err := t.taskDao.UpdateTaskStatus(int64(parent.ID), map[string]any{"status": models.TaskStatusRunning})
+	if err != nil {
+		return 0, err
+	}
+	t.notifyStatus(parent, models.TaskStatusRunning, map[string]any{
+		"pending_langs": len(pending),
+	})
+	for i, child := range pending {
+		if err = t.executeTask(child); err == nil {
+			continue
+		}
+		if i == 0 {
+			if restoreErr := t.taskDao.UpdateTaskStatus(int64(parent.ID), map[string]any{
+				"status": parent.Status,
+			}); restoreErr != nil {
+				logger.Error(fmt.Sprintf("failed to update task status: %s", restoreErr.Error()))
+			}
+			t.notifyStatus(parent, parent.Status, nil)
+		}
+		return i, err
+	}
+	return len(pending), nil
+}
+
+// refreshParentStatus 子任务结束后汇总父任务状态，仍有子任务在执行时不更新，未执行的子任务按未完成计算
+// 每个子任务先写入自身状态再汇总，最后结束的子任务一定能看到全部结果
+func (t *taskService) refreshParentStatus(child *models.TaskModel) {
+	if child.ParentId == 0 {
+		return
+	}
+	children, err := t.taskDao.ListChildTasks(int64(child.ParentId))
+	if err != nil {
+		logger.Error("list child tasks failed", zap.Uint("parent_id", child.ParentId), zap.Error(err))
+		return
+	}
+	done, failed := 0, 0
+	for _, c := range children {
+		switch c.Status {
+		case models.TaskStatusRunning:
+			return
+		case models.TaskStatusDone:
+			done++
+		case models.TaskStatusFailed:
+			failed++
+		}
+	}
+	status := models.TaskStatusPartialDone
+	switch {
+	case done == len(children):
+		status = models.TaskStatusDone
+	case done == 0 && failed > 0:
+		status = models.TaskStatusFailed
+	case done == 0:
+		status = models.TaskStatusCreated
+	}
+	err = t.taskDao.UpdateTaskStatus(int64(child.ParentId), map[string]any{"status": status})
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to update task status: %s", err.Error()))
+		return
+	}
+	parent := &models.TaskModel{CreateBy: child.CreateBy}
+	parent.ID = child.ParentId
+	t.notifyStatus(parent, status, map[string]any{
+		"done_langs":   done,
+		"failed_langs": failed,
+	})
+}
+
+func (t *taskService) GetTaskResultFiles(username string, taskId int64, lang string, format string) ([]*ResultFile, bool, error) {
+	taskData, err := t.taskDao.GetTaskByIdAndUsername(username, taskId)
+	if err != nil {
+		return nil, false, err
+	}
+	if !isParentTask(taskData) {
+		if lang != "" && !language.Same(lang, taskData.TargetLang) {
+			return nil, false, unify_response.ParameterError("任务不包含该目标语言")
+		}
+		file, err := resultFile(taskData, format)
+		if err != nil {
+			return nil, false, err
+		}
+		return []*ResultFile{file}, false, nil
+	}
+	children, err := t.taskDao.ListChildTasks(taskId)
+	if err != nil {
+		return nil, false, err
+	}
+	if lang != "" {
+		for _, child := range children {
+			if language.Same(child.TargetLang, lang) {
+				file, err := resultFile(child, format)
+				if err != nil {
+					return nil, false, err
+				}
+				return []*ResultFile{file}, false, nil
+			}
+		}
+		return nil, false, unify_response.ParameterError("任务不包含该目标语言")
+	}
+	var files []*ResultFile
+	for _, child := range children {
+		if child.Status == models.TaskStatusDone {
+			file, err := resultFile(child, format)
+			if err != nil {
+				return nil, false, err
+			}
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, false, unify_response.ParameterError("任务未完成")
+	}
+	return files, true, nil
+}
+
+func resultFile(task *models.TaskModel, format string) (*ResultFile, error) {
+	if task.Status != models.TaskStatusDone {
+		return nil, unify_response.ParameterError("任务未完成")
+	}
+	file := &ResultFile{
+		TaskId:   int(task.ID),
+		Lang:     task.TargetLang,
+		Path:     task.ResultKey,
+		Filename: resultFilename(task),
+	}
+	if format == "" {
+		return file, nil
+	}
+	if err := exportBilingual(task, file, format); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
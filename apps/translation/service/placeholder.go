@@ -0,0 +1,63 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/placeholder"
+	"strings"
+)
+
+// maxPlaceholderErrorItems 错误信息中最多列出的占位符数量
+const maxPlaceholderErrorItems = 5
+
+// placeholderError 严格模式下译文中的占位符与原文不一致
+type placeholderError struct {
+	issues []placeholder.Issue
+}
+
+func (e *placeholderError) Error() string {
+	items := make([]string, 0, maxPlaceholderErrorItems)
+	for i, issue := range e.issues {
+		if i == maxPlaceholderErrorItems {
+			items = append(items, fmt.Sprintf("等 %d 处", len(e.issues)))
+			break
+		}
+		items = append(items, fmt.Sprintf("%s %s", issue.Kind, issue.Placeholder))
+	}
+	return "译文占位符与原文不一致: " + strings.Join(items, ", ")
+}
+
+// maskPlaceholders 未关闭占位符保护且分段中存在占位符时返回替换结果，否则返回 nil
+func maskPlaceholders(task *models.TaskModel, text string) *placeholder.Masked {
+	if task.PlaceholderMode == placeholder.ModeOff {
+		return nil
+	}
+	masked := placeholder.Mask(text)
+	if len(masked.Tokens) == 0 {
+		return nil
+	}
+	return masked
+}
+
+// placeholderIssues 汇总各分段的占位符问题
+func (o *segmentOutcome) placeholderIssues() []PlaceholderIssue {
+	var list []PlaceholderIssue
+	for i, issues := range o.issues {
+		for _, issue := range issues {
+			list = append(list, PlaceholderIssue{Segment: i, Issue: issue})
+		}
+	}
+	return list
+}
+
+func marshalPlaceholderIssues(issues []PlaceholderIssue) string {
+	if len(issues) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(issues)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"fmt"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+const (
+	templateSourceDB      = "db"
+	templateSourceConfig  = "config"
+	templateSourceBuiltin = "builtin"
+)
+
+type IPromptTemplateService interface {
+	CreatePromptTemplate(username string, req *request_mapping.CreatePromptTemplateReq) (*PromptTemplateData, error)
+	// GetPromptTemplate version 为 0 时返回当前生效的版本
+	GetPromptTemplate(name string, version int) (*PromptTemplateData, error)
+	ListPromptTemplates(req *request_mapping.ListPromptTemplateReq) ([]*PromptTemplateData, int64, error)
+	// ListTemplateTasks 查询由指定模板版本产出的任务
+	ListTemplateTasks(req *request_mapping.ListTemplateTasksReq) ([]*TaskData, int64, error)
+	GetStyleOptions() map[string][]string
+}
+
+type promptTemplateService struct {
+	templateDao dao.IPromptTemplateDao
+	taskDao     dao.ITaskDao
+}
+
+func NewPromptTemplateService(templateDao dao.IPromptTemplateDao, taskDao dao.ITaskDao) IPromptTemplateService {
+	return &promptTemplateService{
+		templateDao: templateDao,
+		taskDao:     taskDao,
+	}
+}
+
+func (p *promptTemplateService) CreatePromptTemplate(
+	username string, req *request_mapping.CreatePromptTemplateReq) (*PromptTemplateData, error) {
+	if _, err := llm.NewPromptTemplate(req.Name, 0, req.Content); err != nil {
+		return nil, unify_response.ParameterError("模板内容不合法", map[string]string{
+			"content": err.Error(),
+		})
+	}
+	template := &models.PromptTemplateModel{
+		Name:        req.Name,
+		Content:     req.Content,
+		Description: req.Description,
+		CreateBy:    username,
+	}
+	if err := p.templateDao.CreatePromptTemplate(template); err != nil {
+		return nil, err
+	}
+	return toPromptTemplateData(template), nil
+}
+
+func (p *promptTemplateService) GetPromptTemplate(name string, version int) (*PromptTemplateData, error) {
+	var (
+		data *PromptTemplateData
+		err  error
+	)
+	if version == 0 {
+		data, err = latestPromptTemplate(p.templateDao, name)
+	} else {
+		data, err = findPromptTemplate(p.templateDao, name, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, unify_response.NotFound()
+	}
+	return data, nil
+}
+
+func (p *promptTemplateService) ListPromptTemplates(
+	req *request_mapping.ListPromptTemplateReq) ([]*PromptTemplateData, int64, error) {
+	list, count, err := p.templateDao.ListPromptTemplates(req.Name, req.Page, req.Size)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]*PromptTemplateData, 0, len(list))
+	for _, template := range list {
+		items = append(items, toPromptTemplateData(template))
+	}
+	return items, count, nil
+}
+
+func (p *promptTemplateService) ListTemplateTasks(
+	req *request_mapping.ListTemplateTasksReq) ([]*TaskData, int64, error) {
+	list, count, err := p.taskDao.ListTasksByTemplate(req.Name, req.Version, req.Page, req.Size)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]*TaskData, 0, len(list))
+	for _, task := range list {
+		// 列表中不返回译文，需要时通过任务详情查看
+		items = append(items, toTaskData(task))
+	}
+	return items, count, nil
+}
+
+func (p *promptTemplateService) GetStyleOptions() map[string][]string {
+	return llm.StyleOptions()
+}
+
+func toPromptTemplateData(template *models.PromptTemplateModel) *PromptTemplateData {
+	return &PromptTemplateData{
+		Name:        template.Name,
+		Version:     template.Version,
+		Content:     template.Content,
+		Description: template.Description,
+		Source:      templateSourceDB,
+		CreateBy:    template.CreateBy,
+	}
+}
+
+// latestPromptTemplate 查找当前生效的模板，依次查找数据库最新版本、配置文件、内置模板，不存在时返回 nil
+func latestPromptTemplate(templateDao dao.IPromptTemplateDao, name string) (*PromptTemplateData, error) {
+	template, err := templateDao.GetLatestPromptTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	if template != nil {
+		return toPromptTemplateData(template), nil
+	}
+	return staticPromptTemplate(name), nil
+}
+
+// findPromptTemplate 查找指定版本，版本 0 表示配置文件或内置模板，不存在时返回 nil
+func findPromptTemplate(templateDao dao.IPromptTemplateDao, name string, version int) (*PromptTemplateData, error) {
+	if version == 0 {
+		return staticPromptTemplate(name), nil
+	}
+	template, err := templateDao.GetPromptTemplate(name, version)
+	if err != nil || template == nil {
+		return nil, err
+	}
+	return toPromptTemplateData(template), nil
+}
+
+func staticPromptTemplate(name string) *PromptTemplateData {
+	for _, c := range config.GetConfig().PromptTemplates {
+		if c != nil && c.Name == name {
+			return &PromptTemplateData{
+				Name:        c.Name,
+				Content:     c.Content,
+				Description: c.Description,
+				Source:      templateSourceConfig,
+			}
+		}
+	}
+	if name == llm.DefaultTemplateName {
+		return &PromptTemplateData{
+			Name:    llm.DefaultTemplateName,
+			Content: llm.DefaultTemplateText,
+			Source:  templateSourceBuiltin,
+		}
+	}
+	return nil
+}
+
+// resolveTaskPromptTemplate 创建任务时确定模板版本，之后模板再更新也不影响该任务
+func resolveTaskPromptTemplate(templateDao dao.IPromptTemplateDao, name string) (*PromptTemplateData, error) {
+	if name == "" {
+		name = llm.DefaultTemplateName
+	}
+	data, err := latestPromptTemplate(templateDao, name)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, unify_response.ParameterError("提示词模板不存在", map[string]string{
+			"template": fmt.Sprintf("模板 %s 不存在", name),
+		})
+	}
+	return data, nil
+}
+
+// loadTaskPromptTemplate 加载并编译任务创建时选定的模板版本，旧任务未记录模板时使用内置模板
+func loadTaskPromptTemplate(templateDao dao.IPromptTemplateDao, task *models.TaskModel) (*llm.PromptTemplate, error) {
+	if task.TemplateName == "" {
+		return nil, nil
+	}
+	data, err := findPromptTemplate(templateDao, task.TemplateName, task.TemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("提示词模板 %s 版本 %d 不存在", task.TemplateName, task.TemplateVersion)
+	}
+	return llm.NewPromptTemplate(data.Name, data.Version, data.Content)
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/quality"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+	"go.uber.org/zap"
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	defaultJudgeMaxChars = 4000
+	// 综合得分中各项指标的权重，未计算的指标不参与加权
+	judgeWeight           = 0.5
+	backTranslationWeight = 0.3
+	untranslatedWeight    = 0.2
+)
+
+type qualityOptions struct {
+	backTranslation bool
+	judge           bool
+	judgeMaxChars   int
+}
+
+// getQualityOptions 返回译文质量评估配置，未启用时返回 nil
+func getQualityOptions() *qualityOptions {
+	qualityConfig := config.GetConfig().Quality
+	if qualityConfig == nil || !qualityConfig.Enabled {
+		return nil
+	}
+	opt := &qualityOptions{
+		backTranslation: !qualityConfig.DisableBackTranslation,
+		judge:           !qualityConfig.DisableJudge,
+		judgeMaxChars:   defaultJudgeMaxChars,
+	}
+	if qualityConfig.JudgeMaxChars > 0 {
+		opt.judgeMaxChars = qualityConfig.JudgeMaxChars
+	}
+	return opt
+}
+
+// evaluateQuality 任务完成后评估译文质量并保存，评估失败只记录日志，不影响任务结果
+// source 与 translation 为需要翻译的文字及其译文，不含文件格式标记
+func (t *taskService) evaluateQuality(
+	job *translateJob, segments []segmenter.Segment, outcome *segmentOutcome, source, translation string) {
+
+	opt := getQualityOptions()
+	if opt == nil {
+		return
+	}
+	taskData := job.task
+	var keep []string
+	for _, e := range job.glossary {
+		if e.DoNotTranslate {
+			keep = append(keep, e.Term)
+		}
+	}
+	data := &QualityData{
+		LengthRatio:       round(quality.LengthRatio(source, translation)),
+		UntranslatedRatio: round(quality.UntranslatedRatio(source, translation, keep)),
+	}
+	ctx := context.Background()
+	if opt.backTranslation {
+		backTranslation, err := t.backTranslate(ctx, job, segments, outcome)
+		if err != nil {
+			logger.Warn("back translation failed", zap.Uint("task_id", taskData.ID), zap.Error(err))
+		} else if backTranslation != "" {
+			score := round(quality.ChrF(backTranslation, source))
+			data.BackTranslationChrF = &score
+		}
+	}
+	if opt.judge {
+		source, target := judgeSample(segments, outcome, opt.judgeMaxChars)
+		judge, err := llm.Judge(ctx, t.llm, &llm.JudgeRequest{
+			Lang:        taskData.Lang,
+			TargetLang:  taskData.TargetLang,
+			Source:      source,
+			Translation: target,
+			Username:    taskData.CreateBy,
+		})
+		if err != nil {
+			logger.Warn("judge translation failed", zap.Uint("task_id", taskData.ID), zap.Error(err))
+		} else {
+			t.recordUsage(taskData, models.UsageOperationJudge,
+				taskData.Lang, taskData.TargetLang, source+target, judge.Result)
+			score := round(judge.Score)
+			data.JudgeScore = &score
+			data.JudgeReason = judge.Reason
+		}
+	}
+	data.Score = round(data.combine())
+	detail, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	err = t.taskDao.UpdateTaskStatus(int64(taskData.ID), map[string]any{
+		"quality_score":  data.Score,
+		"quality_detail": string(detail),
+	})
+	if err != nil {
+		logger.Error("save quality score failed", zap.Uint("task_id", taskData.ID), zap.Error(err))
+		return
+	}
+	t.notifyQuality(taskData, data)
+}
+
+// backTranslate 把译文逐段翻译回源语言，源语言未知时无法回译，返回空字符串
+func (t *taskService) backTranslate(ctx context.Context, job *translateJob,
+	segments []segmenter.Segment, outcome *segmentOutcome) (string, error) {
+
+	taskData := job.task
+	lang := taskData.Lang
+	if lang == models.AutoDetect {
+		return "", nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	back := make([]string, len(segments))
+	sem := make(chan struct{}, getSegmentOptions().concurrency)
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i := range segments {
+		if segments[i].Text == "" || outcome.translations[i] == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			result, err := t.llm.Translate(ctx, &llm.TranslateRequest{
+				Lang:       taskData.TargetLang,
+				TargetLang: lang,
+				Content:    outcome.translations[i],
+				Username:   taskData.CreateBy,
+				NoCache:    taskData.NoCache,
+			})
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			t.recordUsage(taskData, models.UsageOperationBackTranslate,
+				taskData.TargetLang, lang, outcome.translations[i], result)
+			back[i] = result.Text
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return strings.Join(back, "\n"), nil
+}
+
+// judgeSample 从头依次取分段直到原文达到 maxChars，避免长文本评估消耗过多 token
+// 至少包含一个分段
+func judgeSample(segments []segmenter.Segment, outcome *segmentOutcome, maxChars int) (string, string) {
+	var source, target []string
+	chars := 0
+	for i, seg := range segments {
+		if seg.Text == "" {
+			continue
+		}
+		length := utf8.RuneCountInString(seg.Text)
+		if len(source) > 0 && chars+length > maxChars {
+			break
+		}
+		source = append(source, seg.Text)
+		target = append(target, outcome.translations[i])
+		chars += length
+	}
+	return strings.Join(source, "\n\n"), strings.Join(target, "\n\n")
+}
+
+// combine 按权重合并各项指标，得分越低越需要人工复核
+func (q *QualityData) combine() float64 {
+	total := untranslatedWeight * (1 - q.UntranslatedRatio)
+	weights := untranslatedWeight
+	if q.BackTranslationChrF != nil {
+		total += backTranslationWeight * *q.BackTranslationChrF
+		weights += backTranslationWeight
+	}
+	if q.JudgeScore != nil {
+		total += judgeWeight * *q.JudgeScore
+		weights += judgeWeight
+	}
+	return total / weights
+}
+
+func unmarshalQuality(data string) *QualityData {
+	if data == "" {
+		return nil
+	}
+	var q QualityData
+	if err := json.Unmarshal([]byte(data), &q); err != nil {
+		return nil
+	}
+	return &q
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"fmt"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"go.uber.org/zap"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// quotaResetInterval 定时清零过期用量的检查间隔，过期用量在读取时也会按 0 处理，间隔只影响数据库中的值
+const quotaResetInterval = time.Minute
+
+type IQuotaService interface {
+	CreatePlan(req *request_mapping.CreateQuotaPlanReq) (*QuotaPlanData, error)
+	UpdatePlan(req *request_mapping.UpdateQuotaPlanReq) error
+	ListPlans() ([]*QuotaPlanData, error)
+	AssignPlan(req *request_mapping.AssignQuotaPlanReq) error
+	// GetBalance 查询配额余量，普通用户只能查询自己
+	GetBalance(username string, req *request_mapping.QuotaBalanceReq) (*QuotaBalance, error)
+	// StartResetScheduler 启动定时任务，在每日、每月周期开始后清零已用量
+	StartResetScheduler()
+}
+
+type quotaService struct {
+	quotaDao dao.IQuotaDao
+	userDao  dao.IUserDao
+}
+
+func NewQuotaService(quotaDao dao.IQuotaDao, userDao dao.IUserDao) IQuotaService {
+	return &quotaService{
+		quotaDao: quotaDao,
+		userDao:  userDao,
+	}
+}
+
+func (q *quotaService) CreatePlan(req *request_mapping.CreateQuotaPlanReq) (*QuotaPlanData, error) {
+	existed, err := q.quotaDao.GetPlanByName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existed != nil {
+		return nil, unify_response.ParameterError("套餐已存在", map[string]string{
+			"name": fmt.Sprintf("套餐 %s 已存在", req.Name),
+		})
+	}
+	plan := &models.QuotaPlanModel{
+		Name:          req.Name,
+		Description:   req.Description,
+		DailyChars:    req.DailyChars,
+		MonthlyChars:  req.MonthlyChars,
+		DailyTokens:   req.DailyTokens,
+		MonthlyTokens: req.MonthlyTokens,
+	}
+	if err = q.quotaDao.CreatePlan(plan); err != nil {
+		return nil, err
+	}
+	return toQuotaPlanData(plan), nil
+}
+
+func (q *quotaService) UpdatePlan(req *request_mapping.UpdateQuotaPlanReq) error {
+	plan, err := q.quotaDao.GetPlan(uint(req.Id))
+	if err != nil {
+		return err
+	}
+	if plan == nil {
+		return unify_response.NotFound()
+	}
+	return q.quotaDao.UpdatePlan(plan.ID, map[string]any{
+		"description":    req.Description,
+		"daily_chars":    req.DailyChars,
+		"monthly_chars":  req.MonthlyChars,
+		"daily_tokens":   req.DailyTokens,
+		"monthly_tokens": req.MonthlyTokens,
+	})
+}
+
+func (q *quotaService) ListPlans() ([]*QuotaPlanData, error) {
+	list, err := q.quotaDao.ListPlans()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*QuotaPlanData, 0, len(list))
+	for _, plan := range list {
+		items = append(items, toQuotaPlanData(plan))
+	}
+	return items, nil
+}
+
+func (q *quotaService) AssignPlan(req *request_mapping.AssignQuotaPlanReq) error {
+	if _, err := q.userDao.GetUserRole(req.Username); err != nil {
+		return err
+	}
+	if req.PlanId != 0 {
+		plan, err := q.quotaDao.GetPlan(uint(req.PlanId))
+		if err != nil {
+			return err
+		}
+		if plan == nil {
+			return unify_response.ParameterError("套餐不存在")
+		}
+	}
+	day, month := quotaPeriods(time.Now())
+	return q.quotaDao.AssignPlan(req.Username, uint(req.PlanId), day, month)
+}
+
+func (q *quotaService) GetBalance(username string, req *request_mapping.QuotaBalanceReq) (*QuotaBalance, error) {
+	target := username
+	if req.Username != "" && req.Username != username {
+		role, err := q.userDao.GetUserRole(username)
+		if err != nil {
+			return nil, err
+		}
+		if role != models.UserRoleAdmin {
+			return nil, unify_response.NewForbidden("只能查看自己的配额")
+		}
+		if _, err = q.userDao.GetUserRole(req.Username); err != nil {
+			return nil, err
+		}
+		target = req.Username
+	}
+	day, month := quotaPeriods(time.Now())
+	quota, err := loadUserQuota(q.quotaDao, target, day, month)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := userQuotaPlan(q.quotaDao, quota)
+	if err != nil {
+		return nil, err
+	}
+	balance := &QuotaBalance{
+		Username: target,
+		Enabled:  quotaEnabled(),
+		Daily: &QuotaPeriodData{
+			Start:      quota.DailyPeriod,
+			ResetAt:    day.AddDate(0, 0, 1),
+			UsedChars:  quota.DailyChars,
+			UsedTokens: quota.DailyTokens,
+		},
+		Monthly: &QuotaPeriodData{
+			Start:      quota.MonthlyPeriod,
+			ResetAt:    month.AddDate(0, 1, 0),
+			UsedChars:  quota.MonthlyChars,
+			UsedTokens: quota.MonthlyTokens,
+		},
+	}
+	if plan != nil {
+		balance.Plan = toQuotaPlanData(plan)
+		balance.Daily.setLimits(plan.DailyChars, plan.DailyTokens)
+		balance.Monthly.setLimits(plan.MonthlyChars, plan.MonthlyTokens)
+	}
+	return balance, nil
+}
+
+func (q *quotaService) StartResetScheduler() {
+	go func() {
+		ticker := time.NewTicker(quotaResetInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			day, month := quotaPeriods(now)
+			if err := q.quotaDao.ResetExpiredQuotas(day, month); err != nil {
+				logger.Error("reset expired quotas failed", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// setLimits 上限为 0 时不限制，剩余量为 nil
+func (p *QuotaPeriodData) setLimits(chars, tokens int64) {
+	p.CharsLimit, p.TokensLimit = chars, tokens
+	if chars > 0 {
+		remaining := max(chars-p.UsedChars, 0)
+		p.RemainingChars = &remaining
+	}
+	if tokens > 0 {
+		remaining := max(tokens-p.UsedTokens, 0)
+		p.RemainingTokens = &remaining
+	}
+}
+
+func toQuotaPlanData(plan *models.QuotaPlanModel) *QuotaPlanData {
+	return &QuotaPlanData{
+		Id:            int(plan.ID),
+		Name:          plan.Name,
+		Description:   plan.Description,
+		DailyChars:    plan.DailyChars,
+		MonthlyChars:  plan.MonthlyChars,
+		DailyTokens:   plan.DailyTokens,
+		MonthlyTokens: plan.MonthlyTokens,
+	}
+}
+
+// quotaNeed 一次执行预计消耗的配额
+type quotaNeed struct {
+	chars  int64
+	tokens int64
+}
+
+// estimateQuotaNeed 字符数按原文计算，token 数按原文与译文各一份粗略估算，提示词本身的开销不计入
+func estimateQuotaNeed(content string, langs int) quotaNeed {
+	return quotaNeed{
+		chars:  int64(utf8.RuneCountInString(content) * langs),
+		tokens: int64(segmenter.EstimateTokens(content) * 2 * langs),
+	}
+}
+
+// share 按比例折算字符数，用于部分退还
+func (n quotaNeed) share(ratio float64) quotaNeed {
+	ratio = min(max(ratio, 0), 1)
+	return quotaNeed{
+		chars:  int64(math.Round(float64(n.chars) * ratio)),
+		tokens: int64(math.Round(float64(n.tokens) * ratio)),
+	}
+}
+
+func quotaEnabled() bool {
+	quotaConfig := config.GetConfig().Quota
+	return quotaConfig == nil || !quotaConfig.Disabled
+}
+
+// quotaPeriods 返回 now 所在的日、月周期起始时间，按服务器本地时区计算
+func quotaPeriods(now time.Time) (time.Time, time.Time) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return day, month
+}
+
+// loadUserQuota 从未使用过的用户返回当前周期的空记录
+func loadUserQuota(quotaDao dao.IQuotaDao, username string, day, month time.Time) (*models.UserQuotaModel, error) {
+	quota, err := quotaDao.GetUserQuota(username, day, month)
+	if err != nil {
+		return nil, err
+	}
+	if quota == nil {
+		quota = &models.UserQuotaModel{Username: username, DailyPeriod: day, MonthlyPeriod: month}
+	}
+	return quota, nil
+}
+
+// userQuotaPlan 用户当前生效的套餐，未分配套餐时使用默认套餐，返回 nil 表示不限制
+func userQuotaPlan(quotaDao dao.IQuotaDao, quota *models.UserQuotaModel) (*models.QuotaPlanModel, error) {
+	if quota.PlanId != 0 {
+		return quotaDao.GetPlan(quota.PlanId)
+	}
+	quotaConfig := config.GetConfig().Quota
+	if quotaConfig == nil || quotaConfig.DefaultPlan == "" {
+		return nil, nil
+	}
+	plan, err := quotaDao.GetPlanByName(quotaConfig.DefaultPlan)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		logger.Warn("default quota plan not found", zap.String("plan", quotaConfig.DefaultPlan))
+	}
+	return plan, nil
+}
+
+// checkQuota 创建任务时检查余量，不扣减
+func checkQuota(quotaDao dao.IQuotaDao, username string, need quotaNeed) error {
+	if !quotaEnabled() {
+		return nil
+	}
+	day, month := quotaPeriods(time.Now())
+	quota, err := loadUserQuota(quotaDao, username, day, month)
+	if err != nil {
+		return err
+	}
+	return checkQuotaBalance(quotaDao, quota, need)
+}
+
+// consumeQuota 执行任务时检查余量并扣减字符数，token 数在每次模型调用后按实际用量扣减
+func consumeQuota(quotaDao dao.IQuotaDao, username string, need quotaNeed) error {
+	if !quotaEnabled() {
+		return nil
+	}
+	day, month := quotaPeriods(time.Now())
+	return quotaDao.ConsumeQuota(username, need.chars, 0, day, month, func(quota *models.UserQuotaModel) error {
+		return checkQuotaBalance(quotaDao, quota, need)
+	})
+}
+
+// refundQuota 扣减后任务未能执行时退还字符数
+func refundQuota(quotaDao dao.IQuotaDao, username string, need quotaNeed) {
+	if !quotaEnabled() || need.chars <= 0 {
+		return
+	}
+	day, month := quotaPeriods(time.Now())
+	if err := quotaDao.ConsumeQuota(username, -need.chars, 0, day, month, nil); err != nil {
+		logger.Warn("refund quota failed", zap.String("username", username), zap.Error(err))
+	}
+}
+
+// addQuotaTokens 记录模型调用实际消耗的 token，调用已经发生，不做检查
+func addQuotaTokens(quotaDao dao.IQuotaDao, username string, tokens int) {
+	if !quotaEnabled() || tokens <= 0 {
+		return
+	}
+	day, month := quotaPeriods(time.Now())
+	if err := quotaDao.ConsumeQuota(username, 0, int64(tokens), day, month, nil); err != nil {
+		logger.Warn("add quota tokens failed", zap.String("username", username), zap.Error(err))
+	}
+}
+
+func checkQuotaBalance(quotaDao dao.IQuotaDao, quota *models.UserQuotaModel, need quotaNeed) error {
+	plan, err := userQuotaPlan(quotaDao, quota)
+	if err != nil || plan == nil {
+		return err
+	}
+	checks := []struct {
+		name        string
+		limit, used int64
+		need        int64
+	}{
+		{"今日字符", plan.DailyChars, quota.DailyChars, need.chars},
+		{"本月字符", plan.MonthlyChars, quota.MonthlyChars, need.chars},
+		{"今日 token", plan.DailyTokens, quota.DailyTokens, need.tokens},
+		{"本月 token", plan.MonthlyTokens, quota.MonthlyTokens, need.tokens},
+	}
+	for _, c := range checks {
+		if c.limit > 0 && c.used+c.need > c.limit {
+			return unify_response.QuotaExceeded(fmt.Sprintf(
+				"%s配额不足，剩余 %d，本次需要 %d", c.name, max(c.limit-c.used, 0), c.need))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/glossary"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/placeholder"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	defaultChunkConcurrency = 4
+	defaultChunkMaxRetries  = 2
+)
+
+type segmentOptions struct {
+	split       segmenter.Options
+	concurrency int
+	maxRetries  int
+}
+
+func getSegmentOptions() segmentOptions {
+	opt := segmentOptions{
+		split: segmenter.Options{
+			MaxTokens:     segmenter.DefaultMaxTokens,
+			OverlapTokens: segmenter.DefaultOverlapTokens,
+		},
+		concurrency: defaultChunkConcurrency,
+		maxRetries:  defaultChunkMaxRetries,
+	}
+	chunkConfig := config.GetConfig().Chunk
+	if chunkConfig == nil {
+		return opt
+	}
+	if chunkConfig.MaxTokens > 0 {
+		opt.split.MaxTokens = chunkConfig.MaxTokens
+	}
+	if chunkConfig.OverlapTokens != nil {
+		opt.split.OverlapTokens = max(*chunkConfig.OverlapTokens, 0)
+	}
+	if chunkConfig.Concurrency > 0 {
+		opt.concurrency = chunkConfig.Concurrency
+	}
+	if chunkConfig.MaxRetries != nil {
+		opt.maxRetries = max(*chunkConfig.MaxRetries, 0)
+	}
+	return opt
+}
+
+// translateJob 一次任务执行所需的上下文，在执行前准备好，各分段共享
+type translateJob struct {
+	task     *models.TaskModel
+	glossary []glossary.Entry
+	// memory 为 nil 表示未启用翻译记忆
+	memory *translationMemory
+	// template 为 nil 时使用内置模板
+	template *llm.PromptTemplate
+	style    llm.Style
+	// examples 引用任务的原文与译文，contextTaskIds 为实际使用的任务
+	examples       []llm.Example
+	contextTaskIds []int64
+	// validate 检查分段译文是否满足文档的结构要求，为 nil 表示不检查
+	validate func(segment int, translation string) error
+}
+
+// glossaryTerms 返回在分段中出现的术语
+func (j *translateJob) glossaryTerms(text string) []llm.GlossaryTerm {
+	matched := glossary.Match(j.glossary, text)
+	terms := make([]llm.GlossaryTerm, 0, len(matched))
+	for _, e := range matched {
+		terms = append(terms, llm.GlossaryTerm{
+			Term:           e.Term,
+			Translation:    e.Translation,
+			DoNotTranslate: e.DoNotTranslate,
+		})
+	}
+	return terms
+}
+
+// segmentOutcome 一个任务所有分段的翻译结果，results 与 segments 一一对应
+type segmentOutcome struct {
+	translations []string
+	results      []*llm.TranslateResult
+	attempts     []llm.Attempt
+	// fromMemory 对应分段是否直接复用了翻译记忆
+	fromMemory []bool
+	// issues 对应分段译文中与原文不一致的占位符
+	issues [][]placeholder.Issue
+}
+
+// cacheHits 译文来自缓存的分段数
+func (o *segmentOutcome) cacheHits() int {
+	hits := 0
+	for _, r := range o.results {
+		if r != nil && r.Cached {
+			hits++
+		}
+	}
+	return hits
+}
+
+// memoryHits 直接复用翻译记忆的分段数
+func (o *segmentOutcome) memoryHits() int {
+	hits := 0
+	for _, hit := range o.fromMemory {
+		if hit {
+			hits++
+		}
+	}
+	return hits
+}
+
+// providers 按首次出现的顺序返回产出结果的服务商
+func (o *segmentOutcome) providers() string {
+	var names []string
+	for _, r := range o.results {
+		if r != nil && r.Provider != "" && !contains(names, r.Provider) {
+			names = append(names, r.Provider)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// untranslatedShare 没有得到译文的分段在原文中的字符占比
+func (o *segmentOutcome) untranslatedShare(segments []segmenter.Segment) float64 {
+	total, pending := 0, 0
+	for i, seg := range segments {
+		chars := utf8.RuneCountInString(seg.Text)
+		total += chars
+		if o.results[i] == nil && !o.fromMemory[i] {
+			pending += chars
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(pending) / float64(total)
+}
+
+// translateSegments 用有界的协程池并行翻译各分段，任意一段在重试后仍失败时取消其余分段
+func (t *taskService) translateSegments(
+	ctx context.Context, job *translateJob,
+	segments []segmenter.Segment, opt segmentOptions) (*segmentOutcome, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outcome := &segmentOutcome{
+		translations: make([]string, len(segments)),
+		results:      make([]*llm.TranslateResult, len(segments)),
+		fromMemory:   make([]bool, len(segments)),
+		issues:       make([][]placeholder.Issue, len(segments)),
+	}
+	attempts := make([][]llm.Attempt, len(segments))
+	sem := make(chan struct{}, opt.concurrency)
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i := range segments {
+		if segments[i].Text == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			if job.memory != nil {
+				if target, ok := job.memory.exact(segments[i].Text); ok {
+					outcome.translations[i] = target
+					outcome.fromMemory[i] = true
+					t.notifyDelta(job.task, segments[i].Index, target)
+					return
+				}
+			}
+			result, issues, tried, err := t.translateSegment(ctx, job, &segments[i], opt.maxRetries)
+			attempts[i] = tried
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("第 %d/%d 段翻译失败: %w", i+1, len(segments), err)
+					cancel()
+				})
+				return
+			}
+			outcome.results[i] = result
+			outcome.translations[i] = result.Text
+			outcome.issues[i] = issues
+		}(i)
+	}
+	wg.Wait()
+
+	for _, tried := range attempts {
+		outcome.attempts = append(outcome.attempts, tried...)
+	}
+	if firstErr != nil {
+		return outcome, firstErr
+	}
+	return outcome, nil
+}
+
+// translateSegment 翻译单个分段，分段中的占位符替换为标记后再提交给模型，返回的译文已还原，issues 为不一致的占位符
+// 只有译文不符合要求(严格模式下占位符不一致、不满足文档结构要求)时重新生成该分段，
+// 限流、服务端故障等调用错误由各服务商的 resilientClient 退避重试，这里直接返回，不再叠加重试
+func (t *taskService) translateSegment(
+	ctx context.Context, job *translateJob, segment *segmenter.Segment,
+	maxRetries int) (*llm.TranslateResult, []placeholder.Issue, []llm.Attempt, error) {
+
+	taskData := job.task
+	req := &llm.TranslateRequest{
+		Lang:       taskData.Lang,
+		TargetLang: taskData.TargetLang,
+		Content:    segment.Text,
+		Username:   taskData.CreateBy,
+		Context:    segment.Context,
+		Glossary:   job.glossaryTerms(segment.Text),
+		Template:   job.template,
+		Style:      job.style,
+		NoCache:    taskData.NoCache,
+		Examples:   job.examples,
+	}
+	if job.memory != nil {
+		req.References = job.memory.fuzzy(segment.Text)
+	}
+	masked := maskPlaceholders(taskData, segment.Text)
+	if masked != nil {
+		req.Content = masked.Text
+	}
+	var (
+		tried   []llm.Attempt
+		lastErr error
+	)
+	streamClient, stream := t.llm.(llm.IStreamLLMClient)
+	for i := 0; i <= maxRetries; i++ {
+		var (
+			result *llm.TranslateResult
+			err    error
+		)
+		if stream {
+			emitted := false
+			restorer := masked.StreamRestorer()
+			result, err = streamClient.TranslateStream(ctx, req, func(event llm.StreamEvent) {
+				if event.Reset {
+					restorer = masked.StreamRestorer()
+					t.notifyReset(taskData, segment.Index)
+					return
+				}
+				if delta := restorer.Restore(event.Delta); delta != "" {
+					emitted = true
+					t.notifyDelta(taskData, segment.Index, delta)
+				}
+			})
+			if err == nil {
+				// 流结束时输出缓冲中剩余的内容
+				if delta := restorer.Flush(); delta != "" {
+					t.notifyDelta(taskData, segment.Index, delta)
+				}
+			} else if emitted {
+				// 本次失败的增量已推送出去，重试前通知客户端丢弃
+				t.notifyReset(taskData, segment.Index)
+			}
+		} else {
+			result, err = t.llm.Translate(ctx, req)
+		}
+		if err != nil {
+			tried = append(tried, providerChain(err)...)
+			return nil, nil, tried, err
+		}
+		t.recordUsage(taskData, models.UsageOperationTranslate,
+			taskData.Lang, taskData.TargetLang, segment.Text, result)
+		tried = append(tried, result.Attempts...)
+		var issues []placeholder.Issue
+		if masked != nil {
+			result.Text, issues = masked.Restore(result.Text)
+		}
+		if len(issues) > 0 && taskData.PlaceholderMode == placeholder.ModeStrict {
+			err = &placeholderError{issues: issues}
+		} else if job.validate != nil {
+			err = job.validate(segment.Index, result.Text)
+		}
+		if err == nil {
+			return result, issues, tried, nil
+		}
+		// 缓存中的译文同样不符合要求，重试时必须重新生成
+		req.NoCache = true
+		if stream {
+			t.notifyReset(taskData, segment.Index)
+		}
+		lastErr = err
+		logger.Warn("segment translation rejected",
+			zap.Uint("task_id", taskData.ID),
+			zap.Int("segment", segment.Index),
+			zap.Int("retry", i),
+			zap.Error(err))
+	}
+	return nil, nil, tried, lastErr
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+)
+
+func TestGetSegmentOptions(t *testing.T) {
+	cases := []struct {
+		name  string
+		chunk string
+		want  segmentOptions
+	}{
+		{
+			name: "defaults",
+			want: segmentOptions{
+				split:       segmenter.Options{MaxTokens: segmenter.DefaultMaxTokens, OverlapTokens: segmenter.DefaultOverlapTokens},
+				concurrency: defaultChunkConcurrency,
+				maxRetries:  defaultChunkMaxRetries,
+			},
+		},
+		{
+			// 显式配置的 0 关闭重试和上下文，其余字段为 0 时仍用默认值
+			name:  "explicit zero",
+			chunk: "chunk:\n  max_tokens: 0\n  overlap_tokens: 0\n  concurrency: 0\n  max_retries: 0\n",
+			want: segmentOptions{
+				split:       segmenter.Options{MaxTokens: segmenter.DefaultMaxTokens},
+				concurrency: defaultChunkConcurrency,
+			},
+		},
+		{
+			name:  "configured",
+			chunk: "chunk:\n  max_tokens: 800\n  overlap_tokens: 50\n  concurrency: 2\n  max_retries: 5\n",
+			want: segmentOptions{
+				split:       segmenter.Options{MaxTokens: 800, OverlapTokens: 50},
+				concurrency: 2,
+				maxRetries:  5,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			confPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(confPath, []byte("task_result_dir: "+t.TempDir()+"\n"+c.chunk), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := config.ParseConfig(confPath); err != nil {
+				t.Fatal(err)
+			}
+			if got := getSegmentOptions(); got != c.want {
+				t.Errorf("getSegmentOptions() = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
@@ -1,38 +1,71 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
 	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
 	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
 	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/glossary"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/language"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"go.uber.org/zap"
 	"io/ioutil"
-	"os"
 	"path"
+	"strings"
+	"sync"
 )
 
 type ITaskService interface {
 	GetTaskDetail(username string, taskId int64) (*TaskData, error)
+	// ListTasks 任务列表，不包含原文与译文，可按质量得分排序以便优先复核
+	ListTasks(username string, req *request_mapping.ListTaskReq) ([]*TaskData, int64, error)
 	ExecuteTask(username string, taskId int64) error
-	CreateTask(username string, req *request_mapping.CreateTaskReq) error
-	GetTaskIsDoneAndFilePath(id int64, username string) (bool, string, error)
+	CreateTask(username string, req *request_mapping.CreateTaskReq) (*TaskData, error)
+	// UploadTask 以上传的文件创建任务，译文保持相同格式
+	UploadTask(username string, req *request_mapping.UploadTaskReq) (*TaskData, error)
+	// GetTaskResultFiles 返回可下载的译文文件，archive 为 true 时需要把多个语言打包下载，
+	// format 不为空时把纯文本任务导出为该格式的双语文件
+	GetTaskResultFiles(username string, taskId int64, lang string, format string) (files []*ResultFile, archive bool, err error)
+	// StartWorkers 启动执行任务的协程
+	StartWorkers()
 }
 
 type taskService struct {
 	taskDao       dao.ITaskDao
+	glossaryDao   dao.IGlossaryDao
+	memoryDao     dao.ITranslationMemoryDao
+	templateDao   dao.IPromptTemplateDao
+	usageDao      dao.IUsageRecordDao
+	quotaDao      dao.IQuotaDao
 	llm           llm.ILLMClient
 	notifyChannel chan map[string]any
+	// queue 等待执行的任务，由 StartWorkers 启动的协程消费
+	queue chan *models.TaskModel
+	// streamSeq 各执行中任务的增量消息序号，任务 id 对应 *atomic.Int64
+	streamSeq sync.Map
 }
 
 func NewTaskService(
-	taskDao dao.ITaskDao, client llm.ILLMClient, notifyChannel chan map[string]any) ITaskService {
+	taskDao dao.ITaskDao, glossaryDao dao.IGlossaryDao, memoryDao dao.ITranslationMemoryDao,
+	templateDao dao.IPromptTemplateDao, usageDao dao.IUsageRecordDao, quotaDao dao.IQuotaDao,
+	client llm.ILLMClient, notifyChannel chan map[string]any) ITaskService {
 	return &taskService{
 		taskDao:       taskDao,
+		glossaryDao:   glossaryDao,
+		memoryDao:     memoryDao,
+		templateDao:   templateDao,
+		usageDao:      usageDao,
+		quotaDao:      quotaDao,
 		llm:           client,
 		notifyChannel: notifyChannel,
+		queue:         make(chan *models.TaskModel, getTaskWorkerOptions().queueSize),
 	}
 }
 
@@ -43,99 +76,402 @@ func (t *taskService) GetTaskDetail(
 	if err != nil {
 		return nil, err
 	}
-	item := &TaskData{
-		Id:         int(data.ID),
-		Status:     data.Status,
-		CreateBy:   data.CreateBy,
-		Content:    data.Content,
-		Lang:       data.Lang,
-		TargetLang: data.TargetLang,
-	}
-
-	if data.Status == 2 && data.IsOss != 1 {
-		filename := data.ResultKey
-		_, err := os.Stat(path.Join(filename))
-		if err == nil {
-			// 读取文件内容
-			fileData, err := ioutil.ReadFile(filename)
-			if err == nil {
-				data.Content = string(fileData)
-			}
-		}
+	item := toTaskData(data)
+	item.Content = data.Content
+	item.Result = readTaskResult(data)
+	if !isParentTask(data) {
+		return item, nil
+	}
+	children, err := t.taskDao.ListChildTasks(taskId)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		childItem := toTaskData(child)
+		childItem.Result = readTaskResult(child)
+		item.Children = append(item.Children, childItem)
 	}
 	return item, nil
 }
+
+func (t *taskService) ListTasks(
+	username string, req *request_mapping.ListTaskReq) ([]*TaskData, int64, error) {
+	list, count, err := t.taskDao.ListTasks(username, req.Status, req.Sort, req.Page, req.Size)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]*TaskData, 0, len(list))
+	for _, task := range list {
+		items = append(items, toTaskData(task))
+	}
+	return items, count, nil
+}
+
+// toTaskData 转换为接口返回的数据，不包含原文和译文
+func toTaskData(data *models.TaskModel) *TaskData {
+	item := &TaskData{
+		Id:                 int(data.ID),
+		Status:             data.Status,
+		CreateBy:           data.CreateBy,
+		Lang:               data.Lang,
+		TargetLang:         data.TargetLang,
+		Provider:           data.Provider,
+		ProviderChain:      unmarshalAttempts(data.ProviderChain),
+		ErrorMsg:           data.ErrorMsg,
+		SegmentCount:       data.SegmentCount,
+		MemorySegments:     data.MemorySegments,
+		DetectedLang:       data.DetectedLang,
+		DetectConfidence:   data.DetectConfidence,
+		ParentId:           int(data.ParentId),
+		TargetLangs:        splitTargetLangs(data.TargetLangs),
+		TemplateName:       data.TemplateName,
+		TemplateVersion:    data.TemplateVersion,
+		Tone:               data.Tone,
+		Formality:          data.Formality,
+		Domain:             data.Domain,
+		NoCache:            data.NoCache,
+		CacheHits:          data.CacheHits,
+		PlaceholderMode:    data.PlaceholderMode,
+		ContextTaskIds:     splitTaskIds(data.ContextTaskIds),
+		Format:             data.Format,
+		OriginalFilename:   data.OriginalFilename,
+		MaxLineChars:       data.MaxLineChars,
+		TranslateAll:       data.TranslateAll,
+		UsedContextTaskIds: splitTaskIds(data.UsedContextTaskIds),
+		Quality:            unmarshalQuality(data.QualityDetail),
+	}
+	if data.GlossaryViolations != "" {
+		_ = json.Unmarshal([]byte(data.GlossaryViolations), &item.GlossaryViolations)
+	}
+	if data.PlaceholderIssues != "" {
+		_ = json.Unmarshal([]byte(data.PlaceholderIssues), &item.PlaceholderIssues)
+	}
+	return item
+}
+
+// readTaskResult 读取已完成任务的译文，只在任务详情中使用，列表不读取结果文件
+func readTaskResult(data *models.TaskModel) string {
+	if data.Status != models.TaskStatusDone || data.IsOss == 1 {
+		return ""
+	}
+	fileData, err := ioutil.ReadFile(data.ResultKey)
+	if err != nil {
+		return ""
+	}
+	return string(fileData)
+}
+
 func (t *taskService) ExecuteTask(username string, taskId int64) error {
 	taskData, err := t.taskDao.GetTaskByIdAndUsername(username, taskId)
 	if err != nil {
 		return err
 	}
-	err = t.taskDao.UpdateTaskStatus(taskId, map[string]any{"status": 2})
-	if err != nil {
+	if isParentTask(taskData) {
+		pending, err := t.pendingChildren(taskData)
+		if err != nil {
+			return err
+		}
+		text := taskText(taskData)
+		if err = consumeQuota(t.quotaDao, username, estimateQuotaNeed(text, len(pending))); err != nil {
+			return err
+		}
+		started, err := t.executeChildren(taskData, pending)
+		if err != nil {
+			// 只退还未进入队列的子任务
+			refundQuota(t.quotaDao, username, estimateQuotaNeed(text, len(pending)-started))
+			return err
+		}
+		return nil
+	}
+	if taskData.Status == models.TaskStatusRunning {
+		return unify_response.ParameterError("任务正在执行中")
+	}
+	// 先扣减再执行，并发执行同一用户的多个任务时不会超出配额；未能进入队列时退还
+	need := estimateQuotaNeed(taskText(taskData), 1)
+	if err = consumeQuota(t.quotaDao, username, need); err != nil {
 		return err
 	}
-	err = t.execute(taskData)
-	if err != nil {
+	if err = t.executeTask(taskData); err != nil {
+		refundQuota(t.quotaDao, username, need)
 		return err
 	}
 	return nil
 }
 
-func (t *taskService) CreateTask(username string, req *request_mapping.CreateTaskReq) error {
-	err := t.taskDao.CreateTask(username, req.Content, req.TargetLang, req.Lang)
+func (t *taskService) executeTask(taskData *models.TaskModel) error {
+	// 重新执行时清除上一次的质量评估，并发执行同一任务时只有一次能够开始
+	started, err := t.taskDao.StartTask(int64(taskData.ID), map[string]any{
+		"quality_score":  nil,
+		"quality_detail": "",
+	})
+	if err != nil {
+		return err
+	}
+	if !started {
+		return unify_response.ParameterError("任务正在执行中")
+	}
+	err = t.enqueue(taskData)
 	if err != nil {
+		// 未能进入队列，恢复执行前的状态以便稍后重试
+		if restoreErr := t.taskDao.UpdateTaskStatus(int64(taskData.ID), map[string]any{
+			"status": taskData.Status,
+		}); restoreErr != nil {
+			logger.Error(fmt.Sprintf("failed to update task status: %s", restoreErr.Error()))
+		}
 		return err
 	}
 	return nil
 }
 
-func (t *taskService) execute(taskData *models.TaskModel) error {
-	go func(taskData *models.TaskModel, client llm.ILLMClient) {
-		panicErr := recover()
-		if panicErr != nil {
-			fmt.Println(panicErr)
-		}
-		translate, err := client.Translate(
-			taskData.Content, taskData.Lang, taskData.TargetLang)
-		if err != nil {
-			logger.Error("send message to llm error")
-		}
-		filename, err := t.generateRandomFilename()
-		if err != nil {
-			logger.Error("生成文件名失败")
-			return
-		}
-		// 构造完整路径
-		filePath := fmt.Sprintf("%s.txt", path.Join(config.GetConfig().TaskResultDir, filename))
-		// 将内容写入文件
-		err = ioutil.WriteFile(filePath, []byte(translate), 0644)
-		if err != nil {
-			t.taskDao.UpdateTaskStatus(
-				int64(taskData.ID), map[string]any{
-					"status":   3,
-					"task_key": filePath,
-				})
-			logger.Error(fmt.Sprintf("failed to write to file: %s", err.Error()))
-			return
+func (t *taskService) CreateTask(username string, req *request_mapping.CreateTaskReq) (*TaskData, error) {
+	task := newTask(username, req)
+	return t.createTask(task, req, req.Content)
+}
+
+func (t *taskService) UploadTask(username string, req *request_mapping.UploadTaskReq) (*TaskData, error) {
+	task := newTask(username, &req.CreateTaskReq)
+	task.Format = req.Format
+	task.OriginalFilename = req.Filename
+	task.MaxLineChars = req.MaxLineChars
+	task.TranslateAll = req.TranslateAll
+	_, doc, err := parseTaskDocument(task)
+	if err != nil {
+		return nil, unify_response.ParameterError("文件内容与格式不符")
+	}
+	return t.createTask(task, &req.CreateTaskReq, documentText(doc))
+}
+
+func newTask(username string, req *request_mapping.CreateTaskReq) *models.TaskModel {
+	return &models.TaskModel{
+		CreateBy:        username,
+		Content:         req.Content,
+		Lang:            req.Lang,
+		TargetLang:      req.TargetLang,
+		Tone:            req.Tone,
+		Formality:       req.Formality,
+		Domain:          req.Domain,
+		NoCache:         req.NoCache,
+		PlaceholderMode: req.PlaceholderMode,
+		ContextTaskIds:  joinTaskIds(req.ContextTaskIds),
+	}
+}
+
+// createTask text 为需要翻译的文字，用于识别源语言与预估配额，文件任务中不含格式标记
+func (t *taskService) createTask(task *models.TaskModel, req *request_mapping.CreateTaskReq, text string) (*TaskData, error) {
+	username := task.CreateBy
+	tmpl, err := resolveTaskPromptTemplate(t.templateDao, req.Template)
+	if err != nil {
+		return nil, err
+	}
+	task.TemplateName, task.TemplateVersion = tmpl.Name, tmpl.Version
+	if len(req.ContextTaskIds) > 0 {
+		if err = checkContextTasks(t.taskDao, username, req.ContextTaskIds); err != nil {
+			return nil, err
 		}
-		err = t.taskDao.UpdateTaskStatus(
-			int64(taskData.ID), map[string]any{
-				"status":   2,
-				"task_key": filePath,
-			})
-		if err != nil {
-			logger.Error(fmt.Sprintf("failed to update task status: %s", err.Error()))
-			return
+	}
+	err = checkQuota(t.quotaDao, username, estimateQuotaNeed(text, max(len(req.TargetLangs), 1)))
+	if err != nil {
+		return nil, err
+	}
+	detectSourceLang(task, text)
+	if len(req.TargetLangs) > 0 {
+		return t.createParentTask(task, req.TargetLangs)
+	}
+	if language.Same(task.Lang, task.TargetLang) {
+		return nil, unify_response.ParameterError("源语言与目标语言相同", map[string]string{
+			"target_lang": fmt.Sprintf("源语言已是 %s，无需翻译", task.Lang),
+		})
+	}
+	err = t.taskDao.CreateTask(task)
+	if err != nil {
+		return nil, err
+	}
+	return toTaskData(task), nil
+}
+
+// run 在工作协程中执行任务
+func (t *taskService) run(taskData *models.TaskModel) {
+	defer t.refreshParentStatus(taskData)
+	defer t.streamSeq.Delete(taskData.ID)
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			logger.Error("execute task panic", zap.Any("err", panicErr))
+			// 无法确定执行进度，全部退还
+			t.failTask(taskData, fmt.Sprint(panicErr), nil, 1)
 		}
-		t.notifyChannel <- map[string]any{
-			"task_id":   int64(taskData.ID),
-			"file_path": filePath,
-			"status":    2,
+	}()
+	job, err := t.prepareJob(taskData)
+	if err != nil {
+		t.failTask(taskData, err.Error(), nil, 1)
+		return
+	}
+	handler, doc, err := parseTaskDocument(taskData)
+	if err != nil {
+		t.failTask(taskData, err.Error(), nil, 1)
+		return
+	}
+	opt := getSegmentOptions()
+	segments, spans := splitUnits(doc.Units(), opt.split)
+	job.validate = unitValidator(doc, spans)
+	t.notifyStatus(taskData, models.TaskStatusRunning, map[string]any{
+		"segment_count": len(segments),
+	})
+	outcome, err := t.translateSegments(context.Background(), job, segments, opt)
+	if err != nil {
+		logger.Error("send message to llm error", zap.Error(err))
+		t.failTask(taskData, err.Error(), outcome.attempts, outcome.untranslatedShare(segments))
+		return
+	}
+	unitTranslations := joinUnits(segments, spans, outcome.translations)
+	output, err := doc.Render(unitTranslations, taskData.TargetLang)
+	if err != nil {
+		t.failTask(taskData, err.Error(), outcome.attempts, 0)
+		return
+	}
+	// 术语与质量检查只比较需要翻译的文字，双语文件生成的文件中仍含有全部原文
+	source, translation := documentText(doc), strings.Join(unitTranslations, "\n")
+	violations := glossary.Verify(glossary.Match(job.glossary, source), translation)
+	placeholderIssues := outcome.placeholderIssues()
+	filename, err := t.generateRandomFilename()
+	if err != nil {
+		logger.Error("生成文件名失败")
+		t.failTask(taskData, err.Error(), outcome.attempts, 0)
+		return
+	}
+	// 构造完整路径
+	filePath := path.Join(config.GetConfig().TaskResultDir, filename) + resultExtension(taskData, handler)
+	// 将内容写入文件
+	err = ioutil.WriteFile(filePath, output, 0644)
+	if err != nil {
+		t.failTask(taskData, err.Error(), outcome.attempts, 0)
+		logger.Error(fmt.Sprintf("failed to write to file: %s", err.Error()))
+		return
+	}
+	err = t.taskDao.UpdateTaskStatus(
+		int64(taskData.ID), map[string]any{
+			"status":                models.TaskStatusDone,
+			"result_key":            filePath,
+			"provider":              outcome.providers(),
+			"provider_chain":        marshalAttempts(outcome.attempts),
+			"segment_count":         len(segments),
+			"glossary_violations":   marshalViolations(violations),
+			"cache_hits":            outcome.cacheHits(),
+			"memory_segments":       outcome.memoryHits(),
+			"placeholder_issues":    marshalPlaceholderIssues(placeholderIssues),
+			"used_context_task_ids": joinTaskIds(job.contextTaskIds),
+		})
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to update task status: %s", err.Error()))
+		return
+	}
+	if job.memory != nil {
+		job.memory.save(segments, outcome)
+	}
+	t.notifyStatus(taskData, models.TaskStatusDone, map[string]any{
+		"file_path":           filePath,
+		"glossary_violations": len(violations),
+		"cache_hits":          outcome.cacheHits(),
+		"placeholder_issues":  len(placeholderIssues),
+	})
+	t.evaluateQuality(job, segments, outcome, source, translation)
+}
+
+// prepareJob 准备任务执行需要的术语等上下文
+func (t *taskService) prepareJob(taskData *models.TaskModel) (*translateJob, error) {
+	entries, err := loadGlossary(t.glossaryDao, taskData)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := loadTaskPromptTemplate(t.templateDao, taskData)
+	if err != nil {
+		return nil, err
+	}
+	job := &translateJob{
+		task:     taskData,
+		glossary: entries,
+		template: tmpl,
+		style: llm.Style{
+			Tone:      taskData.Tone,
+			Formality: taskData.Formality,
+			Domain:    taskData.Domain,
+		},
+	}
+	job.examples, job.contextTaskIds, err = loadContextExamples(t.taskDao, taskData)
+	if err != nil {
+		return nil, err
+	}
+	// 记忆按源语言保存和查找，源语言未确定时不使用
+	if opt := getMemoryOptions(); opt != nil && taskData.Lang != models.AutoDetect {
+		job.memory = &translationMemory{
+			dao:  t.memoryDao,
+			opt:  opt,
+			task: taskData,
 		}
-	}(taskData, t.llm)
+	}
+	return job, nil
+}
+
+// failTask 标记任务失败，并按 untranslated(未得到译文部分的占比)退还执行时扣减的字符数，
+// 已经翻译的部分即使最终未能生成结果也不退还，token 按实际调用扣减，不退还
+func (t *taskService) failTask(
+	taskData *models.TaskModel, errMsg string, attempts []llm.Attempt, untranslated float64) {
+	refundQuota(t.quotaDao, taskData.CreateBy, estimateQuotaNeed(taskText(taskData), 1).share(untranslated))
+	err := t.taskDao.UpdateTaskStatus(
+		int64(taskData.ID), map[string]any{
+			"status":         models.TaskStatusFailed,
+			"error_msg":      errMsg,
+			"provider_chain": marshalAttempts(attempts),
+		})
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to update task status: %s", err.Error()))
+	}
+	t.notifyStatus(taskData, models.TaskStatusFailed, map[string]any{
+		"error_msg": errMsg,
+	})
+}
+
+// providerChain 从路由错误中取出已尝试过的服务商
+func providerChain(err error) []llm.Attempt {
+	var failedErr *llm.AllProvidersFailedError
+	if errors.As(err, &failedErr) {
+		return failedErr.Attempts
+	}
 	return nil
 }
 
+func marshalAttempts(attempts []llm.Attempt) string {
+	if len(attempts) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(attempts)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func marshalViolations(violations []glossary.Violation) string {
+	if len(violations) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(violations)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func unmarshalAttempts(data string) []llm.Attempt {
+	if data == "" {
+		return nil
+	}
+	var attempts []llm.Attempt
+	if err := json.Unmarshal([]byte(data), &attempts); err != nil {
+		return nil
+	}
+	return attempts
+}
+
 // generateRandomFilename 生成一个随机的文件名
 func (t *taskService) generateRandomFilename() (string, error) {
 	b := make([]byte, 8)
@@ -144,14 +480,3 @@ func (t *taskService) generateRandomFilename() (string, error) {
 	}
 	return fmt.Sprintf("%x", b), nil
 }
-
-func (t *taskService) GetTaskIsDoneAndFilePath(id int64, username string) (bool, string, error) {
-	taskData, err := t.taskDao.GetTaskByIdAndUsername(username, id)
-	if err != nil {
-		return false, "", err
-	}
-	if taskData.Status != 2 {
-		return false, "", nil
-	}
-	return true, taskData.ResultKey, nil
-}
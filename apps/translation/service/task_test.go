@@ -0,0 +1,594 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/formats"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+// 以下为进程内的 dao 实现，只实现任务执行流程用到的部分
+
+type memTaskDao struct {
+	mu    sync.Mutex
+	tasks []*models.TaskModel
+}
+
+func (d *memTaskDao) CreateTask(task *models.TaskModel) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	task.ID = uint(len(d.tasks) + 1)
+	d.tasks = append(d.tasks, task)
+	return nil
+}
+
+func (d *memTaskDao) CreateTaskWithChildren(parent *models.TaskModel, children []*models.TaskModel) error {
+	if err := d.CreateTask(parent); err != nil {
+		return err
+	}
+	for _, child := range children {
+		child.ParentId = parent.ID
+		if err := d.CreateTask(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *memTaskDao) ListChildTasks(parentId int64) ([]*models.TaskModel, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var list []*models.TaskModel
+	for _, task := range d.tasks {
+		if int64(task.ParentId) == parentId {
+			copied := *task
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+
+func (d *memTaskDao) ListTasksByTemplate(string, int, int, int) ([]*models.TaskModel, int64, error) {
+	return nil, 0, nil
+}
+
+func (d *memTaskDao) ListTasks(username string, _ *int, _ string, _, _ int) ([]*models.TaskModel, int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var list []*models.TaskModel
+	for _, task := range d.tasks {
+		if task.CreateBy == username {
+			copied := *task
+			list = append(list, &copied)
+		}
+	}
+	return list, int64(len(list)), nil
+}
+
+func (d *memTaskDao) GetTaskByIdAndUsername(username string, taskId int64) (*models.TaskModel, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, task := range d.tasks {
+		if int64(task.ID) == taskId && task.CreateBy == username {
+			copied := *task
+			return &copied, nil
+		}
+	}
+	return nil, unify_response.NotFound()
+}
+
+func (d *memTaskDao) ListTasksByIds(string, []int64) ([]*models.TaskModel, error) {
+	return nil, nil
+}
+
+func (d *memTaskDao) UpdateTaskStatus(taskId int64, updates map[string]any) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, task := range d.tasks {
+		if int64(task.ID) == taskId {
+			applyColumns(task, updates)
+		}
+	}
+	return nil
+}
+
+func (d *memTaskDao) StartTask(taskId int64, updates map[string]any) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, task := range d.tasks {
+		if int64(task.ID) == taskId && task.Status != models.TaskStatusRunning {
+			updates["status"] = models.TaskStatusRunning
+			applyColumns(task, updates)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// applyColumns 按 gorm 的 column 标签把更新写入模型，nil 为零值
+func applyColumns(model any, updates map[string]any) {
+	v := reflect.ValueOf(model).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		for _, part := range strings.Split(field.Tag.Get("gorm"), ";") {
+			column, ok := strings.CutPrefix(part, "column:")
+			if !ok {
+				continue
+			}
+			value, ok := updates[column]
+			if !ok {
+				continue
+			}
+			if value == nil {
+				v.Field(i).SetZero()
+				continue
+			}
+			rv := reflect.ValueOf(value)
+			if v.Field(i).Kind() == reflect.Pointer {
+				ptr := reflect.New(v.Field(i).Type().Elem())
+				ptr.Elem().Set(rv.Convert(ptr.Elem().Type()))
+				v.Field(i).Set(ptr)
+				continue
+			}
+			v.Field(i).Set(rv.Convert(field.Type))
+		}
+	}
+}
+
+func (d *memTaskDao) get(id uint) models.TaskModel {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return *d.tasks[id-1]
+}
+
+type memGlossaryDao struct {
+	dao.IGlossaryDao
+	entries []*models.GlossaryModel
+}
+
+func (d *memGlossaryDao) FindGlossaryByLangPair(username, sourceLang, targetLang string) ([]*models.GlossaryModel, error) {
+	return d.entries, nil
+}
+
+type memMemoryDao struct {
+	mu       sync.Mutex
+	memories []*models.TranslationMemoryModel
+}
+
+func (d *memMemoryDao) SaveTranslationMemory(memories []*models.TranslationMemoryModel) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, memory := range memories {
+		i := slices.IndexFunc(d.memories, func(m *models.TranslationMemoryModel) bool {
+			return m.CreateBy == memory.CreateBy && m.SourceHash == memory.SourceHash &&
+				m.Lang == memory.Lang && m.TargetLang == memory.TargetLang
+		})
+		if i >= 0 {
+			d.memories[i] = memory
+		} else {
+			d.memories = append(d.memories, memory)
+		}
+	}
+	return nil
+}
+
+func (d *memMemoryDao) FindExactMemory(username, sourceHash, lang, targetLang string) (*models.TranslationMemoryModel, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, m := range d.memories {
+		if m.CreateBy == username && m.SourceHash == sourceHash && m.Lang == lang && m.TargetLang == targetLang {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *memMemoryDao) FindMemoryCandidates(string, string, string, int, int, int) ([]*models.TranslationMemoryModel, error) {
+	return nil, nil
+}
+
+type memTemplateDao struct {
+	dao.IPromptTemplateDao
+}
+
+func (d *memTemplateDao) GetLatestPromptTemplate(string) (*models.PromptTemplateModel, error) {
+	return nil, nil
+}
+
+func (d *memTemplateDao) GetPromptTemplate(string, int) (*models.PromptTemplateModel, error) {
+	return nil, nil
+}
+
+type memUsageDao struct {
+	dao.IUsageRecordDao
+	mu      sync.Mutex
+	records []*models.UsageRecordModel
+}
+
+func (d *memUsageDao) CreateUsageRecord(record *models.UsageRecordModel) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.records = append(d.records, record)
+	return nil
+}
+
+type memQuotaDao struct {
+	dao.IQuotaDao
+	mu    sync.Mutex
+	quota models.UserQuotaModel
+}
+
+func (d *memQuotaDao) GetUserQuota(string, time.Time, time.Time) (*models.UserQuotaModel, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	quota := d.quota
+	return &quota, nil
+}
+
+func (d *memQuotaDao) ConsumeQuota(username string, chars, tokens int64, day, month time.Time,
+	check func(quota *models.UserQuotaModel) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if check != nil {
+		if err := check(&d.quota); err != nil {
+			return err
+		}
+	}
+	d.quota.DailyChars = max(d.quota.DailyChars+chars, 0)
+	d.quota.DailyTokens = max(d.quota.DailyTokens+tokens, 0)
+	return nil
+}
+
+type testTaskService struct {
+	*taskService
+	tasks    *memTaskDao
+	memory   *memMemoryDao
+	quota    *memQuotaDao
+	messages chan map[string]any
+}
+
+func newTestTaskService(t *testing.T) *testTaskService {
+	t.Helper()
+	dir := t.TempDir()
+	conf := "task_result_dir: " + dir + "\ntask_worker:\n  workers: 2\n  queue_size: 4\n"
+	confPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(confPath, []byte(conf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.ParseConfig(confPath); err != nil {
+		t.Fatal(err)
+	}
+	s := &testTaskService{
+		tasks:    &memTaskDao{},
+		memory:   &memMemoryDao{},
+		quota:    &memQuotaDao{},
+		messages: make(chan map[string]any, 1024),
+	}
+	s.taskService = NewTaskService(s.tasks, &memGlossaryDao{}, s.memory, &memTemplateDao{}, &memUsageDao{},
+		s.quota, llm.NewFakeClient("fake"), s.messages).(*taskService)
+	s.StartWorkers()
+	return s
+}
+
+// waitStatus 等待任务的状态消息，返回期间收到的该任务的所有消息
+func (s *testTaskService) waitStatus(t *testing.T, taskId int, status int) []map[string]any {
+	t.Helper()
+	var received []map[string]any
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case message := <-s.messages:
+			if message["task_id"] != int64(taskId) {
+				continue
+			}
+			received = append(received, message)
+			if message["type"] == messageTypeStatus && message["status"] == status {
+				return received
+			}
+		case <-timeout:
+			t.Fatalf("task %d did not reach status %d, received %v", taskId, status, received)
+		}
+	}
+}
+
+func TestTaskRunWithFakeProvider(t *testing.T) {
+	s := newTestTaskService(t)
+	content := "Hello world.\n\nSecond paragraph."
+	task, err := s.CreateTask("alice", &request_mapping.CreateTaskReq{
+		Content: content, Lang: "en", TargetLang: "zh-CN",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.ExecuteTask("alice", int64(task.Id)); err != nil {
+		t.Fatal(err)
+	}
+	messages := s.waitStatus(t, task.Id, models.TaskStatusDone)
+	var deltas []string
+	for _, message := range messages {
+		if message["type"] == messageTypeDelta {
+			deltas = append(deltas, message["delta"].(string))
+		}
+	}
+	if len(deltas) == 0 {
+		t.Error("no delta messages")
+	}
+
+	detail, err := s.GetTaskDetail("alice", int64(task.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[zh-CN] Hello world.\n\n[zh-CN] Second paragraph."
+	if detail.Status != models.TaskStatusDone || detail.Result != want {
+		t.Fatalf("status = %d, result = %q", detail.Status, detail.Result)
+	}
+	// 列表不读取结果文件
+	list, _, err := s.ListTasks("alice", &request_mapping.ListTaskReq{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Status != models.TaskStatusDone || list[0].Result != "" {
+		t.Errorf("list = %+v", list)
+	}
+	if detail.Provider != "fake" || detail.SegmentCount == 0 || detail.MemorySegments != 0 {
+		t.Errorf("provider = %q, segments = %d, memory = %d",
+			detail.Provider, detail.SegmentCount, detail.MemorySegments)
+	}
+	if s.quota.quota.DailyChars != int64(len([]rune(content))) {
+		t.Errorf("charged chars = %d", s.quota.quota.DailyChars)
+	}
+
+	// 纯文本任务导出为双语 XLIFF，按段落对应
+	files, _, err := s.GetTaskResultFiles("alice", int64(task.Id), "", formats.ExportXLIFF20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := files[0]
+	if file.ContentType != formats.XLIFFContentType || file.Filename != fmt.Sprintf("task-%d-zh-CN.xlf", task.Id) ||
+		!strings.Contains(string(file.Content), `srcLang="en" trgLang="zh-CN"`) ||
+		!strings.Contains(string(file.Content), "<target xml:space=\"preserve\">[zh-CN] Second paragraph.</target>") {
+		t.Errorf("export = %s %q\n%s", file.ContentType, file.Filename, file.Content)
+	}
+
+	// 相同原文的第二个任务直接复用翻译记忆
+	second, err := s.CreateTask("alice", &request_mapping.CreateTaskReq{
+		Content: content, Lang: "en", TargetLang: "zh-CN",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.ExecuteTask("alice", int64(second.Id)); err != nil {
+		t.Fatal(err)
+	}
+	s.waitStatus(t, second.Id, models.TaskStatusDone)
+	detail, err = s.GetTaskDetail("alice", int64(second.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Result != want || detail.MemorySegments != detail.SegmentCount {
+		t.Errorf("result = %q, memory = %d of %d", detail.Result, detail.MemorySegments, detail.SegmentCount)
+	}
+}
+
+func TestMemorySkippedForUndetectedSource(t *testing.T) {
+	s := newTestTaskService(t)
+	// 内容太短，识别结果不会被采用，源语言保持 auto-detect
+	for range 2 {
+		task, err := s.CreateTask("alice", &request_mapping.CreateTaskReq{
+			Content: "Hi.", Lang: models.AutoDetect, TargetLang: "fr",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.Lang != models.AutoDetect {
+			t.Fatalf("lang = %q, want %q", task.Lang, models.AutoDetect)
+		}
+		if err = s.ExecuteTask("alice", int64(task.Id)); err != nil {
+			t.Fatal(err)
+		}
+		s.waitStatus(t, task.Id, models.TaskStatusDone)
+		detail, err := s.GetTaskDetail("alice", int64(task.Id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detail.MemorySegments != 0 {
+			t.Errorf("memory segments = %d", detail.MemorySegments)
+		}
+	}
+	if len(s.memory.memories) != 0 {
+		t.Errorf("saved %d memories for an unknown source language", len(s.memory.memories))
+	}
+}
+
+func TestExecuteRunningTaskRejected(t *testing.T) {
+	s := newTestTaskService(t)
+	task, err := s.CreateTask("bob", &request_mapping.CreateTaskReq{
+		Content: "Good morning.", Lang: "en", TargetLang: "fr",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.tasks.UpdateTaskStatus(int64(task.Id), map[string]any{"status": models.TaskStatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.ExecuteTask("bob", int64(task.Id)); err == nil {
+		t.Fatal("running task executed again")
+	}
+	if s.quota.quota.DailyChars != 0 {
+		t.Errorf("charged chars = %d for a rejected task", s.quota.quota.DailyChars)
+	}
+}
+
+func TestMultiTargetTask(t *testing.T) {
+	s := newTestTaskService(t)
+	parent, err := s.CreateTask("carol", &request_mapping.CreateTaskReq{
+		Content: "See you tomorrow.", Lang: "en", TargetLangs: []string{"de", "ja"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.ExecuteTask("carol", int64(parent.Id)); err != nil {
+		t.Fatal(err)
+	}
+	s.waitStatus(t, parent.Id, models.TaskStatusDone)
+	files, archive, err := s.GetTaskResultFiles("carol", int64(parent.Id), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	langs := []string{files[0].Lang, files[1].Lang}
+	slices.Sort(langs)
+	if !archive || !slices.Equal(langs, []string{"de", "ja"}) {
+		t.Errorf("archive = %v, langs = %v", archive, langs)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "[" + file.Lang + "] See you tomorrow."; string(data) != want {
+			t.Errorf("%s result = %q, want %q", file.Lang, data, want)
+		}
+	}
+}
+
+// droppingClient 第一次调用丢弃译文中的全部标记，之后原样返回原文
+type droppingClient struct {
+	calls atomic.Int32
+}
+
+func (c *droppingClient) Translate(_ context.Context, req *llm.TranslateRequest) (*llm.TranslateResult, error) {
+	text := req.Content
+	if c.calls.Add(1) == 1 {
+		text = "All separators lost"
+	}
+	return &llm.TranslateResult{Text: text, Provider: "scripted"}, nil
+}
+
+func TestSubtitleSeparatorRetry(t *testing.T) {
+	s := newTestTaskService(t)
+	client := &droppingClient{}
+	s.llm = client
+	content := "1\n00:00:01,000 --> 00:00:02,000\nHello.\n\n2\n00:00:03,000 --> 00:00:04,000\nGoodbye.\n"
+	task, err := s.UploadTask("alice", &request_mapping.UploadTaskReq{
+		CreateTaskReq: request_mapping.CreateTaskReq{Content: content, Lang: "en", TargetLang: "fr"},
+		Filename:      "demo.srt",
+		Format:        formats.FormatSRT,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.ExecuteTask("alice", int64(task.Id)); err != nil {
+		t.Fatal(err)
+	}
+	s.waitStatus(t, task.Id, models.TaskStatusDone)
+	detail, err := s.GetTaskDetail("alice", int64(task.Id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.calls.Load() != 2 {
+		t.Errorf("calls = %d, want a retry after the separators were lost", client.calls.Load())
+	}
+	if detail.Result != content {
+		t.Errorf("result = %q", detail.Result)
+	}
+}
+
+// errorClient 每次调用都返回同一个错误
+type errorClient struct {
+	err   error
+	calls atomic.Int32
+}
+
+func (c *errorClient) Translate(context.Context, *llm.TranslateRequest) (*llm.TranslateResult, error) {
+	c.calls.Add(1)
+	return nil, c.err
+}
+
+func TestSegmentDoesNotRetryCallErrors(t *testing.T) {
+	for _, err := range []error{
+		&llm.APIError{StatusCode: 400, Message: "bad request"},
+		&llm.APIError{StatusCode: 401, Message: "unauthorized"},
+		llm.ErrNoProvider,
+		&llm.CircuitOpenError{Provider: "p1", RetryAt: time.Now().Add(time.Minute)},
+	} {
+		s := newTestTaskService(t)
+		client := &errorClient{err: err}
+		s.llm = client
+		task, createErr := s.CreateTask("alice", &request_mapping.CreateTaskReq{
+			Content: "Hello world.", Lang: "en", TargetLang: "fr",
+		})
+		if createErr != nil {
+			t.Fatal(createErr)
+		}
+		if createErr = s.ExecuteTask("alice", int64(task.Id)); createErr != nil {
+			t.Fatal(createErr)
+		}
+		s.waitStatus(t, task.Id, models.TaskStatusFailed)
+		if n := client.calls.Load(); n != 1 {
+			t.Errorf("%v: calls = %d, want 1", err, n)
+		}
+	}
+}
+
+// failingClient 原文包含 fail 时等其它分段都完成后返回错误，其余原样返回
+type failingClient struct {
+	fail      string
+	others    int
+	succeeded chan struct{}
+}
+
+func (c *failingClient) Translate(_ context.Context, req *llm.TranslateRequest) (*llm.TranslateResult, error) {
+	if !strings.Contains(req.Content, c.fail) {
+		c.succeeded <- struct{}{}
+		return &llm.TranslateResult{Text: req.Content, Provider: "scripted"}, nil
+	}
+	for range c.others {
+		<-c.succeeded
+	}
+	return nil, &llm.APIError{StatusCode: 400, Message: "bad request"}
+}
+
+func TestFailedTaskRefundsUntranslatedShare(t *testing.T) {
+	s := newTestTaskService(t)
+	// 每个段落一段，三段中只有一段失败
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "config.yaml")
+	conf := "task_result_dir: " + dir + "\nchunk:\n  max_tokens: 4\n  concurrency: 3\n"
+	if err := os.WriteFile(confPath, []byte(conf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.ParseConfig(confPath); err != nil {
+		t.Fatal(err)
+	}
+	s.llm = &failingClient{fail: "Bravo", others: 2, succeeded: make(chan struct{}, 2)}
+	content := "Alpha one two.\n\nBravo one two.\n\nCharl one two."
+	task, err := s.CreateTask("alice", &request_mapping.CreateTaskReq{Content: content, Lang: "en", TargetLang: "fr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.ExecuteTask("alice", int64(task.Id)); err != nil {
+		t.Fatal(err)
+	}
+	s.waitStatus(t, task.Id, models.TaskStatusFailed)
+	chars := len([]rune(content))
+	want := int64(chars - int(math.Round(float64(chars)/3)))
+	if got := s.quota.quota.DailyChars; got != want {
+		t.Errorf("charged chars = %d, want %d for two of three segments", got, want)
+	}
+}
@@ -1,5 +1,12 @@
 package service
 
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/glossary"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/placeholder"
+	"time"
+)
+
 type TaskData struct {
 	Id         int    `json:"id"`
 	Status     int    `json:"status"`
@@ -10,4 +17,160 @@ type TaskData struct {
 	Lang       string `json:"lang"`
 	TargetLang string `json:"target_lang"`
 	Result     string `json:"result"`
+	// Provider 产出结果的服务商，ProviderChain 为依次尝试过的服务商
+	Provider      string        `json:"provider"`
+	ProviderChain []llm.Attempt `json:"provider_chain"`
+	ErrorMsg      string        `json:"error_msg,omitempty"`
+	// SegmentCount 分段数，MemorySegments 其中直接复用翻译记忆的分段数
+	SegmentCount   int `json:"segment_count"`
+	MemorySegments int `json:"memory_segments"`
+	// GlossaryViolations 译文中未按术语表出现的术语
+	GlossaryViolations []glossary.Violation `json:"glossary_violations"`
+	// PlaceholderMode 占位符保护模式，PlaceholderIssues 为译文中与原文不一致的占位符
+	PlaceholderMode   string             `json:"placeholder_mode"`
+	PlaceholderIssues []PlaceholderIssue `json:"placeholder_issues,omitempty"`
+	// ContextTaskIds 引用的前序任务，UsedContextTaskIds 为按 token 预算裁剪后实际使用的任务
+	ContextTaskIds     []int64 `json:"context_task_ids,omitempty"`
+	UsedContextTaskIds []int64 `json:"used_context_task_ids,omitempty"`
+	// Format 文件格式，OriginalFilename 上传的文件名，直接提交文本的任务为空
+	Format           string `json:"format,omitempty"`
+	OriginalFilename string `json:"original_filename,omitempty"`
+	// MaxLineChars 字幕译文每行最多的字符数
+	MaxLineChars int `json:"max_line_chars,omitempty"`
+	// TranslateAll 双语文件中已有译文的条目也重新翻译
+	TranslateAll bool `json:"translate_all,omitempty"`
+	// DetectedLang 自动识别出的源语言，DetectConfidence 为置信度
+	DetectedLang     string  `json:"detected_lang,omitempty"`
+	DetectConfidence float64 `json:"detect_confidence,omitempty"`
+	// 多目标语言任务：父任务带 TargetLangs 与各语言的子任务，子任务带 ParentId
+	ParentId    int         `json:"parent_id,omitempty"`
+	TargetLangs []string    `json:"target_langs,omitempty"`
+	Children    []*TaskData `json:"children,omitempty"`
+	// TemplateName、TemplateVersion 产出结果的提示词模板
+	TemplateName    string `json:"template_name"`
+	TemplateVersion int    `json:"template_version"`
+	Tone            string `json:"tone,omitempty"`
+	Formality       string `json:"formality,omitempty"`
+	Domain          string `json:"domain,omitempty"`
+	// NoCache 是否跳过译文缓存，CacheHits 为命中缓存的分段数
+	NoCache   bool `json:"no_cache"`
+	CacheHits int  `json:"cache_hits"`
+	// Quality 译文质量评估结果，未启用评估或评估尚未完成时为空
+	Quality *QualityData `json:"quality,omitempty"`
+}
+
+// PlaceholderIssue Segment 为分段序号
+type PlaceholderIssue struct {
+	Segment int `json:"segment"`
+	placeholder.Issue
+}
+
+// QualityData 译文质量评估，Score 为各项指标的加权综合得分，取值 [0, 1]
+type QualityData struct {
+	Score float64 `json:"score"`
+	// BackTranslationChrF 回译结果与原文的 chrF 值
+	BackTranslationChrF *float64 `json:"back_translation_chrf,omitempty"`
+	// LengthRatio 译文与原文的字符数之比
+	LengthRatio float64 `json:"length_ratio"`
+	// UntranslatedRatio 原文中原样出现在译文里的词所占比例
+	UntranslatedRatio float64 `json:"untranslated_ratio"`
+	// JudgeScore 模型评分，JudgeReason 为评分理由
+	JudgeScore  *float64 `json:"judge_score,omitempty"`
+	JudgeReason string   `json:"judge_reason,omitempty"`
+}
+
+// UsageReport 用量统计，Totals 按币种汇总
+type UsageReport struct {
+	Start    string          `json:"start"`
+	End      string          `json:"end"`
+	GroupBy  string          `json:"group_by"`
+	Username string          `json:"username,omitempty"`
+	Totals   []*UsageSummary `json:"totals"`
+	Items    []*UsageSummary `json:"items"`
+}
+
+// UsageSummary 一个分组的用量，只填充参与分组的字段
+type UsageSummary struct {
+	Lang             string  `json:"lang,omitempty"`
+	TargetLang       string  `json:"target_lang,omitempty"`
+	Provider         string  `json:"provider,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	Username         string  `json:"username,omitempty"`
+	Day              string  `json:"day,omitempty"`
+	Currency         string  `json:"currency"`
+	Calls            int64   `json:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// QuotaPlanData 配额套餐，上限为 0 表示不限制
+type QuotaPlanData struct {
+	Id            int    `json:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	DailyChars    int64  `json:"daily_chars"`
+	MonthlyChars  int64  `json:"monthly_chars"`
+	DailyTokens   int64  `json:"daily_tokens"`
+	MonthlyTokens int64  `json:"monthly_tokens"`
+}
+
+// QuotaBalance 配额余量，Plan 为空表示不限制
+type QuotaBalance struct {
+	Username string           `json:"username"`
+	Enabled  bool             `json:"enabled"`
+	Plan     *QuotaPlanData   `json:"plan"`
+	Daily    *QuotaPeriodData `json:"daily"`
+	Monthly  *QuotaPeriodData `json:"monthly"`
+}
+
+// QuotaPeriodData 一个周期内的用量，上限为 0 时剩余量为 null
+type QuotaPeriodData struct {
+	Start           time.Time `json:"start"`
+	ResetAt         time.Time `json:"reset_at"`
+	UsedChars       int64     `json:"used_chars"`
+	CharsLimit      int64     `json:"chars_limit"`
+	RemainingChars  *int64    `json:"remaining_chars"`
+	UsedTokens      int64     `json:"used_tokens"`
+	TokensLimit     int64     `json:"tokens_limit"`
+	RemainingTokens *int64    `json:"remaining_tokens"`
+}
+
+// LLMHealth Status 为 up、degraded 或 down
+type LLMHealth struct {
+	Status    string               `json:"status"`
+	Providers []llm.ProviderHealth `json:"providers"`
+}
+
+// PromptTemplateData 提示词模板，Source 为 db、config 或 builtin
+type PromptTemplateData struct {
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+	Source      string `json:"source"`
+	CreateBy    string `json:"create_by,omitempty"`
+}
+
+// ResultFile 已完成任务的译文文件
+type ResultFile struct {
+	TaskId int
+	Lang   string
+	Path   string
+	// Filename 下载时的文件名
+	Filename string
+	// Content 不为空时下载该内容而不是 Path 指向的文件，例如导出的双语 XLIFF，ContentType 为其媒体类型
+	Content     []byte
+	ContentType string
+}
+
+type GlossaryData struct {
+	Id             int    `json:"id"`
+	Term           string `json:"term"`
+	SourceLang     string `json:"source_lang"`
+	TargetLang     string `json:"target_lang"`
+	Translation    string `json:"translation"`
+	CaseSensitive  bool   `json:"case_sensitive"`
+	DoNotTranslate bool   `json:"do_not_translate"`
 }
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/request_mapping"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/llm"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/logger"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+	"go.uber.org/zap"
+	"math"
+	"strings"
+)
+
+const defaultCurrency = "USD"
+
+type IUsageService interface {
+	// GetUsage 统计模型调用的用量与费用，普通用户只能查看自己的用量
+	GetUsage(username string, req *request_mapping.UsageReq) (*UsageReport, error)
+}
+
+type usageService struct {
+	usageDao dao.IUsageRecordDao
+	userDao  dao.IUserDao
+}
+
+func NewUsageService(usageDao dao.IUsageRecordDao, userDao dao.IUserDao) IUsageService {
+	return &usageService{
+		usageDao: usageDao,
+		userDao:  userDao,
+	}
+}
+
+func (u *usageService) GetUsage(username string, req *request_mapping.UsageReq) (*UsageReport, error) {
+	role, err := u.userDao.GetUserRole(username)
+	if err != nil {
+		return nil, err
+	}
+	target := req.Username
+	if role != models.UserRoleAdmin {
+		if target != "" && target != username {
+			return nil, unify_response.NewForbidden("只能查看自己的用量")
+		}
+		target = username
+	}
+	list, err := u.usageDao.AggregateUsage(target, req.StartTime, req.EndTime, req.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	report := &UsageReport{
+		Start:    req.Start,
+		End:      req.End,
+		GroupBy:  req.GroupBy,
+		Username: target,
+		Items:    make([]*UsageSummary, 0, len(list)),
+	}
+	totals := make(map[string]*UsageSummary)
+	for _, row := range list {
+		item := &UsageSummary{
+			Lang:             row.Lang,
+			TargetLang:       row.TargetLang,
+			Provider:         row.Provider,
+			Model:            row.Model,
+			Username:         row.CreateBy,
+			Day:              row.Day,
+			Currency:         row.Currency,
+			Calls:            row.Calls,
+			PromptTokens:     row.PromptTokens,
+			CompletionTokens: row.CompletionTokens,
+			TotalTokens:      row.TotalTokens,
+			Cost:             roundCost(row.Cost),
+		}
+		report.Items = append(report.Items, item)
+		total, ok := totals[row.Currency]
+		if !ok {
+			total = &UsageSummary{Currency: row.Currency}
+			totals[row.Currency] = total
+			report.Totals = append(report.Totals, total)
+		}
+		total.Calls += item.Calls
+		total.PromptTokens += item.PromptTokens
+		total.CompletionTokens += item.CompletionTokens
+		total.TotalTokens += item.TotalTokens
+		total.Cost = roundCost(total.Cost + row.Cost)
+	}
+	return report, nil
+}
+
+// recordUsage 记录一次成功的模型调用，服务商未返回用量时按输入输出文本估算
+// 记录失败只写日志，不影响任务
+func (t *taskService) recordUsage(task *models.TaskModel, operation, lang, targetLang, input string,
+	result *llm.TranslateResult) {
+	// 命中缓存时没有调用模型，不产生费用
+	if result == nil || result.Cached {
+		return
+	}
+	usage := result.Usage
+	estimated := !usage.Reported()
+	if estimated {
+		usage.PromptTokens = segmenter.EstimateTokens(input)
+		usage.CompletionTokens = segmenter.EstimateTokens(result.Text)
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	cost, currency := computeCost(result.Provider, result.Model, usage)
+	err := t.usageDao.CreateUsageRecord(&models.UsageRecordModel{
+		CreateBy:         task.CreateBy,
+		TaskId:           task.ID,
+		Operation:        operation,
+		Provider:         result.Provider,
+		LLMModel:         result.Model,
+		Lang:             lang,
+		TargetLang:       targetLang,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		Estimated:        estimated,
+		LatencyMs:        result.Latency.Milliseconds(),
+		Cost:             cost,
+		Currency:         currency,
+	})
+	if err != nil {
+		logger.Warn("save usage record failed", zap.Uint("task_id", task.ID), zap.Error(err))
+	}
+	addQuotaTokens(t.quotaDao, task.CreateBy, usage.TotalTokens)
+}
+
+// computeCost 按价格表计算费用，未配置价格的模型费用为 0
+func computeCost(provider, model string, usage llm.Usage) (float64, string) {
+	currency := defaultCurrency
+	pricing := config.GetConfig().Pricing
+	if pricing == nil {
+		return 0, currency
+	}
+	if pricing.Currency != "" {
+		currency = pricing.Currency
+	}
+	var promptPrice, completionPrice float64
+	matchedLen := -1
+	for _, p := range pricing.Prices {
+		if p == nil || p.Provider != provider || !strings.HasPrefix(model, p.Model) {
+			continue
+		}
+		if len(p.Model) > matchedLen {
+			matchedLen = len(p.Model)
+			promptPrice, completionPrice = p.PromptPrice, p.CompletionPrice
+		}
+	}
+	if matchedLen < 0 {
+		return 0, currency
+	}
+	cost := (float64(usage.PromptTokens)*promptPrice + float64(usage.CompletionTokens)*completionPrice) / 1e6
+	return roundCost(cost), currency
+}
+
+func roundCost(v float64) float64 {
+	return math.Round(v*1e8) / 1e8
+}
@@ -1,10 +1,14 @@
 package service
 
-import "github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/dao"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+)
 
 type IUserService interface {
 	HandlerLogin(username, password string) (string, error)
 	HandlerRegister(username, password string) error
+	IsAdmin(username string) (bool, error)
 }
 
 type userService struct {
@@ -32,3 +36,11 @@ func (u *userService) HandlerRegister(username, password string) error {
 	}
 	return nil
 }
+
+func (u *userService) IsAdmin(username string) (bool, error) {
+	role, err := u.userDao.GetUserRole(username)
+	if err != nil {
+		return false, err
+	}
+	return role == models.UserRoleAdmin, nil
+}
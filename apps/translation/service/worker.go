@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/jovian1994/cxh-1207-be-interview/apps/translation/config"
+	"github.com/jovian1994/cxh-1207-be-interview/models"
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/unify_response"
+)
+
+const (
+	defaultTaskWorkers   = 8
+	defaultTaskQueueSize = 1000
+)
+
+type taskWorkerOptions struct {
+	workers   int
+	queueSize int
+}
+
+func getTaskWorkerOptions() taskWorkerOptions {
+	opt := taskWorkerOptions{
+		workers:   defaultTaskWorkers,
+		queueSize: defaultTaskQueueSize,
+	}
+	workerConfig := config.GetConfig().TaskWorker
+	if workerConfig == nil {
+		return opt
+	}
+	if workerConfig.Workers > 0 {
+		opt.workers = workerConfig.Workers
+	}
+	if workerConfig.QueueSize > 0 {
+		opt.queueSize = workerConfig.QueueSize
+	}
+	return opt
+}
+
+// StartWorkers 启动固定数量的协程执行队列中的任务，服务商的限流在 llm 客户端中处理
+func (t *taskService) StartWorkers() {
+	for i := 0; i < getTaskWorkerOptions().workers; i++ {
+		go func() {
+			for taskData := range t.queue {
+				t.run(taskData)
+			}
+		}()
+	}
+}
+
+// enqueue 队列已满时直接拒绝，不阻塞请求
+func (t *taskService) enqueue(taskData *models.TaskModel) error {
+	select {
+	case t.queue <- taskData:
+		return nil
+	default:
+		return unify_response.ServiceBusy("任务队列已满，请稍后重试")
+	}
+}
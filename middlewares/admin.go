@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// IAdminChecker 判断用户是否为管理员
+type IAdminChecker interface {
+	IsAdmin(username string) (bool, error)
+}
+
+// AdminRequired 只允许管理员访问，需要放在 LoginRequired 之后
+func AdminRequired(checker IAdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		isAdmin, err := checker.IsAdmin(c.GetString("username"))
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		if !isAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin permission is required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
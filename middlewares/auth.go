@@ -2,15 +2,20 @@ package middlewares
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/jovian1994/cxh-1207-be-interview/pkg/jwt"
 	"net/http"
 	"strings"
 )
 
 // LoginRequired 校验 JWT 的中间件
+// 浏览器建立 WebSocket 连接时无法设置请求头，此时可以通过 token 查询参数传递
 func LoginRequired(TokenVerify jwt.ITokenVerify) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
+		if tokenString == "" && c.Query("token") != "" && websocket.IsWebSocketUpgrade(c.Request) {
+			tokenString = "Bearer " + c.Query("token")
+		}
 		if tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
 			c.Abort()
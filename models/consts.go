@@ -3,8 +3,53 @@ package models
 const (
 	usersTableName = "users"
 	tasksTableName = "tasks"
+
+	glossariesTableName          = "glossaries"
+	translationMemoriesTableName = "translation_memories"
+	promptTemplatesTableName     = "prompt_templates"
+	usageRecordsTableName        = "usage_records"
+	quotaPlansTableName          = "quota_plans"
+	userQuotasTableName          = "user_quotas"
 )
 
 const (
 	AutoDetect = "auto-detect"
 )
+
+// 用户角色
+const (
+	UserRoleNormal = 0
+	UserRoleAdmin  = 1
+)
+
+// 任务状态
+const (
+	TaskStatusCreated = 0
+	TaskStatusRunning = 1
+	TaskStatusDone    = 2
+	TaskStatusFailed  = 3
+	// TaskStatusPartialDone 多目标语言任务中部分语言成功、部分失败
+	TaskStatusPartialDone = 4
+)
+
+// 任务列表排序方式
+const (
+	TaskSortCreated = "created"
+	// TaskSortQuality 按质量得分升序，得分低的优先复核，未评估的排在最后
+	TaskSortQuality = "quality"
+)
+
+// 模型调用用途
+const (
+	UsageOperationTranslate     = "translate"
+	UsageOperationBackTranslate = "back_translate"
+	UsageOperationJudge         = "judge"
+)
+
+// 用量统计的分组方式
+const (
+	UsageGroupLangPair = "lang_pair"
+	UsageGroupProvider = "provider"
+	UsageGroupUser     = "user"
+	UsageGroupDay      = "day"
+)
@@ -0,0 +1,19 @@
+package models
+
+import "gorm.io/gorm"
+
+// GlossaryModel 用户维护的术语表，SourceLang 为空表示适用于任意源语言
+type GlossaryModel struct {
+	gorm.Model
+	CreateBy       string `gorm:"column:create_by"`
+	Term           string `gorm:"column:term"`
+	SourceLang     string `gorm:"column:source_lang"`
+	TargetLang     string `gorm:"column:target_lang"`
+	Translation    string `gorm:"column:translation"`
+	CaseSensitive  bool   `gorm:"column:case_sensitive"`
+	DoNotTranslate bool   `gorm:"column:do_not_translate"`
+}
+
+func (GlossaryModel) TableName() string {
+	return glossariesTableName
+}
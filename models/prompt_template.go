@@ -0,0 +1,18 @@
+package models
+
+import "gorm.io/gorm"
+
+// PromptTemplateModel 系统提示词模板，同名模板每次修改都新增一个版本，版本号从 1 开始
+// Content 为 Go text/template 模板，可用变量见 llm.PromptData
+type PromptTemplateModel struct {
+	gorm.Model
+	Name        string `gorm:"column:name;uniqueIndex:idx_prompt_template_name_version"`
+	Version     int    `gorm:"column:version;uniqueIndex:idx_prompt_template_name_version"`
+	Content     string `gorm:"column:content;type:text"`
+	Description string `gorm:"column:description"`
+	CreateBy    string `gorm:"column:create_by"`
+}
+
+func (PromptTemplateModel) TableName() string {
+	return promptTemplatesTableName
+}
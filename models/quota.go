@@ -0,0 +1,39 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// QuotaPlanModel 配额套餐，各项上限为 0 表示不限制
+type QuotaPlanModel struct {
+	gorm.Model
+	Name          string `gorm:"column:name;uniqueIndex;size:64"`
+	Description   string `gorm:"column:description"`
+	DailyChars    int64  `gorm:"column:daily_chars"`
+	MonthlyChars  int64  `gorm:"column:monthly_chars"`
+	DailyTokens   int64  `gorm:"column:daily_tokens"`
+	MonthlyTokens int64  `gorm:"column:monthly_tokens"`
+}
+
+func (QuotaPlanModel) TableName() string {
+	return quotaPlansTableName
+}
+
+// UserQuotaModel 用户的套餐与当前周期内的已用量
+// PlanId 为 0 表示使用配置文件中的默认套餐，DailyPeriod、MonthlyPeriod 为已用量所属周期的起始时间
+type UserQuotaModel struct {
+	gorm.Model
+	Username      string    `gorm:"column:username;uniqueIndex;size:64"`
+	PlanId        uint      `gorm:"column:plan_id"`
+	DailyChars    int64     `gorm:"column:daily_chars"`
+	DailyTokens   int64     `gorm:"column:daily_tokens"`
+	DailyPeriod   time.Time `gorm:"column:daily_period;index"`
+	MonthlyChars  int64     `gorm:"column:monthly_chars"`
+	MonthlyTokens int64     `gorm:"column:monthly_tokens"`
+	MonthlyPeriod time.Time `gorm:"column:monthly_period;index"`
+}
+
+func (UserQuotaModel) TableName() string {
+	return userQuotasTableName
+}
@@ -13,6 +13,46 @@ type TaskModel struct {
 	Content    string `gorm:"column:content"`
 	Lang       string `gorm:"column:lang"`
 	TargetLang string `gorm:"column:target_lang"`
+	// Provider 实际产出结果的服务商，ProviderChain 为依次尝试过的服务商(JSON)
+	Provider      string `gorm:"column:provider"`
+	ProviderChain string `gorm:"column:provider_chain;type:text"`
+	ErrorMsg      string `gorm:"column:error_msg;type:text"`
+	SegmentCount  int    `gorm:"column:segment_count"`
+	// MemorySegments 直接复用翻译记忆的分段数
+	MemorySegments int `gorm:"column:memory_segments"`
+	// GlossaryViolations 译文中缺失的术语(JSON)
+	GlossaryViolations string `gorm:"column:glossary_violations;type:text"`
+	// DetectedLang 源语言为 auto-detect 时识别出的语言，DetectConfidence 为置信度
+	DetectedLang     string  `gorm:"column:detected_lang"`
+	DetectConfidence float64 `gorm:"column:detect_confidence"`
+	// ParentId 多目标语言任务中子任务所属的父任务，父任务本身不翻译，TargetLangs 为逗号分隔的目标语言
+	ParentId    uint   `gorm:"column:parent_id;index"`
+	TargetLangs string `gorm:"column:target_langs"`
+	// TemplateName、TemplateVersion 创建任务时选定的提示词模板，版本 0 为配置文件或内置模板
+	TemplateName    string `gorm:"column:template_name;index:idx_task_template"`
+	TemplateVersion int    `gorm:"column:template_version;index:idx_task_template"`
+	Tone            string `gorm:"column:tone"`
+	Formality       string `gorm:"column:formality"`
+	Domain          string `gorm:"column:domain"`
+	// QualityScore 译文质量综合得分，未评估时为 NULL；QualityDetail 为各项指标(JSON)
+	QualityScore  *float64 `gorm:"column:quality_score;index"`
+	QualityDetail string   `gorm:"column:quality_detail;type:text"`
+	// NoCache 执行时跳过译文缓存，CacheHits 为命中缓存的分段数
+	NoCache   bool `gorm:"column:no_cache"`
+	CacheHits int  `gorm:"column:cache_hits"`
+	// PlaceholderMode 占位符保护模式 off/warn/strict，PlaceholderIssues 为译文中不一致的占位符(JSON)
+	PlaceholderMode   string `gorm:"column:placeholder_mode"`
+	PlaceholderIssues string `gorm:"column:placeholder_issues;type:text"`
+	// ContextTaskIds 创建时引用的前序任务，UsedContextTaskIds 为按 token 预算裁剪后实际提供给模型的任务，均为逗号分隔
+	ContextTaskIds     string `gorm:"column:context_task_ids"`
+	UsedContextTaskIds string `gorm:"column:used_context_task_ids"`
+	// Format 上传文件的格式，为空时为纯文本；OriginalFilename 上传的文件名，下载译文时在其扩展名前加上目标语言
+	Format           string `gorm:"column:format"`
+	OriginalFilename string `gorm:"column:original_filename"`
+	// MaxLineChars 字幕译文每行最多的字符数，0 为不限制
+	MaxLineChars int `gorm:"column:max_line_chars"`
+	// TranslateAll 双语文件中已有译文的条目也重新翻译
+	TranslateAll bool `gorm:"column:translate_all"`
 }
 
 func (TaskModel) TableName() string {
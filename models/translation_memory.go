@@ -0,0 +1,21 @@
+package models
+
+import "gorm.io/gorm"
+
+// TranslationMemoryModel 翻译记忆，按用户和语言对保存已完成任务的分段原文与译文
+// 同一用户、语言对下相同的原文只保留一条，由唯一索引保证
+type TranslationMemoryModel struct {
+	gorm.Model
+	CreateBy     string `gorm:"column:create_by;size:64;uniqueIndex:idx_translation_memory_source"`
+	SourceHash   string `gorm:"column:source_hash;size:64;uniqueIndex:idx_translation_memory_source"`
+	SourceText   string `gorm:"column:source_text;type:text"` // 归一化后的原文
+	SourceLength int    `gorm:"column:source_length"`         // 归一化原文的字符数，用于模糊匹配时预筛选
+	Lang         string `gorm:"column:lang;size:32;uniqueIndex:idx_translation_memory_source"`
+	TargetLang   string `gorm:"column:target_lang;size:32;uniqueIndex:idx_translation_memory_source"`
+	Target       string `gorm:"column:target;type:text"`
+	TaskId       uint   `gorm:"column:task_id"`
+}
+
+func (TranslationMemoryModel) TableName() string {
+	return translationMemoriesTableName
+}
@@ -0,0 +1,28 @@
+package models
+
+import "gorm.io/gorm"
+
+// UsageRecordModel 每次模型调用的用量与费用，费用按调用时的价格表计算
+type UsageRecordModel struct {
+	gorm.Model
+	CreateBy string `gorm:"column:create_by;index"`
+	TaskId   uint   `gorm:"column:task_id;index"`
+	// Operation 调用用途：translate、back_translate、judge
+	Operation        string `gorm:"column:operation"`
+	Provider         string `gorm:"column:provider"`
+	LLMModel         string `gorm:"column:model"`
+	Lang             string `gorm:"column:lang"`
+	TargetLang       string `gorm:"column:target_lang"`
+	PromptTokens     int    `gorm:"column:prompt_tokens"`
+	CompletionTokens int    `gorm:"column:completion_tokens"`
+	TotalTokens      int    `gorm:"column:total_tokens"`
+	// Estimated 服务商未返回用量，token 数按文本长度估算
+	Estimated bool    `gorm:"column:estimated"`
+	LatencyMs int64   `gorm:"column:latency_ms"`
+	Cost      float64 `gorm:"column:cost;type:decimal(20,8)"`
+	Currency  string  `gorm:"column:currency"`
+}
+
+func (UsageRecordModel) TableName() string {
+	return usageRecordsTableName
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// ICache 字符串键值缓存，ttl 为 0 表示不过期
+type ICache interface {
+	// Get 未命中时返回 false，错误只表示缓存不可用，调用方可按未命中处理
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+}
+
+type tiered struct {
+	caches      []ICache
+	backfillTTL time.Duration
+}
+
+// NewTiered 组合多级缓存，按顺序查找，在后面的层命中时回填前面的层
+// 写入时写入所有层，backfillTTL 为回填时使用的过期时间
+func NewTiered(backfillTTL time.Duration, caches ...ICache) ICache {
+	return &tiered{
+		caches:      caches,
+		backfillTTL: backfillTTL,
+	}
+}
+
+func (t *tiered) Get(ctx context.Context, key string) (string, bool, error) {
+	var lastErr error
+	for i, c := range t.caches {
+		value, ok, err := c.Get(ctx, key)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !ok {
+			continue
+		}
+		for _, upper := range t.caches[:i] {
+			_ = upper.Set(ctx, key, value, t.backfillTTL)
+		}
+		return value, true, nil
+	}
+	return "", false, lastErr
+}
+
+func (t *tiered) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	var lastErr error
+	for _, c := range t.caches {
+		if err := c.Set(ctx, key, value, ttl); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisCache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedis 基于 Redis 的缓存，prefix 用于区分同一实例中的不同用途
+func NewRedis(client redis.UniversalClient, prefix string) ICache {
+	return &redisCache{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (r *redisCache) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (r *redisCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
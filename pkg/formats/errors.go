@@ -0,0 +1,13 @@
+package formats
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoTranslatableText 文档中没有需要翻译的文本
+var ErrNoTranslatableText = errors.New("formats: no translatable text")
+
+func errUnitCount(expected, actual int) error {
+	return fmt.Errorf("formats: expected %d translations, got %d", expected, actual)
+}
@@ -0,0 +1,123 @@
+package formats
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// 格式名称，保存在任务上
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatSRT      = "srt"
+	FormatVTT      = "vtt"
+	FormatPO       = "po"
+	FormatXLIFF    = "xliff"
+)
+
+// Unit 文档中需要翻译的一段文本
+type Unit struct {
+	Text string
+	// Context 相邻的原文，仅作为参考提供给模型，不参与翻译
+	Context string
+}
+
+// IDocument 解析后的文档
+type IDocument interface {
+	// Units 按文档顺序返回需要翻译的文本
+	Units() []Unit
+	// Render 用译文生成目标语言的文档，translations 与 Units 一一对应
+	Render(translations []string, targetLang string) ([]byte, error)
+}
+
+// IUnitValidator 单元译文需要满足结构要求的文档，例如合并翻译的字幕需要保留各条字幕之间的分隔元素，
+// 调用方在译文不满足要求时重新翻译该单元，Render 遇到不满足要求的译文时返回错误
+type IUnitValidator interface {
+	ValidateUnit(i int, translation string) error
+}
+
+// Options 解析时使用的任务参数，各格式只读取与自己相关的部分
+type Options struct {
+	// TargetLang 目标语言，PO 文件按目标语言的复数形式数量生成单元
+	TargetLang string
+	// MaxLineChars 字幕译文每行最多的字符数，超出时重新换行，0 表示保留模型返回的换行
+	MaxLineChars int
+	// TranslateAll 双语文件中已有译文的条目也重新翻译，默认只翻译没有译文或需要复核的条目
+	TranslateAll bool
+}
+
+// IHandler 一种文件格式的解析与生成
+type IHandler interface {
+	Name() string
+	// Extensions 支持的扩展名，第一个用于生成译文文件名
+	Extensions() []string
+	Parse(data []byte, opt Options) (IDocument, error)
+}
+
+var handlers = []IHandler{
+	&textHandler{},
+	&jsonHandler{},
+	&markdownHandler{},
+	&htmlHandler{},
+	&srtHandler{},
+	&vttHandler{},
+	&poHandler{},
+	&xliffHandler{},
+}
+
+// Get 按格式名称查找，名称为空时为纯文本
+func Get(name string) (IHandler, bool) {
+	if name == "" {
+		name = FormatText
+	}
+	for _, h := range handlers {
+		if h.Name() == name {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// ForFilename 按扩展名查找，不区分大小写
+func ForFilename(filename string) (IHandler, bool) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		return nil, false
+	}
+	for _, h := range handlers {
+		for _, e := range h.Extensions() {
+			if e == ext {
+				return h, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Extensions 所有支持的扩展名
+func Extensions() []string {
+	var list []string
+	for _, h := range handlers {
+		list = append(list, h.Extensions()...)
+	}
+	return list
+}
+
+// WrapsLines 格式是否支持限制每行字符数
+func WrapsLines(name string) bool {
+	return name == FormatSRT || name == FormatVTT
+}
+
+// Bilingual 原文与译文保存在同一个文件中的格式，生成的文件中 Units 仍然是原文
+func Bilingual(name string) bool {
+	return name == FormatPO || name == FormatXLIFF
+}
+
+// TargetFilename 在扩展名前加上目标语言，例如 guide.md 翻译为中文后为 guide.zh-CN.md
+func TargetFilename(filename, targetLang string) string {
+	filename = filepath.Base(filename)
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "." + targetLang + ext
+}
@@ -0,0 +1,57 @@
+package formats
+
+import (
+	"testing"
+)
+
+// unitTexts 原文作为译文，用于检查不翻译时生成的文件与原文相同
+func unitTexts(doc IDocument) []string {
+	units := doc.Units()
+	texts := make([]string, len(units))
+	for i, unit := range units {
+		texts[i] = unit.Text
+	}
+	return texts
+}
+
+// roundTrip 解析 data 后以原文生成文档
+func roundTrip(t *testing.T, format, data string, opt Options, targetLang string) (IDocument, string) {
+	t.Helper()
+	handler, ok := Get(format)
+	if !ok {
+		t.Fatalf("unknown format %q", format)
+	}
+	doc, err := handler.Parse([]byte(data), opt)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	out, err := doc.Render(unitTexts(doc), targetLang)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	return doc, string(out)
+}
+
+func TestGetAndForFilename(t *testing.T) {
+	if h, ok := Get(""); !ok || h.Name() != FormatText {
+		t.Errorf("Get(\"\") = %v, %v", h, ok)
+	}
+	cases := map[string]string{
+		"guide.md": FormatMarkdown, "INDEX.HTML": FormatHTML, "en.json": FormatJSON, "movie.srt": FormatSRT,
+		"talk.vtt": FormatVTT, "messages.pot": FormatPO, "app.xlf": FormatXLIFF, "notes.txt": FormatText,
+	}
+	for filename, want := range cases {
+		if h, ok := ForFilename(filename); !ok || h.Name() != want {
+			t.Errorf("ForFilename(%q) = %v, %v, want %s", filename, h, ok, want)
+		}
+	}
+	if _, ok := ForFilename("archive.zip"); ok {
+		t.Error("zip accepted")
+	}
+}
+
+func TestTargetFilename(t *testing.T) {
+	if got := TargetFilename("docs/guide.md", "zh-CN"); got != "guide.zh-CN.md" {
+		t.Errorf("TargetFilename = %q", got)
+	}
+}
@@ -0,0 +1,481 @@
+package formats
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlAttributes 需要翻译的属性
+var htmlAttributes = []string{"title", "alt", "placeholder", "aria-label"}
+
+// htmlSkipped 内容不翻译的元素
+var htmlSkipped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Code: true, atom.Pre: true, atom.Kbd: true, atom.Samp: true,
+	atom.Noscript: true, atom.Template: true, atom.Svg: true, atom.Math: true, atom.Textarea: true,
+}
+
+// htmlInline 行内元素，与相邻文字合并为一个单元翻译
+var htmlInline = map[atom.Atom]bool{
+	atom.A: true, atom.Abbr: true, atom.B: true, atom.Bdi: true, atom.Bdo: true, atom.Br: true, atom.Cite: true,
+	atom.Code: true, atom.Data: true, atom.Del: true, atom.Dfn: true, atom.Em: true, atom.Font: true, atom.I: true,
+	atom.Img: true, atom.Ins: true, atom.Kbd: true, atom.Label: true, atom.Mark: true, atom.Q: true, atom.S: true,
+	atom.Samp: true, atom.Small: true, atom.Span: true, atom.Strong: true, atom.Sub: true, atom.Sup: true,
+	atom.Time: true, atom.U: true, atom.Var: true, atom.Wbr: true,
+}
+
+// htmlVoid 没有结束标签的元素
+var htmlVoid = map[atom.Atom]bool{
+	atom.Area: true, atom.Base: true, atom.Br: true, atom.Col: true, atom.Embed: true, atom.Hr: true,
+	atom.Img: true, atom.Input: true, atom.Link: true, atom.Meta: true, atom.Param: true, atom.Source: true,
+	atom.Track: true, atom.Wbr: true,
+}
+
+// htmlHandler 翻译文本节点与 title、alt、placeholder、aria-label 属性，
+// 段落中的行内标签替换为占位元素，script、style、code 等元素与 translate="no" 的元素不翻译；
+// 生成译文时只替换译文所在的字节区间并把 <html> 的 lang 改为目标语言，标签、空白与实体写法原样保留
+type htmlHandler struct{}
+
+func (h *htmlHandler) Name() string {
+	return FormatHTML
+}
+
+func (h *htmlHandler) Extensions() []string {
+	return []string{".html", ".htm"}
+}
+
+func (h *htmlHandler) Parse(data []byte, opt Options) (IDocument, error) {
+	p := &htmlParser{doc: &htmlDocument{data: data}, z: html.NewTokenizer(bytes.NewReader(data))}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.doc, nil
+}
+
+type htmlDocument struct {
+	data  []byte
+	units []Unit
+	attrs []*htmlAttrValue
+	runs  []*htmlRun
+	// langTag <html> 开始标签的区间，文档片段为 nil
+	langTag *span
+}
+
+// htmlAttrValue 需要翻译的属性值，span 包含引号，quote 为 0 表示原文没有引号
+type htmlAttrValue struct {
+	span
+	quote byte
+	unit  int
+}
+
+// htmlRun 连续的文本与行内元素，tokens 为各占位元素在原文中的区间
+type htmlRun struct {
+	span
+	unit              int
+	tokens            []span
+	leading, trailing string
+}
+
+func (d *htmlDocument) Units() []Unit {
+	return d.units
+}
+
+func (d *htmlDocument) Render(translations []string, targetLang string) ([]byte, error) {
+	if len(translations) != len(d.units) {
+		return nil, errUnitCount(len(d.units), len(translations))
+	}
+	var edits []spanEdit
+	for _, run := range d.runs {
+		edits = append(edits, spanEdit{run.span, d.renderRun(run, translations)})
+	}
+	for _, attr := range d.attrs {
+		if !d.inRun(attr.span) {
+			edits = append(edits, spanEdit{attr.span, d.renderAttr(attr, translations)})
+		}
+	}
+	if d.langTag != nil && targetLang != "" {
+		if edit, ok := d.langEdit(targetLang); ok {
+			edits = append(edits, edit)
+		}
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+	var out bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		if e.start < last {
+			continue
+		}
+		out.Write(d.data[last:e.start])
+		out.WriteString(e.text)
+		last = e.end
+	}
+	out.Write(d.data[last:])
+	return out.Bytes(), nil
+}
+
+// renderRun 译文与原文相同时保留原文的写法，否则按译文中占位元素的顺序还原标签
+func (d *htmlDocument) renderRun(run *htmlRun, translations []string) string {
+	translation := translations[run.unit]
+	if translation == d.units[run.unit].Text {
+		return d.rewrite(run.span, translations)
+	}
+	var out strings.Builder
+	out.WriteString(run.leading)
+	last := 0
+	for _, loc := range inlinePlaceholderPattern.FindAllStringSubmatchIndex(translation, -1) {
+		out.WriteString(htmlTextEscaper.Replace(translation[last:loc[0]]))
+		last = loc[1]
+		id, err := strconv.Atoi(translation[loc[2]:loc[3]])
+		if err != nil || id < 1 || id > len(run.tokens) {
+			out.WriteString(htmlTextEscaper.Replace(translation[loc[0]:loc[1]]))
+			continue
+		}
+		out.WriteString(d.rewrite(run.tokens[id-1], translations))
+	}
+	out.WriteString(htmlTextEscaper.Replace(translation[last:]))
+	out.WriteString(run.trailing)
+	return out.String()
+}
+
+// rewrite 返回区间内的原文，其中需要翻译的属性值替换为译文
+func (d *htmlDocument) rewrite(s span, translations []string) string {
+	var out strings.Builder
+	last := s.start
+	for _, attr := range d.attrs {
+		if attr.start < s.start || attr.end > s.end {
+			continue
+		}
+		out.Write(d.data[last:attr.start])
+		out.WriteString(d.renderAttr(attr, translations))
+		last = attr.end
+	}
+	out.Write(d.data[last:s.end])
+	return out.String()
+}
+
+func (d *htmlDocument) renderAttr(attr *htmlAttrValue, translations []string) string {
+	translation := strings.TrimSpace(translations[attr.unit])
+	if translation == d.units[attr.unit].Text {
+		return string(d.data[attr.start:attr.end])
+	}
+	quote := attr.quote
+	if quote == 0 {
+		quote = '"'
+	}
+	escaped := strings.NewReplacer("&", "&amp;", string(quote), htmlQuoteEntity(quote)).Replace(translation)
+	return string(quote) + escaped + string(quote)
+}
+
+func (d *htmlDocument) inRun(s span) bool {
+	for _, run := range d.runs {
+		if s.start >= run.start && s.end <= run.end {
+			return true
+		}
+	}
+	return false
+}
+
+// langEdit 修改 <html> 的 lang，没有时添加在标签末尾，与目标语言相同时不修改
+func (d *htmlDocument) langEdit(targetLang string) (spanEdit, bool) {
+	tag := d.data[d.langTag.start:d.langTag.end]
+	for _, attr := range scanHTMLAttrs(tag) {
+		if attr.name != "lang" {
+			continue
+		}
+		if html.UnescapeString(string(tag[attr.value.start:attr.value.end])) == targetLang {
+			return spanEdit{}, false
+		}
+		quote := attr.quote
+		if quote == 0 {
+			quote = '"'
+		}
+		value := span{d.langTag.start + attr.value.start, d.langTag.start + attr.value.end}
+		if attr.quote != 0 {
+			value = span{value.start - 1, value.end + 1}
+		}
+		return spanEdit{value, string(quote) + htmlAttrEscaper.Replace(targetLang) + string(quote)}, true
+	}
+	end := d.langTag.end - 1
+	if bytes.HasSuffix(tag, []byte("/>")) {
+		end--
+	}
+	return spanEdit{span{end, end}, ` lang="` + htmlAttrEscaper.Replace(targetLang) + `"`}, true
+}
+
+var (
+	htmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	htmlAttrEscaper = strings.NewReplacer("&", "&amp;", `"`, "&quot;")
+)
+
+func htmlQuoteEntity(quote byte) string {
+	if quote == '\'' {
+		return "&#39;"
+	}
+	return "&quot;"
+}
+
+type htmlParser struct {
+	doc    *htmlDocument
+	z      *html.Tokenizer
+	offset int
+	// run 正在收集的文本与行内元素
+	run     *htmlRun
+	builder *inlineBuilder
+}
+
+// htmlToken 一个节点及其在原文中的区间
+type htmlToken struct {
+	typ  html.TokenType
+	atom atom.Atom
+	name string
+	raw  []byte
+	span
+}
+
+func (p *htmlParser) next() (htmlToken, error) {
+	typ := p.z.Next()
+	if typ == html.ErrorToken {
+		return htmlToken{}, p.z.Err()
+	}
+	// Raw 返回的内容在下一次 Next 后失效，改为引用原文
+	start := p.offset
+	p.offset += len(p.z.Raw())
+	tok := htmlToken{typ: typ, raw: p.doc.data[start:p.offset], span: span{start, p.offset}}
+	if typ == html.StartTagToken || typ == html.EndTagToken || typ == html.SelfClosingTagToken {
+		name, _ := p.z.TagName()
+		tok.name = string(name)
+		tok.atom = atom.Lookup(name)
+	}
+	return tok, nil
+}
+
+func (p *htmlParser) parse() error {
+	for {
+		tok, err := p.next()
+		if err == io.EOF {
+			p.flush()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch tok.typ {
+		case html.TextToken:
+			p.text(tok)
+		case html.CommentToken:
+			p.protect(tok.span)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if err = p.startTag(tok); err != nil {
+				return err
+			}
+		case html.EndTagToken:
+			if htmlInline[tok.atom] {
+				p.protect(tok.span)
+				continue
+			}
+			p.flush()
+		default:
+			p.flush()
+		}
+	}
+}
+
+func (p *htmlParser) startTag(tok htmlToken) error {
+	attrs := scanHTMLAttrs(tok.raw)
+	inline := htmlInline[tok.atom]
+	if skipHTMLTag(tok, attrs) {
+		end := tok.end
+		if tok.typ == html.StartTagToken && !htmlVoid[tok.atom] {
+			var err error
+			if end, err = p.skip(tok.name); err != nil {
+				return err
+			}
+		}
+		if inline {
+			p.protect(span{tok.start, end})
+		} else {
+			p.flush()
+		}
+		return nil
+	}
+	if !inline {
+		p.flush()
+	}
+	if tok.atom == atom.Html && p.doc.langTag == nil {
+		tag := tok.span
+		p.doc.langTag = &tag
+	}
+	for _, attr := range attrs {
+		if !containsFold(htmlAttributes, attr.name) {
+			continue
+		}
+		value := html.UnescapeString(string(tok.raw[attr.value.start:attr.value.end]))
+		if !translatable(value) {
+			continue
+		}
+		s := span{tok.start + attr.value.start, tok.start + attr.value.end}
+		if attr.quote != 0 {
+			s = span{s.start - 1, s.end + 1}
+		}
+		p.doc.attrs = append(p.doc.attrs, &htmlAttrValue{span: s, quote: attr.quote, unit: len(p.doc.units)})
+		p.addUnit(strings.TrimSpace(value))
+	}
+	if inline {
+		p.protect(tok.span)
+	}
+	return nil
+}
+
+// skip 跳过元素的内容，返回结束标签之后的位置，没有结束标签时到文档末尾
+func (p *htmlParser) skip(name string) (int, error) {
+	depth := 0
+	for {
+		tok, err := p.next()
+		if err == io.EOF {
+			return p.offset, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if tok.name != name {
+			continue
+		}
+		switch tok.typ {
+		case html.StartTagToken:
+			depth++
+		case html.EndTagToken:
+			if depth == 0 {
+				return tok.end, nil
+			}
+			depth--
+		}
+	}
+}
+
+func (p *htmlParser) begin(start int) {
+	if p.run == nil {
+		p.run = &htmlRun{span: span{start, start}}
+		p.builder = &inlineBuilder{}
+	}
+}
+
+func (p *htmlParser) text(tok htmlToken) {
+	p.begin(tok.start)
+	p.builder.text(html.UnescapeString(string(tok.raw)))
+	p.run.end = tok.end
+}
+
+// protect 行内元素的开始与结束标签各为一个占位元素，不翻译的元素与注释整体为一个占位元素
+func (p *htmlParser) protect(s span) {
+	p.begin(s.start)
+	p.builder.protect(string(p.doc.data[s.start:s.end]))
+	p.run.tokens = append(p.run.tokens, s)
+	p.run.end = s.end
+}
+
+// flush 结束当前的文本，去掉占位元素后没有文字时原样保留
+func (p *htmlParser) flush() {
+	run, b := p.run, p.builder
+	p.run, p.builder = nil, nil
+	if run == nil {
+		return
+	}
+	inline := b.done()
+	if !inline.translatable() {
+		return
+	}
+	text := strings.TrimSpace(inline.Text)
+	run.leading = inline.Text[:strings.Index(inline.Text, text)]
+	run.trailing = inline.Text[len(run.leading)+len(text):]
+	run.unit = len(p.doc.units)
+	p.doc.runs = append(p.doc.runs, run)
+	p.addUnit(text)
+}
+
+func (p *htmlParser) addUnit(text string) {
+	var context string
+	if n := len(p.doc.units); n > 0 {
+		context = tailRunes(p.doc.units[n-1].Text, markdownContextRunes)
+	}
+	p.doc.units = append(p.doc.units, Unit{Text: text, Context: context})
+}
+
+func skipHTMLTag(tok htmlToken, attrs []htmlAttrSpan) bool {
+	if htmlSkipped[tok.atom] {
+		return true
+	}
+	for _, attr := range attrs {
+		if attr.name == "translate" && strings.EqualFold(strings.TrimSpace(string(tok.raw[attr.value.start:attr.value.end])), "no") {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlAttrSpan 开始标签中的属性，value 为属性值相对于标签的区间，不包含引号
+type htmlAttrSpan struct {
+	name  string
+	value span
+	quote byte
+}
+
+// scanHTMLAttrs 按 HTML 的规则扫描开始标签中的属性，保留各属性值的位置
+func scanHTMLAttrs(tag []byte) []htmlAttrSpan {
+	var attrs []htmlAttrSpan
+	i := 1
+	for i < len(tag) && !isHTMLSpace(tag[i]) && tag[i] != '>' && tag[i] != '/' {
+		i++
+	}
+	for i < len(tag) {
+		for i < len(tag) && (isHTMLSpace(tag[i]) || tag[i] == '/') {
+			i++
+		}
+		if i >= len(tag) || tag[i] == '>' {
+			break
+		}
+		start := i
+		for i < len(tag) && !isHTMLSpace(tag[i]) && tag[i] != '=' && tag[i] != '>' && tag[i] != '/' {
+			i++
+		}
+		attr := htmlAttrSpan{name: strings.ToLower(string(tag[start:i])), value: span{i, i}}
+		j := i
+		for j < len(tag) && isHTMLSpace(tag[j]) {
+			j++
+		}
+		if j < len(tag) && tag[j] == '=' {
+			j++
+			for j < len(tag) && isHTMLSpace(tag[j]) {
+				j++
+			}
+			if j < len(tag) && (tag[j] == '"' || tag[j] == '\'') {
+				attr.quote = tag[j]
+				end := bytes.IndexByte(tag[j+1:], tag[j])
+				if end < 0 {
+					end = len(tag) - j - 1
+				}
+				attr.value = span{j + 1, j + 1 + end}
+				i = min(j+2+end, len(tag))
+			} else {
+				k := j
+				for k < len(tag) && !isHTMLSpace(tag[k]) && tag[k] != '>' {
+					k++
+				}
+				attr.value = span{j, k}
+				i = k
+			}
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
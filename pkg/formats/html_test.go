@@ -0,0 +1,89 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{"document", "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n  <meta charset=\"utf-8\">\n  <title>Home page</title>\n</head>\n" +
+			"<body>\n  <p class=intro>Hello <b>dear</b> reader,<br>welcome!</p>\n  <img src=\"a.png\" alt=\"A cat\">\n</body>\n</html>\n"},
+		{"fragment", "<p>Dear {{name}},</p>\n<p>Your order <a href='/orders/1' title='Order details'>#1</a> has shipped.</p>"},
+		{"crlf", "<ul>\r\n  <li>First item</li>\r\n  <li>Second &amp; last item</li>\r\n</ul>\r\n"},
+		{"skipped", "<div>\n<script>var s = \"Do not translate\";</script>\n<style>p { color: red }</style>\n" +
+			"<pre>  keep   spacing  </pre>\n<p translate=\"no\">Brand Name</p>\n<p>Run <code>make all</code> now</p>\n</div>"},
+		{"attributes", "<form><input type=text placeholder=\"Your name\" aria-label='Name' disabled>" +
+			"<button title=\"Send the form\">Send</button></form>"},
+		{"comments and entities", "<p>Caf&eacute; &nbsp;<!-- note --> open&#33;</p><hr/><p>Self closing <br/> tag</p>"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, out := roundTrip(t, FormatHTML, c.data, Options{}, "")
+			if out != c.data {
+				t.Errorf("round trip changed the document\n got: %q\nwant: %q", out, c.data)
+			}
+		})
+	}
+}
+
+func TestHTMLUnits(t *testing.T) {
+	data := "<html lang=\"en\"><head><title>Home</title></head><body>" +
+		"<p>Hello <b title=\"Bold text\">dear</b> reader,<br>welcome!</p>" +
+		"<p translate=\"no\">Brand</p><p>Run <code>make</code></p><script>alert('x')</script>" +
+		"<img src=\"a.png\" alt=\"A cat\"><input placeholder=\"Search\"></body></html>"
+	doc, _ := roundTrip(t, FormatHTML, data, Options{}, "")
+	want := []string{
+		"Home",
+		"Bold text",
+		`Hello <x id="1"/>dear<x id="2"/> reader,<x id="3"/>welcome!`,
+		`Run <x id="1"/>`,
+		"A cat",
+		"Search",
+	}
+	if got := unitTexts(doc); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("units = %q\nwant %q", got, want)
+	}
+}
+
+func TestHTMLRender(t *testing.T) {
+	data := "<!DOCTYPE html>\n<html lang=\"en\">\n<body>\n  <p>Hello <b title=\"Bold text\">dear</b> reader,<br>welcome!</p>\n" +
+		"  <img src=\"a.png\" alt='A cat'>\n</body>\n</html>\n"
+	handler, _ := Get(FormatHTML)
+	doc, err := handler.Parse([]byte(data), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := doc.Render([]string{
+		"Texte <gras>",
+		`<x id="3"/>Bonjour <x id="1"/>cher<x id="2"/> lecteur & bienvenue`,
+		"Un chat 'noir'",
+	}, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<!DOCTYPE html>\n<html lang=\"fr\">\n<body>\n  <p><br>Bonjour <b title=\"Texte <gras>\">cher</b> lecteur &amp; bienvenue</p>\n" +
+		"  <img src=\"a.png\" alt='Un chat &#39;noir&#39;'>\n</body>\n</html>\n"
+	if string(out) != want {
+		t.Errorf("render = %q\nwant %q", out, want)
+	}
+}
+
+func TestHTMLLang(t *testing.T) {
+	cases := []struct {
+		data, lang, want string
+	}{
+		{"<html><body><p>Hi there</p></body></html>", "de", "<html lang=\"de\"><body><p>Hi there</p></body></html>"},
+		{"<html lang=en><p>Hi there</p></html>", "de", "<html lang=\"de\"><p>Hi there</p></html>"},
+		{"<html lang='en'><p>Hi there</p></html>", "en", "<html lang='en'><p>Hi there</p></html>"},
+		{"<p>Fragment text</p>", "de", "<p>Fragment text</p>"},
+	}
+	for _, c := range cases {
+		if _, out := roundTrip(t, FormatHTML, c.data, Options{}, c.lang); out != c.want {
+			t.Errorf("lang %s: got %q, want %q", c.lang, out, c.want)
+		}
+	}
+}
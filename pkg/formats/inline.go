@@ -0,0 +1,68 @@
+package formats
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 行内不需要翻译的片段(代码、链接地址、标签等)替换为 <x id="n"/>，与 XLIFF 的行内占位元素写法相同，
+// 服务端的占位符保护会把它当作标签处理，生成译文时再还原为原文片段
+
+var inlinePlaceholderPattern = regexp.MustCompile(`(?i)<x\s+id\s*=\s*["']?(\d+)["']?\s*/?>`)
+
+// inlineText 替换后的文本，Text 中第 i 个片段以 <x id="i+1"/> 表示
+type inlineText struct {
+	Text      string
+	Fragments []string
+}
+
+type inlineBuilder struct {
+	buf       strings.Builder
+	fragments []string
+}
+
+func (b *inlineBuilder) text(s string) {
+	b.buf.WriteString(s)
+}
+
+func (b *inlineBuilder) protect(s string) {
+	b.fragments = append(b.fragments, s)
+	fmt.Fprintf(&b.buf, `<x id="%d"/>`, len(b.fragments))
+}
+
+func (b *inlineBuilder) done() inlineText {
+	return inlineText{Text: b.buf.String(), Fragments: b.fragments}
+}
+
+// translatable 去掉占位片段后仍有需要翻译的文字
+func (t inlineText) translatable() bool {
+	return translatable(inlinePlaceholderPattern.ReplaceAllString(t.Text, " "))
+}
+
+// restore 把译文中的占位元素还原为原文片段，无法识别的保留原样
+func (t inlineText) restore(translation string) string {
+	return t.restoreEscaped(translation, nil)
+}
+
+// restoreEscaped 与 restore 相同，占位元素以外的文字用 escape 转义，例如写回 XML 时
+func (t inlineText) restoreEscaped(translation string, escape func(string) string) string {
+	if escape == nil {
+		escape = func(s string) string { return s }
+	}
+	var out strings.Builder
+	last := 0
+	for _, loc := range inlinePlaceholderPattern.FindAllStringSubmatchIndex(translation, -1) {
+		out.WriteString(escape(translation[last:loc[0]]))
+		last = loc[1]
+		i, err := strconv.Atoi(translation[loc[2]:loc[3]])
+		if err != nil || i < 1 || i > len(t.Fragments) {
+			out.WriteString(escape(translation[loc[0]:loc[1]]))
+			continue
+		}
+		out.WriteString(t.Fragments[i-1])
+	}
+	out.WriteString(escape(translation[last:]))
+	return out.String()
+}
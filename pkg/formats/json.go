@@ -0,0 +1,139 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// jsonHandler 翻译所有字符串值，键名、数字与其它字面量不变，译文按原文的位置原样替换，保留缩进与键的顺序
+type jsonHandler struct{}
+
+func (h *jsonHandler) Name() string {
+	return FormatJSON
+}
+
+func (h *jsonHandler) Extensions() []string {
+	return []string{".json"}
+}
+
+func (h *jsonHandler) Parse(data []byte, opt Options) (IDocument, error) {
+	if !json.Valid(data) {
+		return nil, errors.New("formats: invalid json")
+	}
+	doc := &jsonDocument{data: data}
+	for _, lit := range scanJSONStrings(data) {
+		var value string
+		if err := json.Unmarshal(data[lit.start:lit.end], &value); err != nil {
+			return nil, err
+		}
+		if !translatable(value) {
+			continue
+		}
+		lit.value = value
+		doc.values = append(doc.values, lit)
+	}
+	return doc, nil
+}
+
+// jsonLiteral 字符串字面量在原文中的位置，包含引号
+type jsonLiteral struct {
+	start, end int
+	value      string
+}
+
+type jsonDocument struct {
+	data   []byte
+	values []jsonLiteral
+}
+
+func (d *jsonDocument) Units() []Unit {
+	units := make([]Unit, 0, len(d.values))
+	for _, v := range d.values {
+		units = append(units, Unit{Text: v.value})
+	}
+	return units
+}
+
+func (d *jsonDocument) Render(translations []string, targetLang string) ([]byte, error) {
+	if len(translations) != len(d.values) {
+		return nil, errUnitCount(len(d.values), len(translations))
+	}
+	var out bytes.Buffer
+	last := 0
+	for i, v := range d.values {
+		out.Write(d.data[last:v.start])
+		last = v.end
+		// 未改变的值保留原文的转义写法
+		if translations[i] == v.value {
+			out.Write(d.data[v.start:v.end])
+			continue
+		}
+		encoded, err := encodeJSONString(translations[i])
+		if err != nil {
+			return nil, err
+		}
+		out.Write(encoded)
+	}
+	out.Write(d.data[last:])
+	return out.Bytes(), nil
+}
+
+// scanJSONStrings 返回合法 JSON 中所有作为值的字符串，对象的键名不返回
+func scanJSONStrings(data []byte) []jsonLiteral {
+	var (
+		list      []jsonLiteral
+		stack     []byte
+		expectKey bool
+	)
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '{', '[':
+			stack = append(stack, data[i])
+			expectKey = data[i] == '{'
+		case '}', ']':
+			stack = stack[:len(stack)-1]
+			expectKey = false
+		case ',':
+			expectKey = len(stack) > 0 && stack[len(stack)-1] == '{'
+		case ':':
+			expectKey = false
+		case '"':
+			end := i + 1
+			for ; data[end] != '"'; end++ {
+				if data[end] == '\\' {
+					end++
+				}
+			}
+			if !expectKey {
+				list = append(list, jsonLiteral{start: i, end: end + 1})
+			}
+			i = end
+		}
+	}
+	return list
+}
+
+// translatable 跳过空白、不含文字的值(如数字、颜色)与链接
+func translatable(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || hexColorPattern.MatchString(s) {
+		return false
+	}
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+func encodeJSONString(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,55 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{"nested", `{"menu": {"save": "Save", "items": ["Open file", {"label": "Close"}]}, "count": 3}`},
+		{"escapes", `{"greeting": "Café \"open\"", "path": "a\/b", "html": "<b>Bold</b>\ttab"}`},
+		{"crlf and indent", "{\r\n    \"title\": \"Welcome\",\r\n    \"empty\": \"\"\r\n}\r\n"},
+		{"array root", `["First", "Second", 1, true, null]`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, out := roundTrip(t, FormatJSON, c.data, Options{}, "fr")
+			if out != c.data {
+				t.Errorf("round trip changed the document\n got: %q\nwant: %q", out, c.data)
+			}
+		})
+	}
+}
+
+func TestJSONUnits(t *testing.T) {
+	data := `{"title": "Welcome", "nested": {"title": "Inner title", "list": ["One", "https://example.com", "#fff", "42", "  "]},` +
+		` "key only": {"label": "Label"}, "escaped": "Café"}`
+	doc, _ := roundTrip(t, FormatJSON, data, Options{}, "fr")
+	want := []string{"Welcome", "Inner title", "One", "Label", "Café"}
+	if got := unitTexts(doc); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("units = %q, want %q", got, want)
+	}
+}
+
+func TestJSONRender(t *testing.T) {
+	handler, _ := Get(FormatJSON)
+	doc, err := handler.Parse([]byte("{\n  \"title\": \"Welcome\",\n  \"items\": [\"Open\"]\n}"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := doc.Render([]string{"Bienvenue \"chez nous\" <3", "Ouvrir"}, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"title\": \"Bienvenue \\\"chez nous\\\" <3\",\n  \"items\": [\"Ouvrir\"]\n}"
+	if string(out) != want {
+		t.Errorf("render = %q, want %q", out, want)
+	}
+	if _, err = handler.Parse([]byte(`{"a": }`), Options{}); err == nil {
+		t.Error("invalid json accepted")
+	}
+}
@@ -0,0 +1,703 @@
+package formats
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatterKeys 需要翻译的 front matter 顶层字段，其余字段原样保留
+var FrontMatterKeys = []string{"title", "description", "summary", "subtitle", "excerpt"}
+
+// markdownContextRunes 提供给模型的相邻原文长度
+const markdownContextRunes = 200
+
+var (
+	fencePattern       = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	headingPattern     = regexp.MustCompile(`^( {0,3}#{1,6})(?:([ \t]+)(.*?))??([ \t]+#+)?[ \t]*$`)
+	setextPattern      = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	thematicPattern    = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	quotePattern       = regexp.MustCompile(`^ {0,3}> ?`)
+	listItemPattern    = regexp.MustCompile(`^ {0,3}(?:[-*+]|\d{1,9}[.)])(?:[ \t]+|$)`)
+	taskBoxPattern     = regexp.MustCompile(`^\[[ xX]\][ \t]+`)
+	linkDefPattern     = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:[ \t]*\S`)
+	htmlBlockPattern   = regexp.MustCompile(`^ {0,3}(?:<!--|<\?|<![A-Z]|</?(?i:address|article|aside|blockquote|body|details|dialog|div|dl|fieldset|figcaption|figure|footer|form|h[1-6]|header|hr|iframe|li|main|nav|ol|p|pre|script|section|style|summary|table|tbody|td|tfoot|th|thead|tr|ul)(?:[\s/>]|$))`)
+	htmlTagLinePattern = regexp.MustCompile(`^ {0,3}</?[A-Za-z][\w-]*(?:\s[^<>]*)?/?>[ \t]*$`)
+	delimiterPattern   = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	frontMatterPattern = regexp.MustCompile(`^([A-Za-z_][\w-]*)([ \t]*:[ \t]*)(.*?)[ \t]*$`)
+
+	autolinkPattern   = regexp.MustCompile(`^<(?:[A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*|[^\s@<>]+@[^\s@<>]+)>`)
+	inlineHTMLPattern = regexp.MustCompile(`^(?:<!--[\s\S]*?-->|</?[A-Za-z][\w:-]*(?:\s+[^<>]*?)?\s*/?>)`)
+	footnotePattern   = regexp.MustCompile(`^\[\^[^\]\s]+\]`)
+	bareURLPattern    = regexp.MustCompile(`^https?://[^\s<>()\[\]]*[^\s<>()\[\].,;:!?'"]`)
+)
+
+// markdownHandler 只翻译标题、段落、列表项、表格单元格、图片说明与 front matter 中的指定字段，
+// 代码块、HTML 块、链接地址与行内代码原样保留，译文按原文的结构重新生成
+type markdownHandler struct{}
+
+func (h *markdownHandler) Name() string {
+	return FormatMarkdown
+}
+
+func (h *markdownHandler) Extensions() []string {
+	return []string{".md", ".markdown"}
+}
+
+func (h *markdownHandler) Parse(data []byte, opt Options) (IDocument, error) {
+	content := string(data)
+	doc := &markdownDocument{crlf: strings.Contains(content, "\r\n")}
+	if doc.crlf {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	lines := strings.Split(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		doc.noTrailingNewline = true
+	}
+	p := &mdParser{doc: doc}
+	start := p.frontMatter(lines)
+	doc.blocks = append(doc.blocks, p.parse(lines[start:])...)
+	return doc, nil
+}
+
+type markdownDocument struct {
+	blocks            []mdBlock
+	units             []Unit
+	inline            []inlineText
+	crlf              bool
+	noTrailingNewline bool
+}
+
+func (d *markdownDocument) Units() []Unit {
+	return d.units
+}
+
+func (d *markdownDocument) Render(translations []string, targetLang string) ([]byte, error) {
+	if len(translations) != len(d.units) {
+		return nil, errUnitCount(len(d.units), len(translations))
+	}
+	var b strings.Builder
+	renderBlocks(&b, d.blocks, d, translations)
+	out := b.String()
+	if d.noTrailingNewline {
+		out = strings.TrimSuffix(out, "\n")
+	}
+	if d.crlf {
+		out = strings.ReplaceAll(out, "\n", "\r\n")
+	}
+	return []byte(out), nil
+}
+
+// translation 还原行内片段后的译文
+func (d *markdownDocument) translation(translations []string, i int) string {
+	return d.inline[i].restore(translations[i])
+}
+
+// mdBlock 文档结构中的一部分，原样保留的文本、需要翻译的文本或带有行前缀的容器
+type mdBlock interface {
+	render(b *strings.Builder, d *markdownDocument, translations []string)
+}
+
+func renderBlocks(b *strings.Builder, blocks []mdBlock, d *markdownDocument, translations []string) {
+	for _, block := range blocks {
+		block.render(b, d, translations)
+	}
+}
+
+// mdRaw 原样保留的文本
+type mdRaw string
+
+func (r mdRaw) render(b *strings.Builder, _ *markdownDocument, _ []string) {
+	b.WriteString(string(r))
+}
+
+// mdText 需要翻译的文本，singleLine 为 true 时(标题、单元格)译文中的换行替换为空格
+type mdText struct {
+	unit       int
+	singleLine bool
+}
+
+func (t mdText) render(b *strings.Builder, d *markdownDocument, translations []string) {
+	text := strings.TrimSpace(d.translation(translations, t.unit))
+	if t.singleLine {
+		b.WriteString(strings.Join(strings.Fields(text), " "))
+		return
+	}
+	// 段落中的空行会把段落拆开，行首缩进可能变成代码块，都需要去掉
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimLeft(line, " \t"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	b.WriteString(strings.Join(lines, "\n"))
+}
+
+// mdFrontValue front matter 中需要翻译的字段值，quote 为原文使用的引号
+type mdFrontValue struct {
+	unit  int
+	quote byte
+}
+
+func (v mdFrontValue) render(b *strings.Builder, d *markdownDocument, translations []string) {
+	text := strings.Join(strings.Fields(d.translation(translations, v.unit)), " ")
+	switch {
+	case v.quote == '\'':
+		b.WriteString("'" + strings.ReplaceAll(text, "'", "''") + "'")
+	case v.quote == '"' || !plainYAMLScalar(text):
+		// JSON 字符串同时也是合法的 YAML 双引号字符串
+		encoded, err := encodeJSONString(text)
+		if err != nil {
+			b.WriteString(text)
+			return
+		}
+		b.Write(encoded)
+	default:
+		b.WriteString(text)
+	}
+}
+
+// plainYAMLScalar 不加引号时能否解析回原来的字符串
+func plainYAMLScalar(s string) bool {
+	var value any
+	if err := yaml.Unmarshal([]byte(s), &value); err != nil {
+		return false
+	}
+	parsed, ok := value.(string)
+	return ok && parsed == s
+}
+
+// mdContainer 引用块与列表项，子结构生成后第一行加上 first，其余各行加上 rest
+type mdContainer struct {
+	first, rest string
+	children    []mdBlock
+}
+
+func (c *mdContainer) render(b *strings.Builder, d *markdownDocument, translations []string) {
+	var inner strings.Builder
+	renderBlocks(&inner, c.children, d, translations)
+	for i, line := range strings.SplitAfter(inner.String(), "\n") {
+		if line == "" {
+			continue
+		}
+		prefix := c.rest
+		if i == 0 {
+			prefix = c.first
+		}
+		if strings.TrimSpace(line) == "" {
+			prefix = strings.TrimRight(prefix, " \t")
+		}
+		b.WriteString(prefix + line)
+	}
+}
+
+type mdParser struct {
+	doc *markdownDocument
+}
+
+// text 需要翻译的文本，行内片段替换后没有文字时原样保留
+func (p *mdParser) text(s string, singleLine bool) mdBlock {
+	unit, ok := p.unit(s)
+	if !ok {
+		return mdRaw(s)
+	}
+	return mdText{unit: unit, singleLine: singleLine}
+}
+
+func (p *mdParser) unit(s string) (int, bool) {
+	inline := maskMarkdownInline(s)
+	if !inline.translatable() {
+		return 0, false
+	}
+	var context string
+	if n := len(p.doc.units); n > 0 {
+		context = tailRunes(p.doc.units[n-1].Text, markdownContextRunes)
+	}
+	p.doc.units = append(p.doc.units, Unit{Text: inline.Text, Context: context})
+	p.doc.inline = append(p.doc.inline, inline)
+	return len(p.doc.units) - 1, true
+}
+
+// frontMatter 解析文档开头 --- 包围的 YAML，返回正文开始的行
+func (p *mdParser) frontMatter(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " \t") != "---" {
+		return 0
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimRight(lines[i], " \t"); line == "---" || line == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return 0
+	}
+	p.doc.blocks = append(p.doc.blocks, mdRaw(lines[0]+"\n"))
+	for _, line := range lines[1:end] {
+		p.doc.blocks = append(p.doc.blocks, p.frontMatterLine(line)...)
+	}
+	p.doc.blocks = append(p.doc.blocks, mdRaw(lines[end]+"\n"))
+	return end + 1
+}
+
+// frontMatterLine 只处理顶层字段的单行字符串值，块标量、列表与对象原样保留
+func (p *mdParser) frontMatterLine(line string) []mdBlock {
+	raw := []mdBlock{mdRaw(line + "\n")}
+	match := frontMatterPattern.FindStringSubmatch(line)
+	if match == nil || !containsFold(FrontMatterKeys, match[1]) || match[3] == "" {
+		return raw
+	}
+	value, comment := match[3], ""
+	quote := value[0]
+	switch quote {
+	case '"', '\'':
+		end := quotedScalarEnd(value)
+		if end < 0 {
+			return raw
+		}
+		// 引号之后只能是注释
+		if rest := value[end:]; strings.TrimSpace(rest) != "" {
+			if !strings.HasPrefix(strings.TrimLeft(rest, " \t"), "#") || rest[0] != ' ' && rest[0] != '\t' {
+				return raw
+			}
+			value, comment = value[:end], rest
+		}
+	case '|', '>', '[', '{', '&', '*', '!', '#':
+		return raw
+	default:
+		quote = 0
+		if i := strings.Index(value, " #"); i >= 0 {
+			value, comment = strings.TrimRight(value[:i], " \t"), value[i:]
+		}
+	}
+	var text string
+	if err := yaml.Unmarshal([]byte(value), &text); err != nil {
+		return raw
+	}
+	unit, ok := p.unit(text)
+	if !ok {
+		return raw
+	}
+	return []mdBlock{
+		mdRaw(match[1] + match[2]),
+		mdFrontValue{unit: unit, quote: quote},
+		mdRaw(comment + "\n"),
+	}
+}
+
+// quotedScalarEnd 返回以引号开头的 YAML 标量结束引号之后的位置，没有结束引号时返回 -1
+func quotedScalarEnd(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] != quote:
+		case quote == '\'' && i+1 < len(value) && value[i+1] == '\'':
+			i++
+		default:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func (p *mdParser) parse(lines []string) []mdBlock {
+	var blocks []mdBlock
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			blocks = append(blocks, mdRaw(line+"\n"))
+			i++
+		case fencePattern.MatchString(line):
+			end := fenceEnd(lines, i)
+			blocks = append(blocks, rawLines(lines[i:end]))
+			i = end
+		case indentWidth(line) >= 4:
+			end := i + 1
+			for j := end; j < len(lines); j++ {
+				if strings.TrimSpace(lines[j]) == "" {
+					continue
+				}
+				if indentWidth(lines[j]) < 4 {
+					break
+				}
+				end = j + 1
+			}
+			blocks = append(blocks, rawLines(lines[i:end]))
+			i = end
+		case thematicPattern.MatchString(line), linkDefPattern.MatchString(line):
+			blocks = append(blocks, mdRaw(line+"\n"))
+			i++
+		case isHTMLBlockStart(line):
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			blocks = append(blocks, rawLines(lines[i:end]))
+			i = end
+		case headingPattern.MatchString(line):
+			blocks = append(blocks, p.heading(line)...)
+			i++
+		case quotePattern.MatchString(line):
+			var inner []string
+			for i < len(lines) && quotePattern.MatchString(lines[i]) {
+				inner = append(inner, quotePattern.ReplaceAllString(lines[i], ""))
+				i++
+			}
+			blocks = append(blocks, &mdContainer{first: "> ", rest: "> ", children: p.parse(inner)})
+		case listItemPattern.MatchString(line):
+			var block mdBlock
+			block, i = p.listItem(lines, i)
+			blocks = append(blocks, block)
+		case i+1 < len(lines) && strings.Contains(line, "|") && delimiterPattern.MatchString(lines[i+1]):
+			var table []mdBlock
+			table, i = p.table(lines, i)
+			blocks = append(blocks, table...)
+		default:
+			var paragraph []mdBlock
+			paragraph, i = p.paragraph(lines, i)
+			blocks = append(blocks, paragraph...)
+		}
+	}
+	return blocks
+}
+
+func (p *mdParser) heading(line string) []mdBlock {
+	match := headingPattern.FindStringSubmatch(line)
+	if match[3] == "" {
+		return []mdBlock{mdRaw(line + "\n")}
+	}
+	return []mdBlock{
+		mdRaw(match[1] + match[2]),
+		p.text(match[3], true),
+		mdRaw(match[4] + "\n"),
+	}
+}
+
+// listItem 列表项的后续行按内容的缩进去掉前缀后作为子结构解析，返回下一个列表项或其它结构开始的行
+func (p *mdParser) listItem(lines []string, i int) (mdBlock, int) {
+	line := lines[i]
+	marker := listItemPattern.FindString(line)
+	// 标记后超过 4 个空格时内容为缩进代码块，内容从标记后一个空格开始
+	bullet := strings.TrimRight(marker, " \t")
+	width := len(marker)
+	if strings.Contains(marker, "\t") || width-len(bullet) > 4 || len(marker) == len(bullet) {
+		width = len(bullet) + 1
+		marker = line[:min(len(line), width)]
+	}
+	first := marker
+	content := line[len(marker):]
+	if box := taskBoxPattern.FindString(content); box != "" {
+		first += box
+		content = content[len(box):]
+	}
+	inner := []string{content}
+	end := i + 1
+	lazy := strings.TrimSpace(content) != ""
+	for j := i + 1; j < len(lines); j++ {
+		next := lines[j]
+		switch {
+		case strings.TrimSpace(next) == "":
+			inner = append(inner, "")
+			lazy = false
+			continue
+		case indentWidth(next) >= width:
+			inner = append(inner, dedent(next, width))
+		case lazy && !isBlockStart(next):
+			inner = append(inner, strings.TrimLeft(next, " \t"))
+		default:
+			return p.listContainer(first, width, inner[:end-i]), end
+		}
+		lazy = true
+		end = j + 1
+	}
+	return p.listContainer(first, width, inner[:end-i]), end
+}
+
+func (p *mdParser) listContainer(first string, width int, inner []string) mdBlock {
+	return &mdContainer{first: first, rest: strings.Repeat(" ", width), children: p.parse(inner)}
+}
+
+// table 逐个单元格翻译，分隔行与单元格两侧的空白原样保留
+func (p *mdParser) table(lines []string, i int) ([]mdBlock, int) {
+	blocks := p.tableRow(lines[i])
+	blocks = append(blocks, mdRaw(lines[i+1]+"\n"))
+	i += 2
+	for i < len(lines) && strings.Contains(lines[i], "|") && !isBlockStart(lines[i]) {
+		blocks = append(blocks, p.tableRow(lines[i])...)
+		i++
+	}
+	return blocks, i
+}
+
+func (p *mdParser) tableRow(line string) []mdBlock {
+	var blocks []mdBlock
+	cell := func(s string) {
+		trimmed := strings.TrimSpace(s)
+		if trimmed == "" {
+			blocks = append(blocks, mdRaw(s))
+			return
+		}
+		start := strings.Index(s, trimmed)
+		blocks = append(blocks, mdRaw(s[:start]), p.text(trimmed, true), mdRaw(s[start+len(trimmed):]))
+	}
+	last := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '`':
+			if end := codeSpanEnd(line, i); end > 0 {
+				i = end - 1
+			}
+		case '|':
+			cell(line[last:i])
+			blocks = append(blocks, mdRaw("|"))
+			last = i + 1
+		}
+	}
+	cell(line[last:])
+	return append(blocks, mdRaw("\n"))
+}
+
+// paragraph 连续的文本行作为一个单元，下一行为 === 或 --- 时为标题
+func (p *mdParser) paragraph(lines []string, i int) ([]mdBlock, int) {
+	var text []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if len(text) > 0 && setextPattern.MatchString(line) {
+			return []mdBlock{p.text(strings.Join(text, "\n"), true), mdRaw("\n" + line + "\n")}, i + 1
+		}
+		if len(text) > 0 && isBlockStart(line) {
+			break
+		}
+		text = append(text, strings.TrimLeft(line, " \t"))
+	}
+	last := len(text) - 1
+	text[last] = strings.TrimRight(text[last], " \t")
+	return []mdBlock{p.text(strings.Join(text, "\n"), false), mdRaw("\n")}, i
+}
+
+// isBlockStart 可以打断段落的结构
+func isBlockStart(line string) bool {
+	return strings.TrimSpace(line) == "" || fencePattern.MatchString(line) || thematicPattern.MatchString(line) ||
+		headingPattern.MatchString(line) || quotePattern.MatchString(line) ||
+		listItemPattern.MatchString(line) || isHTMLBlockStart(line)
+}
+
+func isHTMLBlockStart(line string) bool {
+	return htmlBlockPattern.MatchString(line) || htmlTagLinePattern.MatchString(line)
+}
+
+// fenceEnd 返回代码块结束后的下一行，没有结束标记时代码块到文档末尾
+func fenceEnd(lines []string, i int) int {
+	fence := fencePattern.FindStringSubmatch(lines[i])[1]
+	for j := i + 1; j < len(lines); j++ {
+		line := strings.TrimSpace(lines[j])
+		if indentWidth(lines[j]) < 4 && strings.HasPrefix(line, fence) &&
+			strings.Trim(line, fence[:1]) == "" {
+			return j + 1
+		}
+	}
+	return len(lines)
+}
+
+func rawLines(lines []string) mdRaw {
+	return mdRaw(strings.Join(lines, "\n") + "\n")
+}
+
+// indentWidth 行首空白的列数，制表符按 4 列计算
+func indentWidth(line string) int {
+	width := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// dedent 去掉行首 width 列空白
+func dedent(line string, width int) string {
+	col := 0
+	for i, c := range line {
+		if col >= width || (c != ' ' && c != '\t') {
+			return strings.Repeat(" ", col-width) + line[i:]
+		}
+		if c == '\t' {
+			col += 4 - col%4
+		} else {
+			col++
+		}
+	}
+	return ""
+}
+
+// codeSpanEnd 返回从 s[i] 开始的行内代码结束后的位置，没有配对的反引号时返回 -1
+func codeSpanEnd(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	for j := i + n; j < len(s); {
+		k := strings.IndexByte(s[j:], '`')
+		if k < 0 {
+			return -1
+		}
+		j += k
+		m := 0
+		for j+m < len(s) && s[j+m] == '`' {
+			m++
+		}
+		if m == n {
+			return j + m
+		}
+		j += m
+	}
+	return -1
+}
+
+// maskMarkdownInline 替换行内代码、链接与图片地址、自动链接、HTML 标签、脚注引用与裸链接，链接文字仍然翻译
+func maskMarkdownInline(s string) inlineText {
+	b := &inlineBuilder{}
+	scanMarkdownInline(b, s)
+	return b.done()
+}
+
+func scanMarkdownInline(b *inlineBuilder, s string) {
+	last := 0
+	flush := func(i int) {
+		b.text(s[last:i])
+	}
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		n := 0
+		switch s[i] {
+		case '\\':
+			i += 2
+			continue
+		case '`':
+			if end := codeSpanEnd(s, i); end > 0 {
+				n = end - i
+			} else {
+				for n < len(rest) && rest[n] == '`' {
+					n++
+				}
+				i += n
+				continue
+			}
+		case '<':
+			if loc := autolinkPattern.FindStringIndex(rest); loc != nil {
+				n = loc[1]
+			} else if loc = inlineHTMLPattern.FindStringIndex(rest); loc != nil {
+				n = loc[1]
+			}
+		case '[':
+			if loc := footnotePattern.FindStringIndex(rest); loc != nil {
+				n = loc[1]
+				break
+			}
+			if text, tail := splitLink(rest); tail > 0 {
+				flush(i)
+				b.text("[")
+				scanMarkdownInline(b, rest[1:text])
+				b.protect(rest[text:tail])
+				i += tail
+				last = i
+				continue
+			}
+		case 'h':
+			if i == 0 || !isWordByte(s[i-1]) {
+				if loc := bareURLPattern.FindStringIndex(rest); loc != nil {
+					n = loc[1]
+				}
+			}
+		}
+		if n == 0 {
+			i++
+			continue
+		}
+		flush(i)
+		b.protect(rest[:n])
+		i += n
+		last = i
+	}
+	flush(len(s))
+}
+
+// splitLink 解析 [text](url)、[text][ref] 与 [text][]，返回链接文字结束的位置与整个链接结束的位置
+func splitLink(s string) (int, int) {
+	text := matchBracket(s, '[', ']')
+	if text < 0 || text+1 >= len(s) {
+		return 0, 0
+	}
+	switch s[text+1] {
+	case '(':
+		if end := matchBracket(s[text+1:], '(', ')'); end > 0 {
+			return text, text + 1 + end + 1
+		}
+	case '[':
+		if end := strings.IndexByte(s[text+1:], ']'); end > 0 {
+			return text, text + 1 + end + 1
+		}
+	}
+	return 0, 0
+}
+
+// matchBracket s 以 open 开头，返回与之配对的 close 的位置
+func matchBracket(s string, open, close byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			if end := codeSpanEnd(s, i); end > 0 {
+				i = end - 1
+			}
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isWordByte 链接前紧跟 ASCII 字母或数字时不是链接的开头，中文等后面可以直接跟链接
+func isWordByte(c byte) bool {
+	return c == '_' || c < utf8.RuneSelf && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)))
+}
+
+func tailRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[len(runes)-n:])
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
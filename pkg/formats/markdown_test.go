@@ -0,0 +1,78 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{"front matter", "---\ntitle: Getting started\ndescription: \"How to: install\" # shown in search\ntags: [go, docs]\nsummary: 'It''s quick'\nsubtitle: Quick start # plain comment\n---\n\n# Install\n"},
+		{"code fence", "Run this:\n\n```go\n// Hello is not translated\nfmt.Println(\"hi\")\n```\n\n~~~\nraw ~~~ text\n~~~\n"},
+		{"indented code", "Example:\n\n    go test ./...\n    go vet ./...\n\nDone.\n"},
+		{"crlf", "# Title\r\n\r\nFirst line\r\nsecond line\r\n\r\n- item one\r\n- item two\r\n"},
+		{"no trailing newline", "Just one paragraph"},
+		{"headings", "Intro\n=====\n\n## Closed heading ##\n\nSub\n---\n"},
+		{"lists and quotes", "1. First *step*\n2. Second step\n   continued here\n\n- [x] Done task\n- [ ] Open task\n\n> Quoted text\n> spans lines\n"},
+		{"inline", "Use `go run` with [the docs](https://go.dev/doc \"Go docs\") and <https://go.dev>.\n" +
+			"See ![a gopher](img/gopher.png) and [ref link][1] or a footnote[^1].\n\n[1]: https://example.com\n"},
+		{"table", "| Name | Description |\n|:-----|------------:|\n| `id` | Primary key |\n|  x  |   Some text  |\n"},
+		{"html block", "<div class=\"note\">\n  <p>Kept as is</p>\n</div>\n\nAfter <b>bold</b> text.\n"},
+		{"thematic break", "Above\n\n***\n\nBelow\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, out := roundTrip(t, FormatMarkdown, c.data, Options{}, "fr")
+			if out != c.data {
+				t.Errorf("round trip changed the document\n got: %q\nwant: %q", out, c.data)
+			}
+		})
+	}
+}
+
+func TestMarkdownUnits(t *testing.T) {
+	data := "---\ntitle: Getting started\nlayout: guide\n---\n\n# Install\n\nRun `go install` from [the site](https://go.dev).\n\n```sh\necho not translated\n```\n\n| Key | Meaning |\n|-----|---------|\n| 42 | Answer |\n"
+	doc, _ := roundTrip(t, FormatMarkdown, data, Options{}, "fr")
+	want := []string{
+		"Getting started",
+		"Install",
+		`Run <x id="1"/> from [the site<x id="2"/>.`,
+		"Key",
+		"Meaning",
+		"Answer",
+	}
+	got := unitTexts(doc)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("units = %q\nwant %q", got, want)
+	}
+	if doc.Units()[1].Context != "Getting started" {
+		t.Errorf("context = %q", doc.Units()[1].Context)
+	}
+}
+
+func TestMarkdownRender(t *testing.T) {
+	data := "---\ntitle: Getting started\n---\n\n# Install\n\nRun `go install` from [the site](https://go.dev).\r\n"
+	handler, _ := Get(FormatMarkdown)
+	doc, err := handler.Parse([]byte(strings.ReplaceAll(data, "\r\n", "\n")), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := doc.Render([]string{
+		"Premiers pas: guide",
+		"Installation",
+		"Exécutez <x id=\"1\"/> depuis\n\n  [le site<x id=\"2\"/>.",
+	}, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\ntitle: \"Premiers pas: guide\"\n---\n\n# Installation\n\nExécutez `go install` depuis\n[le site](https://go.dev).\n"
+	if string(out) != want {
+		t.Errorf("render = %q\nwant %q", out, want)
+	}
+	if _, err = doc.Render([]string{"one"}, "fr"); err == nil {
+		t.Error("unit count mismatch accepted")
+	}
+}
@@ -0,0 +1,158 @@
+package formats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pluralRule gettext 的复数规则，expr 写入 Plural-Forms，form 与 expr 的计算结果相同
+type pluralRule struct {
+	count int
+	expr  string
+	form  func(n int) int
+}
+
+var (
+	pluralOne = pluralRule{1, "0", func(n int) int { return 0 }}
+	// pluralTwo 大多数语言只区分单数与复数
+	pluralTwo       = pluralRule{2, "(n != 1)", func(n int) int { return b2i(n != 1) }}
+	pluralTwoFrench = pluralRule{2, "(n > 1)", func(n int) int { return b2i(n > 1) }}
+	pluralSlavic    = pluralRule{3,
+		"(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)",
+		func(n int) int {
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return 0
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+				return 1
+			}
+			return 2
+		}}
+)
+
+// pluralRules 按语言查找复数规则，地区变体优先，例如 pt-BR 与 pt 不同
+var pluralRules = map[string]pluralRule{
+	"zh": pluralOne, "ja": pluralOne, "ko": pluralOne, "vi": pluralOne, "th": pluralOne, "id": pluralOne,
+	"ms": pluralOne, "lo": pluralOne, "km": pluralOne, "my": pluralOne,
+	"fr": pluralTwoFrench, "pt-BR": pluralTwoFrench, "fa": pluralTwoFrench, "tr": pluralTwo,
+	"ru": pluralSlavic, "uk": pluralSlavic, "be": pluralSlavic, "sr": pluralSlavic, "hr": pluralSlavic,
+	"bs": pluralSlavic,
+	"pl": {3, "(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2)", func(n int) int {
+		switch {
+		case n == 1:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+			return 1
+		}
+		return 2
+	}},
+	"cs": {3, "(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2", czechPlural},
+	"sk": {3, "(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2", czechPlural},
+	"lt": {3, "(n%10==1 && n%100!=11 ? 0 : n%10>=2 && (n%100<10 || n%100>=20) ? 1 : 2)", func(n int) int {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && (n%100 < 10 || n%100 >= 20):
+			return 1
+		}
+		return 2
+	}},
+	"lv": {3, "(n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2)", func(n int) int {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n != 0:
+			return 1
+		}
+		return 2
+	}},
+	"ro": {3, "(n==1 ? 0 : (n==0 || (n%100 > 0 && n%100 < 20)) ? 1 : 2)", func(n int) int {
+		switch {
+		case n == 1:
+			return 0
+		case n == 0 || (n%100 > 0 && n%100 < 20):
+			return 1
+		}
+		return 2
+	}},
+	"sl": {4, "(n%100==1 ? 0 : n%100==2 ? 1 : n%100==3 || n%100==4 ? 2 : 3)", func(n int) int {
+		switch n % 100 {
+		case 1:
+			return 0
+		case 2:
+			return 1
+		case 3, 4:
+			return 2
+		}
+		return 3
+	}},
+	"ga": {5, "(n==1 ? 0 : n==2 ? 1 : n<7 ? 2 : n<11 ? 3 : 4)", func(n int) int {
+		switch {
+		case n == 1:
+			return 0
+		case n == 2:
+			return 1
+		case n < 7:
+			return 2
+		case n < 11:
+			return 3
+		}
+		return 4
+	}},
+	"ar": {6, "(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5)", func(n int) int {
+		switch {
+		case n <= 2:
+			return n
+		case n%100 >= 3 && n%100 <= 10:
+			return 3
+		case n%100 >= 11:
+			return 4
+		}
+		return 5
+	}},
+}
+
+func czechPlural(n int) int {
+	switch {
+	case n == 1:
+		return 0
+	case n >= 2 && n <= 4:
+		return 1
+	}
+	return 2
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// pluralFor 未收录的语言按单复数两种形式处理
+func pluralFor(lang string) pluralRule {
+	if rule, ok := pluralRules[lang]; ok {
+		return rule
+	}
+	base, _, _ := strings.Cut(lang, "-")
+	if rule, ok := pluralRules[strings.ToLower(base)]; ok {
+		return rule
+	}
+	return pluralTwo
+}
+
+// header Plural-Forms 头的取值
+func (r pluralRule) header() string {
+	return fmt.Sprintf("nplurals=%d; plural=%s;", r.count, r.expr)
+}
+
+// samples 使用第 form 种形式的前几个数，提示模型该形式的用法
+func (r pluralRule) samples(form int) []int {
+	var list []int
+	for n := 0; n < 1000 && len(list) < 4; n++ {
+		if r.form(n) == form {
+			list = append(list, n)
+		}
+	}
+	return list
+}
@@ -0,0 +1,328 @@
+package formats
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const poFlagFuzzy = "fuzzy"
+
+var (
+	poKeywordPattern = regexp.MustCompile(`^(msgctxt|msgid|msgid_plural|msgstr(?:\[(\d+)\])?)[ \t]+(".*")[ \t]*$`)
+	poStringPattern  = regexp.MustCompile(`^[ \t]*(".*")[ \t]*$`)
+)
+
+// poHandler gettext 的 PO/POT 文件，默认只翻译没有译文或标记为 fuzzy 的条目，
+// 复数条目按目标语言的复数形式数量生成译文，并更新文件头中的语言与复数规则
+type poHandler struct{}
+
+func (h *poHandler) Name() string {
+	return FormatPO
+}
+
+func (h *poHandler) Extensions() []string {
+	return []string{".po", ".pot"}
+}
+
+func (h *poHandler) Parse(data []byte, opt Options) (IDocument, error) {
+	doc := &poDocument{plural: pluralFor(opt.TargetLang)}
+	content := string(data)
+	doc.crlf = strings.Contains(content, "\r\n")
+	if doc.crlf {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	doc.lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	for i := 0; i < len(doc.lines); {
+		if strings.TrimSpace(doc.lines[i]) == "" {
+			i++
+			continue
+		}
+		end := i
+		for end < len(doc.lines) && strings.TrimSpace(doc.lines[end]) != "" {
+			end++
+		}
+		entry, err := parsePOEntry(doc.lines, i, end)
+		if err != nil {
+			return nil, err
+		}
+		doc.entries = append(doc.entries, entry)
+		i = end
+	}
+	for _, entry := range doc.entries {
+		if entry.header() {
+			if doc.header == nil {
+				doc.header = entry
+			}
+			continue
+		}
+		if !entry.obsolete && translatable(entry.id) && (opt.TranslateAll || entry.fuzzy() || entry.untranslated()) {
+			doc.addUnits(entry)
+		}
+	}
+	return doc, nil
+}
+
+// poEntry 一个条目在原文中占 [start, end) 行，msgstr 从 strLine 行开始直到条目结束
+type poEntry struct {
+	start, end int
+	strLine    int
+	flagLine   int
+	flags      []string
+	comments   []string
+	ctxt       string
+	id         string
+	idPlural   string
+	plural     bool
+	strs       []string
+	obsolete   bool
+	// units 各个译文对应的单元，复数条目每种形式一个单元
+	units []int
+}
+
+func (e *poEntry) header() bool {
+	return !e.obsolete && e.id == "" && e.ctxt == ""
+}
+
+func (e *poEntry) fuzzy() bool {
+	return slices.Contains(e.flags, poFlagFuzzy)
+}
+
+func (e *poEntry) untranslated() bool {
+	for _, s := range e.strs {
+		if s != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parsePOEntry(lines []string, start, end int) (*poEntry, error) {
+	entry := &poEntry{start: start, end: end, strLine: end, flagLine: -1}
+	var field *string
+	for i := start; i < end; i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		switch {
+		case strings.HasPrefix(line, "#~"):
+			entry.obsolete = true
+			continue
+		case strings.HasPrefix(line, "#,"):
+			entry.flagLine = i
+			for _, flag := range strings.Split(line[2:], ",") {
+				if flag = strings.TrimSpace(flag); flag != "" {
+					entry.flags = append(entry.flags, flag)
+				}
+			}
+			continue
+		case strings.HasPrefix(line, "#."), strings.HasPrefix(line, "# "):
+			if comment := strings.TrimSpace(line[2:]); comment != "" {
+				entry.comments = append(entry.comments, comment)
+			}
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+		if match := poStringPattern.FindStringSubmatch(line); match != nil && field != nil {
+			s, err := strconv.Unquote(match[1])
+			if err != nil {
+				return nil, fmt.Errorf("formats: invalid po string at line %d", i+1)
+			}
+			*field += s
+			continue
+		}
+		match := poKeywordPattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("formats: invalid po line %d", i+1)
+		}
+		s, err := strconv.Unquote(match[3])
+		if err != nil {
+			return nil, fmt.Errorf("formats: invalid po string at line %d", i+1)
+		}
+		switch keyword := match[1]; {
+		case keyword == "msgctxt":
+			field = &entry.ctxt
+		case keyword == "msgid":
+			field = &entry.id
+		case keyword == "msgid_plural":
+			field = &entry.idPlural
+			entry.plural = true
+		default:
+			if entry.strLine == end {
+				entry.strLine = i
+			}
+			entry.strs = append(entry.strs, "")
+			field = &entry.strs[len(entry.strs)-1]
+		}
+		*field = s
+	}
+	return entry, nil
+}
+
+// poDocument 生成译文时只替换需要翻译的条目的 msgstr，其它行原样保留
+type poDocument struct {
+	lines   []string
+	entries []*poEntry
+	header  *poEntry
+	units   []Unit
+	plural  pluralRule
+	crlf    bool
+}
+
+func (d *poDocument) Units() []Unit {
+	return d.units
+}
+
+// addUnits msgctxt 与注释作为上下文提供给模型，复数条目的每种形式注明适用的数量
+func (d *poDocument) addUnits(entry *poEntry) {
+	var context []string
+	if entry.ctxt != "" {
+		context = append(context, "Message context: "+entry.ctxt)
+	}
+	for _, comment := range entry.comments {
+		context = append(context, "Note: "+comment)
+	}
+	if !entry.plural {
+		entry.units = []int{len(d.units)}
+		d.units = append(d.units, Unit{Text: entry.id, Context: strings.Join(context, "\n")})
+		return
+	}
+	for form := 0; form < d.plural.count; form++ {
+		source := entry.idPlural
+		if form == 0 && d.plural.count > 1 {
+			source = entry.id
+		}
+		samples := make([]string, 0, 4)
+		for _, n := range d.plural.samples(form) {
+			samples = append(samples, strconv.Itoa(n))
+		}
+		hint := fmt.Sprintf("Plural form %d of %d, used when the count is %s",
+			form+1, d.plural.count, strings.Join(samples, ", "))
+		entry.units = append(entry.units, len(d.units))
+		d.units = append(d.units, Unit{Text: source, Context: strings.Join(append(slices.Clone(context), hint), "\n")})
+	}
+}
+
+func (d *poDocument) Render(translations []string, targetLang string) ([]byte, error) {
+	if len(translations) != len(d.units) {
+		return nil, errUnitCount(len(d.units), len(translations))
+	}
+	var out []string
+	if d.header == nil {
+		out = append(out, `msgid ""`)
+		out = append(out, poField("msgstr", d.headerText(nil, targetLang))...)
+		out = append(out, "")
+	}
+	last := 0
+	for _, entry := range d.entries {
+		var strs []string
+		switch {
+		case entry == d.header:
+			strs = []string{d.headerText(entry, targetLang)}
+		case len(entry.units) > 0:
+			for _, unit := range entry.units {
+				strs = append(strs, poNewlines(d.units[unit].Text, translations[unit]))
+			}
+		default:
+			continue
+		}
+		out = append(out, d.lines[last:entry.start]...)
+		out = append(out, d.entryLines(entry, strs)...)
+		last = entry.end
+	}
+	out = append(out, d.lines[last:]...)
+	content := strings.Join(out, "\n") + "\n"
+	if d.crlf {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+	return []byte(content), nil
+}
+
+// entryLines 去掉 fuzzy 标记与 #| 旧原文后写入新的 msgstr
+func (d *poDocument) entryLines(entry *poEntry, strs []string) []string {
+	var lines []string
+	for i := entry.start; i < entry.strLine; i++ {
+		line := d.lines[i]
+		switch {
+		case i == entry.flagLine:
+			flags := slices.DeleteFunc(slices.Clone(entry.flags), func(flag string) bool {
+				return flag == poFlagFuzzy
+			})
+			if len(flags) == 0 {
+				continue
+			}
+			line = "#, " + strings.Join(flags, ", ")
+		case strings.HasPrefix(line, "#|"):
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if !entry.plural {
+		return append(lines, poField("msgstr", strs[0])...)
+	}
+	for i, s := range strs {
+		lines = append(lines, poField(fmt.Sprintf("msgstr[%d]", i), s)...)
+	}
+	return lines
+}
+
+// headerText 更新文件头中的语言、复数规则、编码与修订时间，其余字段保持原有顺序
+func (d *poDocument) headerText(header *poEntry, targetLang string) string {
+	updates := [][2]string{
+		{"Language", targetLang},
+		{"Plural-Forms", pluralFor(targetLang).header()},
+		{"Content-Type", "text/plain; charset=UTF-8"},
+		{"Content-Transfer-Encoding", "8bit"},
+		{"PO-Revision-Date", time.Now().Format("2006-01-02 15:04-0700")},
+	}
+	var fields []string
+	if header != nil && len(header.strs) > 0 {
+		fields = strings.Split(strings.TrimSuffix(header.strs[0], "\n"), "\n")
+	}
+	for _, update := range updates {
+		field := update[0] + ": " + update[1]
+		i := slices.IndexFunc(fields, func(line string) bool {
+			key, _, _ := strings.Cut(line, ":")
+			return strings.EqualFold(strings.TrimSpace(key), update[0])
+		})
+		if i >= 0 {
+			fields[i] = field
+		} else {
+			fields = append(fields, field)
+		}
+	}
+	return strings.Join(fields, "\n") + "\n"
+}
+
+// poNewlines 译文首尾的换行与原文保持一致，msgfmt 会检查这一点
+func poNewlines(source, translation string) string {
+	translation = strings.TrimSpace(translation)
+	if strings.HasPrefix(source, "\n") {
+		translation = "\n" + translation
+	}
+	if strings.HasSuffix(source, "\n") {
+		translation += "\n"
+	}
+	return translation
+}
+
+// poField 含有换行的字符串按 gettext 的习惯分行书写
+func poField(keyword, s string) []string {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		return []string{keyword + " " + poQuote(s)}
+	}
+	lines := []string{keyword + ` ""`}
+	for _, part := range strings.SplitAfter(s, "\n") {
+		if part != "" {
+			lines = append(lines, poQuote(part))
+		}
+	}
+	return lines
+}
+
+func poQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + replacer.Replace(s) + `"`
+}
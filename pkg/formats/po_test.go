@@ -0,0 +1,204 @@
+package formats
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testPO = `# Demo translations
+msgid ""
+msgstr ""
+"Project-Id-Version: demo 1.0\n"
+"Language: en\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: main.go:10
+msgid "Done"
+msgstr "Готово"
+
+#. Shown on the toolbar
+msgctxt "menu"
+msgid "Open"
+msgstr ""
+
+#, fuzzy, c-format
+#| msgid "Delete %s"
+msgid "Remove %s"
+msgstr "Удалить %s"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+#~ msgid "Old"
+#~ msgstr "Старый"
+`
+
+var poRevisionPattern = regexp.MustCompile(`"PO-Revision-Date: [^"]*\\n"`)
+
+func TestPOUnits(t *testing.T) {
+	handler, _ := Get(FormatPO)
+	doc, err := handler.Parse([]byte(testPO), Options{TargetLang: "ru"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Unit{
+		{Text: "Open", Context: "Message context: menu\nNote: Shown on the toolbar"},
+		{Text: "Remove %s"},
+		{Text: "%d file", Context: "Plural form 1 of 3, used when the count is 1, 21, 31, 41"},
+		{Text: "%d files", Context: "Plural form 2 of 3, used when the count is 2, 3, 4, 22"},
+		{Text: "%d files", Context: "Plural form 3 of 3, used when the count is 0, 5, 6, 7"},
+	}
+	units := doc.Units()
+	if len(units) != len(want) {
+		t.Fatalf("units = %q", unitTexts(doc))
+	}
+	for i := range want {
+		if units[i] != want[i] {
+			t.Errorf("unit %d = %+v, want %+v", i, units[i], want[i])
+		}
+	}
+
+	// 已有译文的条目只在 TranslateAll 时重新翻译，过时条目始终跳过
+	doc, err = handler.Parse([]byte(testPO), Options{TargetLang: "ru", TranslateAll: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texts := unitTexts(doc); len(texts) != 6 || texts[0] != "Done" {
+		t.Errorf("translate all units = %q", texts)
+	}
+}
+
+func TestPORender(t *testing.T) {
+	handler, _ := Get(FormatPO)
+	doc, err := handler.Parse([]byte(testPO), Options{TargetLang: "ru"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := doc.Render([]string{"Открыть", "Убрать %s", "%d файл", "%d файла", "%d файлов"}, "ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Demo translations
+msgid ""
+msgstr ""
+"Project-Id-Version: demo 1.0\n"
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"PO-Revision-Date: -\n"
+
+#: main.go:10
+msgid "Done"
+msgstr "Готово"
+
+#. Shown on the toolbar
+msgctxt "menu"
+msgid "Open"
+msgstr "Открыть"
+
+#, c-format
+msgid "Remove %s"
+msgstr "Убрать %s"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d файл"
+msgstr[1] "%d файла"
+msgstr[2] "%d файлов"
+
+#~ msgid "Old"
+#~ msgstr "Старый"
+`
+	got := poRevisionPattern.ReplaceAllString(string(out), `"PO-Revision-Date: -\n"`)
+	if got != want {
+		t.Errorf("render:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPOHeader(t *testing.T) {
+	cases := []struct {
+		name, data, want string
+	}{
+		{
+			// 没有文件头时补充文件头
+			name: "missing",
+			data: "msgid \"Hi\"\nmsgstr \"\"\n",
+			want: "msgid \"\"\nmsgstr \"\"\n\"Language: zh-CN\\n\"\n\"Plural-Forms: nplurals=1; plural=0;\\n\"\n" +
+				"\"Content-Type: text/plain; charset=UTF-8\\n\"\n\"Content-Transfer-Encoding: 8bit\\n\"\n" +
+				"\"PO-Revision-Date: -\\n\"\n\nmsgid \"Hi\"\nmsgstr \"你好\"\n",
+		},
+		{
+			// POT 模板的文件头通常标记为 fuzzy，字段名不区分大小写
+			name: "fuzzy template",
+			data: "#, fuzzy\nmsgid \"\"\nmsgstr \"\"\n\"language: \\n\"\n\"plural-forms: nplurals=INTEGER; plural=EXPRESSION;\\n\"\n\n" +
+				"msgid \"Hi\"\nmsgstr \"\"\n",
+			want: "msgid \"\"\nmsgstr \"\"\n\"Language: zh-CN\\n\"\n\"Plural-Forms: nplurals=1; plural=0;\\n\"\n" +
+				"\"Content-Type: text/plain; charset=UTF-8\\n\"\n\"Content-Transfer-Encoding: 8bit\\n\"\n" +
+				"\"PO-Revision-Date: -\\n\"\n\nmsgid \"Hi\"\nmsgstr \"你好\"\n",
+		},
+	}
+	handler, _ := Get(FormatPO)
+	for _, c := range cases {
+		for _, crlf := range []bool{false, true} {
+			data, want := c.data, c.want
+			if crlf {
+				data, want = strings.ReplaceAll(data, "\n", "\r\n"), strings.ReplaceAll(want, "\n", "\r\n")
+			}
+			doc, err := handler.Parse([]byte(data), Options{TargetLang: "zh-CN"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := doc.Render([]string{"你好"}, "zh-CN")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := poRevisionPattern.ReplaceAllString(string(out), `"PO-Revision-Date: -\n"`); got != want {
+				t.Errorf("%s (crlf %v):\n%q\nwant\n%q", c.name, crlf, got, want)
+			}
+		}
+	}
+}
+
+func TestPluralFor(t *testing.T) {
+	cases := []struct {
+		lang  string
+		count int
+		expr  string
+	}{
+		{"zh-CN", 1, "0"},
+		{"en", 2, "(n != 1)"},
+		{"fr", 2, "(n > 1)"},
+		{"pt-BR", 2, "(n > 1)"},
+		{"pt", 2, "(n != 1)"},
+		{"ru", 3, pluralSlavic.expr},
+		{"uk-UA", 3, pluralSlavic.expr},
+		{"ar", 6, pluralRules["ar"].expr},
+		{"xx", 2, "(n != 1)"},
+	}
+	for _, c := range cases {
+		if rule := pluralFor(c.lang); rule.count != c.count || rule.expr != c.expr {
+			t.Errorf("pluralFor(%q) = %d %q", c.lang, rule.count, rule.expr)
+		}
+	}
+
+	forms := map[int]int{0: 2, 1: 0, 2: 1, 4: 1, 5: 2, 11: 2, 12: 2, 21: 0, 22: 1, 111: 2, 1001: 0}
+	for n, form := range forms {
+		if got := pluralFor("ru").form(n); got != form {
+			t.Errorf("ru form(%d) = %d, want %d", n, got, form)
+		}
+	}
+}
+
+func TestPOInvalid(t *testing.T) {
+	handler, _ := Get(FormatPO)
+	for _, data := range []string{"msgid \"Hi\nmsgstr \"\"\n", "msgid \"Hi\"\nbogus\n"} {
+		if _, err := handler.Parse([]byte(data), Options{}); err == nil {
+			t.Errorf("%q accepted", data)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package formats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// srtHandler 每条字幕由序号、时间轴与若干行文本组成，以空行分隔，序号与时间轴原样保留
+type srtHandler struct{}
+
+func (h *srtHandler) Name() string {
+	return FormatSRT
+}
+
+func (h *srtHandler) Extensions() []string {
+	return []string{".srt"}
+}
+
+func (h *srtHandler) Parse(data []byte, opt Options) (IDocument, error) {
+	doc := newSubtitleDocument(data, opt)
+	for i := 0; i < len(doc.lines); {
+		if strings.TrimSpace(doc.lines[i]) == "" {
+			i++
+			continue
+		}
+		// 序号可以省略，直接从时间轴开始
+		timing := i
+		if !isTimingLine(doc.lines[i]) {
+			timing = i + 1
+		}
+		if timing >= len(doc.lines) || !isTimingLine(doc.lines[timing]) {
+			return nil, fmt.Errorf("formats: invalid srt cue at line %d", i+1)
+		}
+		i = cueEnd(doc.lines, timing+1)
+		doc.cues = append(doc.cues, subtitleCue{start: timing + 1, end: i})
+	}
+	doc.batch()
+	return doc, nil
+}
+
+func isTimingLine(line string) bool {
+	return strings.Contains(line, "-->")
+}
+
+// cueEnd 字幕文本到空行为止
+func cueEnd(lines []string, i int) int {
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+		i++
+	}
+	return i
+}
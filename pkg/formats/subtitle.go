@@ -0,0 +1,378 @@
+package formats
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// subtitleBatchCues、subtitleBatchRunes 连续的字幕合并为一个单元翻译，句子跨字幕时模型能看到完整的句子
+	subtitleBatchCues  = 8
+	subtitleBatchRunes = 600
+	// subtitleContextCues 上一个单元末尾作为上下文的字幕数
+	subtitleContextCues = 2
+)
+
+// ErrSubtitleSeparators 合并翻译的字幕在译文中缺少、重复或打乱了分隔元素，无法确定各条字幕的译文
+var ErrSubtitleSeparators = errors.New("formats: subtitle separators changed in translation")
+
+var (
+	// subtitleTagPattern 字幕中的样式标签，例如 <i>、<c.yellow>、<v Speaker>、<00:01.000> 与 SRT 的 {\an8}
+	subtitleTagPattern = regexp.MustCompile(`^(?:<[^<>\n]+>|\{\\[^{}\n]*\})`)
+)
+
+// subtitleCue 一条字幕，lines 为字幕文本在原文行中的区间 [start, end)
+type subtitleCue struct {
+	start, end int
+}
+
+// subtitleBatch 合并翻译的连续字幕，separators 为字幕之间分隔占位元素的序号
+type subtitleBatch struct {
+	cues       []int
+	inline     inlineText
+	separators []int
+}
+
+// subtitleDocument SRT 与 WebVTT 共用，序号、时间轴与其它行原样保留，只替换字幕文本行
+type subtitleDocument struct {
+	lines             []string
+	cues              []subtitleCue
+	batches           []subtitleBatch
+	units             []Unit
+	crlf              bool
+	noTrailingNewline bool
+	maxLineChars      int
+}
+
+// newSubtitleDocument 统一换行符后按行拆分，记录原文的换行符以便原样输出
+func newSubtitleDocument(data []byte, opt Options) *subtitleDocument {
+	content := string(data)
+	doc := &subtitleDocument{crlf: strings.Contains(content, "\r\n"), maxLineChars: opt.MaxLineChars}
+	if doc.crlf {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	doc.lines = strings.Split(content, "\n")
+	if doc.lines[len(doc.lines)-1] == "" {
+		doc.lines = doc.lines[:len(doc.lines)-1]
+	} else {
+		doc.noTrailingNewline = true
+	}
+	return doc
+}
+
+func (d *subtitleDocument) Units() []Unit {
+	return d.units
+}
+
+func (d *subtitleDocument) cueText(i int) string {
+	cue := d.cues[i]
+	return strings.Join(d.lines[cue.start:cue.end], "\n")
+}
+
+// batch 按数量与长度把字幕分组，每组的文本以占位元素分隔各条字幕，并以前几条字幕作为上下文
+func (d *subtitleDocument) batch() {
+	var current []int
+	size := 0
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		b := &inlineBuilder{}
+		batch := subtitleBatch{cues: current}
+		for i, cue := range current {
+			if i > 0 {
+				b.protect("\n")
+				batch.separators = append(batch.separators, len(b.fragments))
+			}
+			maskSubtitleTags(b, d.cueText(cue))
+		}
+		batch.inline = b.done()
+		var context []string
+		for _, cue := range d.previousCues(current[0]) {
+			context = append(context, stripSubtitleTags(d.cueText(cue)))
+		}
+		d.batches = append(d.batches, batch)
+		d.units = append(d.units, Unit{Text: batch.inline.Text, Context: strings.Join(context, "\n")})
+		current, size = nil, 0
+	}
+	for i := range d.cues {
+		text := stripSubtitleTags(d.cueText(i))
+		if !translatable(text) {
+			continue
+		}
+		runes := utf8.RuneCountInString(text)
+		if len(current) == subtitleBatchCues || (len(current) > 0 && size+runes > subtitleBatchRunes) {
+			flush()
+		}
+		current = append(current, i)
+		size += runes
+	}
+	flush()
+}
+
+// previousCues cue 之前最多 subtitleContextCues 条需要翻译的字幕
+func (d *subtitleDocument) previousCues(cue int) []int {
+	var list []int
+	for i := cue - 1; i >= 0 && len(list) < subtitleContextCues; i-- {
+		if translatable(stripSubtitleTags(d.cueText(i))) {
+			list = append([]int{i}, list...)
+		}
+	}
+	return list
+}
+
+func (d *subtitleDocument) Render(translations []string, targetLang string) ([]byte, error) {
+	if len(translations) != len(d.units) {
+		return nil, errUnitCount(len(d.units), len(translations))
+	}
+	// 以字幕文本的第一行为键，记录替换后的各行
+	replaced := make(map[int][]string)
+	for i, batch := range d.batches {
+		parts, err := batch.split(translations[i])
+		if err != nil {
+			return nil, err
+		}
+		for j, text := range parts {
+			cue := d.cues[batch.cues[j]]
+			replaced[cue.start] = d.layout(batch.inline.restore(text))
+		}
+	}
+	var out []string
+	for i, cue := 0, 0; i < len(d.lines); i++ {
+		for cue < len(d.cues) && d.cues[cue].start < i {
+			cue++
+		}
+		lines, ok := replaced[i]
+		if !ok {
+			out = append(out, d.lines[i])
+			continue
+		}
+		out = append(out, lines...)
+		i = d.cues[cue].end - 1
+	}
+	content := strings.Join(out, "\n")
+	if !d.noTrailingNewline {
+		content += "\n"
+	}
+	if d.crlf {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+	return []byte(content), nil
+}
+
+// layout 去掉空行，空行会提前结束字幕；设置了每行字数时重新换行
+func (d *subtitleDocument) layout(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return []string{""}
+	}
+	if d.maxLineChars <= 0 {
+		return lines
+	}
+	return wrapSubtitle(joinSubtitleLines(lines), d.maxLineChars)
+}
+
+// ValidateUnit 译文中的分隔元素必须与原文相同，每个恰好出现一次且顺序不变
+func (d *subtitleDocument) ValidateUnit(i int, translation string) error {
+	if i < 0 || i >= len(d.batches) {
+		return nil
+	}
+	_, err := d.batches[i].split(translation)
+	return err
+}
+
+// split 按分隔占位元素拆分译文，分隔元素缺失、重复或顺序错乱时返回错误，
+// 此时无法判断文字属于哪条字幕，按比例拆分会把译文放到错误的时间轴上
+func (b *subtitleBatch) split(translation string) ([]string, error) {
+	separators := make(map[string]bool, len(b.separators))
+	for _, id := range b.separators {
+		separators[strconv.Itoa(id)] = true
+	}
+	var parts []string
+	last, next := 0, 0
+	for _, loc := range inlinePlaceholderPattern.FindAllStringSubmatchIndex(translation, -1) {
+		id := translation[loc[2]:loc[3]]
+		if !separators[id] {
+			continue
+		}
+		if next == len(b.separators) || id != strconv.Itoa(b.separators[next]) {
+			return nil, fmt.Errorf("%w: unexpected separator %s", ErrSubtitleSeparators, id)
+		}
+		parts = append(parts, translation[last:loc[0]])
+		last = loc[1]
+		next++
+	}
+	if next < len(b.separators) {
+		return nil, fmt.Errorf("%w: found %d of %d separators", ErrSubtitleSeparators, next, len(b.separators))
+	}
+	return append(parts, translation[last:]), nil
+}
+
+// maskSubtitleTags 样式标签替换为占位元素，字幕中的换行保留
+func maskSubtitleTags(b *inlineBuilder, s string) {
+	last := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '<' && s[i] != '{' {
+			continue
+		}
+		if loc := subtitleTagPattern.FindStringIndex(s[i:]); loc != nil {
+			b.text(s[last:i])
+			b.protect(s[i : i+loc[1]])
+			i += loc[1] - 1
+			last = i + 1
+		}
+	}
+	b.text(s[last:])
+}
+
+func stripSubtitleTags(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '<' || s[i] == '{' {
+			if loc := subtitleTagPattern.FindStringIndex(s[i:]); loc != nil {
+				i += loc[1] - 1
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// joinSubtitleLines 合并为一行，两侧都是中日韩等不用空格分词的文字时直接连接
+func joinSubtitleLines(lines []string) string {
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			prev, _ := utf8.DecodeLastRuneInString(sb.String())
+			next, _ := utf8.DecodeRuneInString(line)
+			if !isUnspaced(prev) || !isUnspaced(next) {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// wrapSubtitle 按空格换行使每行不超过 limit 个字符，单词过长或没有空格的文字按字符断开，样式标签不计入长度
+func wrapSubtitle(text string, limit int) []string {
+	var (
+		lines   []string
+		current strings.Builder
+		width   int
+	)
+	newline := func() {
+		if current.Len() > 0 {
+			lines = append(lines, strings.TrimRight(current.String(), " "))
+		}
+		current.Reset()
+		width = 0
+	}
+	for _, word := range subtitleWords(text) {
+		w := utf8.RuneCountInString(stripSubtitleTags(word))
+		if word == " " {
+			if width > 0 && width < limit {
+				current.WriteByte(' ')
+				width++
+			}
+			continue
+		}
+		if width+w > limit && width > 0 {
+			newline()
+		}
+		for w > limit {
+			// 单个词超过一行，按字符断开
+			head, tail := splitRunes(word, limit)
+			current.WriteString(head)
+			newline()
+			word, w = tail, utf8.RuneCountInString(stripSubtitleTags(tail))
+		}
+		current.WriteString(word)
+		width += w
+	}
+	newline()
+	if len(lines) == 0 {
+		return []string{""}
+	}
+	return lines
+}
+
+// subtitleWords 按空格拆分，中日韩文字每个字符为一个词，标签与相邻的文字连在一起
+func subtitleWords(text string) []string {
+	var (
+		words []string
+		word  strings.Builder
+	)
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for i := 0; i < len(text); {
+		if loc := subtitleTagPattern.FindStringIndex(text[i:]); loc != nil {
+			word.WriteString(text[i : i+loc[1]])
+			i += loc[1]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+			words = append(words, " ")
+		case isUnspaced(r):
+			flush()
+			word.WriteRune(r)
+			// 紧随其后的标点不能放到下一行开头
+			for i+size < len(text) {
+				p, n := utf8.DecodeRuneInString(text[i+size:])
+				if !unicode.IsPunct(p) {
+					break
+				}
+				word.WriteRune(p)
+				size += n
+			}
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+		i += size
+	}
+	flush()
+	return words
+}
+
+// splitRunes 按字符数拆分，标签不计入字符数
+func splitRunes(s string, n int) (string, string) {
+	n = max(n, 1)
+	count := 0
+	for i := 0; i < len(s); {
+		if loc := subtitleTagPattern.FindStringIndex(s[i:]); loc != nil {
+			i += loc[1]
+			continue
+		}
+		if count == n {
+			return s[:i], s[i:]
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		count++
+	}
+	return s, ""
+}
+
+func isUnspaced(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai, unicode.Lao, unicode.Khmer) ||
+		(r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF)
+}
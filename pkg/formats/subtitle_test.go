@@ -0,0 +1,143 @@
+package formats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testSRT = "1\n00:00:01,000 --> 00:00:02,500\nHello there.\n\n2\n00:00:03,000 --> 00:00:04,000\n<i>How are</i>\nyou today?\n\n" +
+	"3\n00:00:05,000 --> 00:00:06,000\n♪ ♪\n\n4\n00:00:07,000 --> 00:00:08,000\n{\\an8}Fine, thanks.\n"
+
+const testVTT = "WEBVTT - demo\n\nNOTE translators ignore this\n\nSTYLE\n::cue { color: white }\n\n" +
+	"intro\n00:01.000 --> 00:02.000 align:start position:10%\n<v Anna>Good morning.\n\n00:02.500 --> 00:04.000\nSee you <c.yellow>later</c>.\n"
+
+func TestSubtitleRoundTrip(t *testing.T) {
+	cases := []struct {
+		name, format, data string
+	}{
+		{"srt", FormatSRT, testSRT},
+		{"srt crlf", FormatSRT, strings.ReplaceAll(testSRT, "\n", "\r\n")},
+		{"srt without index or trailing newline", FormatSRT, "00:00:01,000 --> 00:00:02,000\nNo index here"},
+		{"vtt", FormatVTT, testVTT},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, out := roundTrip(t, c.format, c.data, Options{}, "fr")
+			if out != c.data {
+				t.Errorf("round trip changed the subtitles\n got: %q\nwant: %q", out, c.data)
+			}
+		})
+	}
+}
+
+func TestSubtitleUnits(t *testing.T) {
+	doc, _ := roundTrip(t, FormatSRT, testSRT, Options{}, "fr")
+	want := `Hello there.<x id="1"/><x id="2"/>How are<x id="3"/>` + "\nyou today?" + `<x id="4"/><x id="5"/>Fine, thanks.`
+	units := doc.Units()
+	if len(units) != 1 || units[0].Text != want {
+		t.Fatalf("units = %q, want %q", unitTexts(doc), want)
+	}
+
+	// 超过一组的字幕数时拆分为多个单元，后一个单元以前两条字幕为上下文
+	var b strings.Builder
+	for i := 1; i <= subtitleBatchCues+2; i++ {
+		fmt.Fprintf(&b, "%d\n00:00:%02d,000 --> 00:00:%02d,500\nLine number %d\n\n", i, i, i, i)
+	}
+	doc, _ = roundTrip(t, FormatSRT, b.String(), Options{}, "fr")
+	units = doc.Units()
+	if len(units) != 2 || units[1].Context != "Line number 7\nLine number 8" {
+		t.Errorf("units = %q, context = %q", unitTexts(doc), units[len(units)-1].Context)
+	}
+}
+
+func TestSubtitleRenderKeepsTiming(t *testing.T) {
+	handler, _ := Get(FormatVTT)
+	doc, err := handler.Parse([]byte(testVTT), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := doc.Render([]string{`<x id="1"/>Bonjour.` + "\n\n" + `<x id="2"/>À plus <x id="3"/>tard<x id="4"/>.`}, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "WEBVTT - demo\n\nNOTE translators ignore this\n\nSTYLE\n::cue { color: white }\n\n" +
+		"intro\n00:01.000 --> 00:02.000 align:start position:10%\n<v Anna>Bonjour.\n\n00:02.500 --> 00:04.000\nÀ plus <c.yellow>tard</c>.\n"
+	if string(out) != want {
+		t.Errorf("render = %q\nwant %q", out, want)
+	}
+}
+
+func TestSubtitleSeparators(t *testing.T) {
+	handler, _ := Get(FormatSRT)
+	doc, err := handler.Parse([]byte(testSRT), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := doc.(IUnitValidator)
+	cases := []struct {
+		name        string
+		translation string
+		valid       bool
+	}{
+		{"intact", `Salut.<x id="1"/><x id="2"/>Comment<x id="3"/> vas-tu ?<x id="4"/><x id="5"/>Bien.`, true},
+		{"lost", `Salut. Comment vas-tu ?<x id="4"/><x id="5"/>Bien.`, false},
+		{"duplicated", `Salut.<x id="1"/>Comment<x id="1"/> vas-tu ?<x id="4"/>Bien.`, false},
+		{"reordered", `Salut.<x id="4"/>Comment vas-tu ?<x id="1"/>Bien.`, false},
+	}
+	for _, c := range cases {
+		err := validator.ValidateUnit(0, c.translation)
+		if (err == nil) != c.valid {
+			t.Errorf("%s: ValidateUnit = %v", c.name, err)
+		}
+		_, renderErr := doc.Render([]string{c.translation}, "fr")
+		if c.valid && renderErr != nil {
+			t.Errorf("%s: render = %v", c.name, renderErr)
+		}
+		if !c.valid && !errors.Is(renderErr, ErrSubtitleSeparators) {
+			t.Errorf("%s: render error = %v, want ErrSubtitleSeparators", c.name, renderErr)
+		}
+	}
+}
+
+func TestSubtitleMaxLineChars(t *testing.T) {
+	data := "1\n00:00:01,000 --> 00:00:03,000\nShort\n"
+	cases := []struct {
+		translation string
+		want        string
+	}{
+		{"This translation is far too long\nfor one line", "This translation is\nfar too long for one\nline"},
+		{"<i>Italic words stay</i> together", "<i>Italic words stay</i>\ntogether"},
+		{"Supercalifragilisticexpialidocious", "Supercalifragilistic\nexpialidocious"},
+		{"这是一个很长的中文字幕需要按照字符数重新换行，标点不放在行首。", "这是一个很长的中文字幕需要按照字符数重新\n换行，标点不放在行首。"},
+	}
+	handler, _ := Get(FormatSRT)
+	for _, c := range cases {
+		doc, err := handler.Parse([]byte(data), Options{MaxLineChars: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := doc.Render([]string{c.translation}, "fr")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "1\n00:00:01,000 --> 00:00:03,000\n" + c.want + "\n"; string(out) != want {
+			t.Errorf("wrap %q = %q, want %q", c.translation, out, want)
+		}
+	}
+}
+
+func TestSubtitleInvalid(t *testing.T) {
+	if _, ok := Get(FormatVTT); !ok {
+		t.Fatal("vtt handler missing")
+	}
+	srt, _ := Get(FormatSRT)
+	if _, err := srt.Parse([]byte("1\nno timing here\n"), Options{}); err == nil {
+		t.Error("srt without timing accepted")
+	}
+	vtt, _ := Get(FormatVTT)
+	if _, err := vtt.Parse([]byte("00:01.000 --> 00:02.000\nHi\n"), Options{}); err == nil {
+		t.Error("vtt without header accepted")
+	}
+}
@@ -0,0 +1,31 @@
+package formats
+
+// textHandler 纯文本整体作为一个单元，由分段逻辑按段落切分
+type textHandler struct{}
+
+func (h *textHandler) Name() string {
+	return FormatText
+}
+
+func (h *textHandler) Extensions() []string {
+	return []string{".txt", ".text"}
+}
+
+func (h *textHandler) Parse(data []byte, opt Options) (IDocument, error) {
+	return &textDocument{content: string(data)}, nil
+}
+
+type textDocument struct {
+	content string
+}
+
+func (d *textDocument) Units() []Unit {
+	return []Unit{{Text: d.content}}
+}
+
+func (d *textDocument) Render(translations []string, targetLang string) ([]byte, error) {
+	if len(translations) != 1 {
+		return nil, errUnitCount(1, len(translations))
+	}
+	return []byte(translations[0]), nil
+}
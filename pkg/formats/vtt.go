@@ -0,0 +1,48 @@
+package formats
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// vttHandler WebVTT 的文件头、NOTE、STYLE 与 REGION 块原样保留，字幕的标识、时间轴与设置原样保留
+type vttHandler struct{}
+
+func (h *vttHandler) Name() string {
+	return FormatVTT
+}
+
+func (h *vttHandler) Extensions() []string {
+	return []string{".vtt"}
+}
+
+func (h *vttHandler) Parse(data []byte, opt Options) (IDocument, error) {
+	doc := newSubtitleDocument(data, opt)
+	if len(doc.lines) == 0 || !strings.HasPrefix(doc.lines[0], "WEBVTT") {
+		return nil, errors.New("formats: missing WEBVTT header")
+	}
+	for i := cueEnd(doc.lines, 0); i < len(doc.lines); {
+		line := doc.lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+			continue
+		case strings.HasPrefix(line, "NOTE"), strings.HasPrefix(line, "STYLE"), strings.HasPrefix(line, "REGION"):
+			i = cueEnd(doc.lines, i)
+			continue
+		}
+		// 时间轴前可以有一行标识
+		timing := i
+		if !isTimingLine(line) {
+			timing = i + 1
+		}
+		if timing >= len(doc.lines) || !isTimingLine(doc.lines[timing]) {
+			return nil, fmt.Errorf("formats: invalid vtt cue at line %d", i+1)
+		}
+		i = cueEnd(doc.lines, timing+1)
+		doc.cues = append(doc.cues, subtitleCue{start: timing + 1, end: i})
+	}
+	doc.batch()
+	return doc, nil
+}
@@ -0,0 +1,378 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	XLIFF12 = "1.2"
+	XLIFF20 = "2.0"
+
+	xliffStateTranslated = "translated"
+)
+
+// xliffPaired 成对的行内元素，开始与结束标签各为一个占位元素，中间的文字仍然翻译；其余行内元素整体为一个占位元素
+var xliffPaired = []string{"g", "pc", "mrk"}
+
+// xliffPending 需要翻译的状态，1.2 为 target 的 state，2.0 为 segment 的 state
+var xliffPending = []string{"", "new", "initial", "needs-translation", "needs-l10n", "needs-adaptation"}
+
+// xliffHandler XLIFF 1.2 与 2.0，遵循 translate="no"，默认只翻译没有译文或状态为待翻译的条目，
+// 译文写入 target 并把状态设为 translated，文件中的其它内容按字节原样保留
+type xliffHandler struct{}
+
+func (h *xliffHandler) Name() string {
+	return FormatXLIFF
+}
+
+func (h *xliffHandler) Extensions() []string {
+	return []string{".xlf", ".xliff"}
+}
+
+func (h *xliffHandler) Parse(data []byte, opt Options) (IDocument, error) {
+	p := &xliffParser{data: data, dec: xml.NewDecoder(bytes.NewReader(data))}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	if p.version == "" {
+		return nil, errors.New("formats: missing xliff root element")
+	}
+	doc := &xliffDocument{data: data, version: p.version, langTags: p.langTags}
+	for _, seg := range p.segments {
+		if !seg.translate || !seg.inline.translatable() {
+			continue
+		}
+		if !opt.TranslateAll && seg.hasTarget && !seg.emptyTarget && !slices.Contains(xliffPending, seg.state) {
+			continue
+		}
+		doc.segments = append(doc.segments, seg)
+		doc.units = append(doc.units, Unit{Text: seg.inline.Text})
+	}
+	return doc, nil
+}
+
+// span 原文中的字节区间 [start, end)
+type span struct {
+	start, end int
+}
+
+// xliffSegment 1.2 的 trans-unit 或 2.0 的 segment
+type xliffSegment struct {
+	translate bool
+	// depth trans-unit 或 segment 所在的层级，只有直接子元素 source 与 target 属于该条目，
+	// alt-trans 等元素中的 source 与 target 是参考译文
+	depth  int
+	inline inlineText
+	// sourceTag 原文 source 元素的标签名，可能带有命名空间前缀；sourceEnd 为 </source> 之后的位置
+	sourceTag string
+	sourceEnd int
+	// indent source 前的空白，新增的 target 使用相同的缩进
+	indent      string
+	hasTarget   bool
+	emptyTarget bool
+	// targetTag target 的开始标签，targetInner 为其内容；自闭合的 target 只记录 targetTag
+	targetTag   span
+	targetInner *span
+	state       string
+	// stateTag 保存状态的开始标签，1.2 为 target，2.0 为 segment
+	stateTag *span
+}
+
+type xliffParser struct {
+	data     []byte
+	dec      *xml.Decoder
+	version  string
+	langTags []span
+	segments []*xliffSegment
+}
+
+// token 返回下一个节点及其在原文中的区间，自闭合元素的结束节点区间为空
+func (p *xliffParser) token() (xml.Token, span, error) {
+	start := int(p.dec.InputOffset())
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, span{}, err
+	}
+	return xml.CopyToken(tok), span{start, int(p.dec.InputOffset())}, nil
+}
+
+func (p *xliffParser) parse() error {
+	// translate 记录每层元素是否可翻译，子元素继承父元素
+	translate := []bool{true}
+	var seg *xliffSegment
+	for {
+		tok, pos, err := p.token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("formats: invalid xliff: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			allowed := translate[len(translate)-1] && xmlAttr(t, "translate") != "no"
+			translate = append(translate, allowed)
+			switch t.Name.Local {
+			case "xliff":
+				p.version = xmlAttr(t, "version")
+				if xliffV2(p.version) {
+					p.langTags = append(p.langTags, pos)
+				}
+			case "file":
+				if !xliffV2(p.version) {
+					p.langTags = append(p.langTags, pos)
+				}
+			case "trans-unit":
+				seg = &xliffSegment{translate: allowed, depth: len(translate)}
+			case "segment":
+				seg = &xliffSegment{translate: allowed, depth: len(translate), state: xmlAttr(t, "state")}
+				tag := pos
+				seg.stateTag = &tag
+			case "source":
+				if seg == nil || len(translate) != seg.depth+1 {
+					continue
+				}
+				seg.sourceTag = xmlTagName(p.data[pos.start:pos.end])
+				seg.indent = precedingSpace(p.data, pos.start)
+				if seg.inline, err = p.inline(); err != nil {
+					return err
+				}
+				seg.sourceEnd = int(p.dec.InputOffset())
+				translate = translate[:len(translate)-1]
+			case "target":
+				if seg == nil || len(translate) != seg.depth+1 {
+					continue
+				}
+				seg.hasTarget = true
+				seg.targetTag = pos
+				if !xliffV2(p.version) {
+					seg.state = xmlAttr(t, "state")
+					tag := pos
+					seg.stateTag = &tag
+				}
+				inner, err := p.skip()
+				if err != nil {
+					return err
+				}
+				if inner != nil {
+					seg.targetInner = inner
+					seg.emptyTarget = len(bytes.TrimSpace(p.data[inner.start:inner.end])) == 0
+				} else {
+					seg.emptyTarget = true
+				}
+				translate = translate[:len(translate)-1]
+			}
+		case xml.EndElement:
+			translate = translate[:len(translate)-1]
+			if (t.Name.Local == "trans-unit" || t.Name.Local == "segment") && seg != nil {
+				if seg.sourceTag != "" {
+					p.segments = append(p.segments, seg)
+				}
+				seg = nil
+			}
+		}
+	}
+}
+
+// skip 跳过当前元素的内容，返回内容的区间，自闭合元素返回 nil
+func (p *xliffParser) skip() (*span, error) {
+	start := int(p.dec.InputOffset())
+	depth := 0
+	for {
+		tok, pos, err := p.token()
+		if err != nil {
+			return nil, fmt.Errorf("formats: invalid xliff: %w", err)
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if pos.start == pos.end {
+				return nil, nil
+			}
+			return &span{start, pos.start}, nil
+		}
+	}
+}
+
+// inline 读取 source 的内容，行内元素替换为占位元素
+func (p *xliffParser) inline() (inlineText, error) {
+	b := &inlineBuilder{}
+	depth := 0
+	for {
+		tok, pos, err := p.token()
+		if err != nil {
+			return inlineText{}, fmt.Errorf("formats: invalid xliff: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			b.text(string(t))
+		case xml.StartElement:
+			if !slices.Contains(xliffPaired, t.Name.Local) {
+				if _, err = p.skip(); err != nil {
+					return inlineText{}, err
+				}
+				b.protect(string(p.data[pos.start:p.dec.InputOffset()]))
+				continue
+			}
+			depth++
+			b.protect(string(p.data[pos.start:pos.end]))
+		case xml.EndElement:
+			if depth == 0 {
+				return b.done(), nil
+			}
+			depth--
+			if pos.start == pos.end {
+				// 自闭合的成对元素，开始标签已经包含了全部内容
+				continue
+			}
+			b.protect(string(p.data[pos.start:pos.end]))
+		}
+	}
+}
+
+type xliffDocument struct {
+	data     []byte
+	version  string
+	langTags []span
+	segments []*xliffSegment
+	units    []Unit
+}
+
+func (d *xliffDocument) Units() []Unit {
+	return d.units
+}
+
+type spanEdit struct {
+	span
+	text string
+}
+
+func (d *xliffDocument) Render(translations []string, targetLang string) ([]byte, error) {
+	if len(translations) != len(d.units) {
+		return nil, errUnitCount(len(d.units), len(translations))
+	}
+	var edits []spanEdit
+	tag := func(s span, name, value string) {
+		edits = append(edits, spanEdit{s, string(setXMLAttr(d.data[s.start:s.end], name, value))})
+	}
+	v1 := !xliffV2(d.version)
+	langAttr := "trgLang"
+	if v1 {
+		langAttr = "target-language"
+	}
+	for _, s := range d.langTags {
+		tag(s, langAttr, targetLang)
+	}
+	for i, seg := range d.segments {
+		text := seg.inline.restoreEscaped(strings.TrimSpace(translations[i]), xmlEscape)
+		targetName := strings.TrimSuffix(seg.sourceTag, "source") + "target"
+		open := "<" + targetName + ">"
+		if v1 {
+			open = "<" + targetName + ` state="` + xliffStateTranslated + `">`
+		}
+		switch {
+		case !seg.hasTarget:
+			edits = append(edits, spanEdit{span{seg.sourceEnd, seg.sourceEnd},
+				seg.indent + open + text + "</" + targetName + ">"})
+		case seg.targetInner == nil:
+			edits = append(edits, spanEdit{seg.targetTag, open + text + "</" + targetName + ">"})
+		default:
+			edits = append(edits, spanEdit{*seg.targetInner, text})
+			if v1 {
+				tag(seg.targetTag, "state", xliffStateTranslated)
+			}
+		}
+		if !v1 && seg.stateTag != nil {
+			tag(*seg.stateTag, "state", xliffStateTranslated)
+		}
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+	var out bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		if e.start < last {
+			// 同一个标签只修改一次
+			continue
+		}
+		out.Write(d.data[last:e.start])
+		out.WriteString(e.text)
+		last = e.end
+	}
+	out.Write(d.data[last:])
+	return out.Bytes(), nil
+}
+
+func xmlAttr(t xml.StartElement, name string) string {
+	for _, attr := range t.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// xmlTagName 开始标签中的元素名，保留命名空间前缀
+func xmlTagName(tag []byte) string {
+	name := strings.TrimPrefix(string(tag), "<")
+	if i := strings.IndexAny(name, " \t\r\n/>"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// precedingSpace pos 之前同一行的缩进，前面不是空白时返回空
+func precedingSpace(data []byte, pos int) string {
+	i := pos
+	for i > 0 && (data[i-1] == ' ' || data[i-1] == '\t') {
+		i--
+	}
+	if i > 0 && data[i-1] == '\n' {
+		start := i - 1
+		if start > 0 && data[start-1] == '\r' {
+			start--
+		}
+		return string(data[start:pos])
+	}
+	return string(data[i:pos])
+}
+
+// xliffV2 2.0 的语言写在根元素的 trgLang，状态写在 segment；1.2 写在 file 的 target-language 与 target
+func xliffV2(version string) bool {
+	return strings.HasPrefix(version, "2")
+}
+
+// setXMLAttr 修改开始标签中的属性，不存在时添加在标签末尾
+func setXMLAttr(tag []byte, name, value string) []byte {
+	pattern := regexp.MustCompile(`(\s` + regexp.QuoteMeta(name) + `\s*=\s*)(?:"[^"]*"|'[^']*')`)
+	escaped := xmlEscape(value)
+	if loc := pattern.FindSubmatchIndex(tag); loc != nil {
+		return slices.Concat(tag[:loc[3]], []byte(`"`+escaped+`"`), tag[loc[1]:])
+	}
+	end := len(tag) - 1
+	if bytes.HasSuffix(tag, []byte("/>")) {
+		end--
+	}
+	return slices.Concat(tag[:end], []byte(` `+name+`="`+escaped+`"`), tag[end:])
+}
+
+// xmlEscaper 换行保持原样，便于在 CAT 工具与文本编辑器中阅读
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\r", "&#xD;")
+
+func xmlEscape(s string) string {
+	return xmlEscaper.Replace(s)
+}
@@ -0,0 +1,94 @@
+package formats
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 下载纯文本任务时可选的双语导出格式
+const (
+	ExportXLIFF12 = "xliff"
+	ExportXLIFF20 = "xliff2"
+
+	// XLIFFContentType 导出文件的媒体类型
+	XLIFFContentType = "application/xliff+xml"
+)
+
+var paragraphSeparator = regexp.MustCompile(`\n[ \t]*(?:\r?\n[ \t]*)+`)
+
+// Pair 一组原文与译文
+type Pair struct {
+	Source string
+	Target string
+}
+
+// XLIFFVersion 导出格式对应的 XLIFF 版本
+func XLIFFVersion(format string) (string, bool) {
+	switch format {
+	case ExportXLIFF12:
+		return XLIFF12, true
+	case ExportXLIFF20:
+		return XLIFF20, true
+	}
+	return "", false
+}
+
+// AlignParagraphs 原文与译文按空行分段，段数相同时逐段对应，否则整篇作为一组
+func AlignParagraphs(source, target string) []Pair {
+	sources := splitParagraphs(source)
+	targets := splitParagraphs(target)
+	if len(sources) != len(targets) || len(sources) == 0 {
+		return []Pair{{Source: strings.TrimSpace(source), Target: strings.TrimSpace(target)}}
+	}
+	pairs := make([]Pair, len(sources))
+	for i := range sources {
+		pairs[i] = Pair{Source: sources[i], Target: targets[i]}
+	}
+	return pairs
+}
+
+func splitParagraphs(s string) []string {
+	var list []string
+	for _, p := range paragraphSeparator.Split(strings.ReplaceAll(s, "\r\n", "\n"), -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// ExportXLIFF 生成双语 XLIFF，CAT 工具可以直接导入复核，译文状态为 translated
+func ExportXLIFF(version, sourceLang, targetLang, original string, pairs []Pair) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	switch version {
+	case XLIFF12:
+		fmt.Fprintf(&b, `<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">`+"\n")
+		fmt.Fprintf(&b, `  <file original="%s" source-language="%s" target-language="%s" datatype="plaintext">`+"\n",
+			xmlEscape(original), xmlEscape(sourceLang), xmlEscape(targetLang))
+		b.WriteString("    <body>\n")
+		for i, pair := range pairs {
+			fmt.Fprintf(&b, `      <trans-unit id="%d" xml:space="preserve">`+"\n", i+1)
+			fmt.Fprintf(&b, "        <source>%s</source>\n", xmlEscape(pair.Source))
+			fmt.Fprintf(&b, `        <target state="%s">%s</target>`+"\n", xliffStateTranslated, xmlEscape(pair.Target))
+			b.WriteString("      </trans-unit>\n")
+		}
+		b.WriteString("    </body>\n  </file>\n</xliff>\n")
+	case XLIFF20:
+		fmt.Fprintf(&b, `<xliff version="2.0" xmlns="urn:oasis:names:tc:xliff:document:2.0" srcLang="%s" trgLang="%s">`+"\n",
+			xmlEscape(sourceLang), xmlEscape(targetLang))
+		fmt.Fprintf(&b, `  <file id="f1" original="%s">`+"\n", xmlEscape(original))
+		for i, pair := range pairs {
+			fmt.Fprintf(&b, `    <unit id="u%d">`+"\n", i+1)
+			fmt.Fprintf(&b, `      <segment state="%s">`+"\n", xliffStateTranslated)
+			fmt.Fprintf(&b, `        <source xml:space="preserve">%s</source>`+"\n", xmlEscape(pair.Source))
+			fmt.Fprintf(&b, `        <target xml:space="preserve">%s</target>`+"\n", xmlEscape(pair.Target))
+			b.WriteString("      </segment>\n    </unit>\n")
+		}
+		b.WriteString("  </file>\n</xliff>\n")
+	default:
+		return nil, fmt.Errorf("formats: unsupported xliff version %q", version)
+	}
+	return []byte(b.String()), nil
+}
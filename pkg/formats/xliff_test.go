@@ -0,0 +1,238 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+)
+
+const testXLIFF12 = `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="app.txt" source-language="en" datatype="plaintext">
+    <body>
+      <trans-unit id="1">
+        <source>Hello <g id="b">bold</g> world &amp; more</source>
+        <alt-trans>
+          <source>Hello</source>
+          <target>Bonjour</target>
+        </alt-trans>
+      </trans-unit>
+      <trans-unit id="2">
+        <source>Click <ph id="p">{0}</ph> to save.</source>
+        <target state="needs-translation">Cliquez</target>
+      </trans-unit>
+      <trans-unit id="3">
+        <source>Already done</source>
+        <target state="translated">Déjà fait</target>
+      </trans-unit>
+      <trans-unit id="4" translate="no">
+        <source>BrandName</source>
+      </trans-unit>
+      <trans-unit id="5">
+        <source>Empty target</source>
+        <target/>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`
+
+const testXLIFF20 = `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="2.0" xmlns="urn:oasis:names:tc:xliff:document:2.0" srcLang="en">
+  <file id="f1">
+    <unit id="u1">
+      <segment>
+        <source>Good <pc id="1">morning</pc></source>
+      </segment>
+      <segment state="final">
+        <source>Keep me</source>
+        <target>Garde-moi</target>
+      </segment>
+    </unit>
+    <unit id="u2" translate="no">
+      <segment>
+        <source>Code</source>
+      </segment>
+    </unit>
+    <unit id="u3">
+      <segment state="initial">
+        <source>Line <ph id="2"/> break</source>
+        <target></target>
+      </segment>
+    </unit>
+  </file>
+</xliff>
+`
+
+func TestXLIFFUnits(t *testing.T) {
+	cases := []struct {
+		name, data string
+		all        bool
+		want       []string
+	}{
+		{"1.2", testXLIFF12, false, []string{
+			`Hello <x id="1"/>bold<x id="2"/> world & more`,
+			`Click <x id="1"/> to save.`,
+			"Empty target",
+		}},
+		{"1.2 translate all", testXLIFF12, true, []string{
+			`Hello <x id="1"/>bold<x id="2"/> world & more`,
+			`Click <x id="1"/> to save.`,
+			"Already done",
+			"Empty target",
+		}},
+		{"2.0", testXLIFF20, false, []string{
+			`Good <x id="1"/>morning<x id="2"/>`,
+			`Line <x id="1"/> break`,
+		}},
+	}
+	handler, _ := Get(FormatXLIFF)
+	for _, c := range cases {
+		doc, err := handler.Parse([]byte(c.data), Options{TranslateAll: c.all})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := unitTexts(doc); strings.Join(got, "|") != strings.Join(c.want, "|") {
+			t.Errorf("%s units = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestXLIFFRender(t *testing.T) {
+	cases := []struct {
+		name, data   string
+		translations []string
+		want         string
+	}{
+		{
+			name: "1.2",
+			data: testXLIFF12,
+			translations: []string{
+				`Bonjour <x id="1"/>gras<x id="2"/> monde & plus`,
+				`Cliquez sur <x id="1"/> pour <enregistrer>.`,
+				"Cible vide",
+			},
+			want: strings.NewReplacer(
+				`datatype="plaintext">`, `datatype="plaintext" target-language="fr">`,
+				"</source>\n        <alt-trans>",
+				"</source>\n        <target state=\"translated\">Bonjour <g id=\"b\">gras</g> monde &amp; plus</target>\n        <alt-trans>",
+				`<target state="needs-translation">Cliquez</target>`,
+				`<target state="translated">Cliquez sur <ph id="p">{0}</ph> pour &lt;enregistrer&gt;.</target>`,
+				`<target/>`, `<target state="translated">Cible vide</target>`,
+			).Replace(testXLIFF12),
+		},
+		{
+			name:         "2.0",
+			data:         testXLIFF20,
+			translations: []string{`Bon <x id="1"/>matin<x id="2"/>`, `Ligne <x id="1"/> coupée`},
+			want: strings.NewReplacer(
+				`srcLang="en">`, `srcLang="en" trgLang="fr">`,
+				"<segment>\n        <source>Good", "<segment state=\"translated\">\n        <source>Good",
+				`morning</pc></source>`, "morning</pc></source>\n        <target>Bon <pc id=\"1\">matin</pc></target>",
+				`<segment state="initial">`, `<segment state="translated">`,
+				`<target></target>`, `<target>Ligne <ph id="2"/> coupée</target>`,
+			).Replace(testXLIFF20),
+		},
+	}
+	handler, _ := Get(FormatXLIFF)
+	for _, c := range cases {
+		doc, err := handler.Parse([]byte(c.data), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := doc.Render(c.translations, "fr")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != c.want {
+			t.Errorf("%s render:\n%s\nwant:\n%s", c.name, out, c.want)
+		}
+		// 生成的文件再次解析时没有需要翻译的条目
+		doc, err = handler.Parse(out, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if units := doc.Units(); len(units) != 0 {
+			t.Errorf("%s rendered file still has units %q", c.name, unitTexts(doc))
+		}
+	}
+}
+
+func TestXLIFFKeepsCRLFIndent(t *testing.T) {
+	data := strings.ReplaceAll(`<xliff version="1.2"><file source-language="en" target-language="de">
+	<body>
+		<trans-unit id="1">
+			<source>Hi</source>
+		</trans-unit>
+	</body>
+</file></xliff>`, "\n", "\r\n")
+	handler, _ := Get(FormatXLIFF)
+	doc, err := handler.Parse([]byte(data), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := doc.Render([]string{"Salut"}, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.NewReplacer(`target-language="de"`, `target-language="fr"`,
+		"<source>Hi</source>", "<source>Hi</source>\r\n\t\t\t<target state=\"translated\">Salut</target>").Replace(data)
+	if string(out) != want {
+		t.Errorf("render = %q\nwant %q", out, want)
+	}
+}
+
+func TestExportXLIFF(t *testing.T) {
+	pairs := AlignParagraphs("First <b>one</b>.\r\n\r\nSecond & last.", "Premier <b>un</b>.\n\n  \nSecond & dernier.")
+	if len(pairs) != 2 || pairs[1] != (Pair{"Second & last.", "Second & dernier."}) {
+		t.Fatalf("pairs = %q", pairs)
+	}
+	if merged := AlignParagraphs("A\n\nB", "A B"); len(merged) != 1 || merged[0].Source != "A\n\nB" {
+		t.Errorf("mismatched paragraphs = %q", merged)
+	}
+
+	handler, _ := Get(FormatXLIFF)
+	for _, format := range []string{ExportXLIFF12, ExportXLIFF20} {
+		version, ok := XLIFFVersion(format)
+		if !ok {
+			t.Fatalf("%s not an export format", format)
+		}
+		data, err := ExportXLIFF(version, "en", "fr", `notes "v1".txt`, pairs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 导出的译文状态为已翻译，只有重新翻译时才作为单元
+		doc, err := handler.Parse(data, Options{})
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", format, err, data)
+		}
+		if len(doc.Units()) != 0 {
+			t.Errorf("%s exported pending units %q", format, unitTexts(doc))
+		}
+		doc, err = handler.Parse(data, Options{TranslateAll: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := unitTexts(doc); len(got) != 2 || got[0] != "First <b>one</b>." || got[1] != "Second & last." {
+			t.Errorf("%s exported sources = %q", format, got)
+		}
+		if !strings.Contains(string(data), `Premier &lt;b&gt;un&lt;/b&gt;.`) ||
+			!strings.Contains(string(data), `notes &quot;v1&quot;.txt`) {
+			t.Errorf("%s export not escaped:\n%s", format, data)
+		}
+	}
+	if _, ok := XLIFFVersion("docx"); ok {
+		t.Error("docx accepted as export format")
+	}
+	if _, err := ExportXLIFF("3.0", "en", "fr", "a.txt", pairs); err == nil {
+		t.Error("unknown version accepted")
+	}
+}
+
+func TestXLIFFInvalid(t *testing.T) {
+	handler, _ := Get(FormatXLIFF)
+	for _, data := range []string{"<root><source>x</source></root>", `<xliff version="1.2"><file>`} {
+		if _, err := handler.Parse([]byte(data), Options{}); err == nil {
+			t.Errorf("%q accepted", data)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package glossary
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Entry 一条术语
+type Entry struct {
+	Term           string `json:"term"`
+	Translation    string `json:"translation"`
+	CaseSensitive  bool   `json:"case_sensitive"`
+	DoNotTranslate bool   `json:"do_not_translate"`
+}
+
+// Expected 译文中应当出现的文本，不翻译的术语保持原样
+func (e *Entry) Expected() string {
+	if e.DoNotTranslate || e.Translation == "" {
+		return e.Term
+	}
+	return e.Translation
+}
+
+// Violation 译文中缺失的术语
+type Violation struct {
+	Term     string `json:"term"`
+	Expected string `json:"expected"`
+}
+
+// Match 返回在 content 中出现过的术语，结果按术语长度降序，避免短术语覆盖长术语
+func Match(entries []Entry, content string) []Entry {
+	var matched []Entry
+	for _, e := range entries {
+		if e.Term != "" && contains(content, e.Term, e.CaseSensitive) {
+			matched = append(matched, e)
+		}
+	}
+	for i := 1; i < len(matched); i++ {
+		for j := i; j > 0 && len(matched[j].Term) > len(matched[j-1].Term); j-- {
+			matched[j], matched[j-1] = matched[j-1], matched[j]
+		}
+	}
+	return matched
+}
+
+// Verify 检查 matched 中的每条术语是否都按要求出现在译文中
+func Verify(matched []Entry, translation string) []Violation {
+	var violations []Violation
+	for _, e := range matched {
+		expected := e.Expected()
+		if !contains(translation, expected, e.CaseSensitive) {
+			violations = append(violations, Violation{
+				Term:     e.Term,
+				Expected: expected,
+			})
+		}
+	}
+	return violations
+}
+
+// contains 查找 term，以字母或数字开头/结尾的术语要求在单词边界上出现，
+// 避免 "cat" 命中 "category"；CJK 等不以空格分词的文字不做边界限制
+func contains(text, term string, caseSensitive bool) bool {
+	if term == "" {
+		return false
+	}
+	if !caseSensitive {
+		text = strings.ToLower(text)
+		term = strings.ToLower(term)
+	}
+	first, _ := utf8.DecodeRuneInString(term)
+	last, _ := utf8.DecodeLastRuneInString(term)
+	checkStart, checkEnd := needBoundary(first), needBoundary(last)
+	offset := 0
+	for {
+		idx := strings.Index(text[offset:], term)
+		if idx < 0 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		okStart := !checkStart || start == 0 || !isWordRune(before)
+		okEnd := !checkEnd || end == len(text) || !isWordRune(after)
+		if okStart && okEnd {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+}
+
+func needBoundary(r rune) bool {
+	return isWordRune(r) && !isUnspacedScript(r)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func isUnspacedScript(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+}
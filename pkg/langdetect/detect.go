@@ -0,0 +1,159 @@
+package langdetect
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Result 检测结果，无法判断时 Lang 为空
+type Result struct {
+	Lang       string  `json:"lang"`
+	Confidence float64 `json:"confidence"`
+}
+
+const (
+	// 参与检测的最大字符数，长文本取前面一部分即可
+	maxSampleRunes = 2000
+	// 少于该字母数时不做判断
+	minLetters = 3
+	// 平滑系数
+	smoothing = 0.5
+	// 对数似然差与置信度之间的缩放系数，样本语料较小，避免置信度过高
+	confidenceScale = 1.25
+)
+
+// Detect 识别文本的语言，返回 ISO 639-1 语言代码
+func Detect(text string) Result {
+	runes := []rune(text)
+	if len(runes) > maxSampleRunes {
+		runes = runes[:maxSampleRunes]
+	}
+	sample := string(runes)
+
+	counts, letters := countScripts(sample)
+	if letters < minLetters {
+		return Result{}
+	}
+	script, scriptCount := dominantScript(counts)
+	ratio := float64(scriptCount) / float64(letters)
+
+	// 日文通常混用汉字与假名，只要假名占有一定比例就判定为日文
+	if kana := counts[scriptKana]; kana > 0 && float64(kana)/float64(letters) >= 0.1 {
+		return Result{Lang: "ja", Confidence: round(math.Min(1, float64(kana+counts[scriptHan])/float64(letters)))}
+	}
+	if lang, ok := uniqueScriptLang[script]; ok {
+		return Result{Lang: lang, Confidence: round(ratio)}
+	}
+	candidates, ok := scriptCandidates[script]
+	if !ok {
+		return Result{}
+	}
+	lang, confidence := classify(sample, candidates)
+	return Result{Lang: lang, Confidence: round(confidence * ratio)}
+}
+
+// classify 使用字符 n-gram 的朴素贝叶斯模型在候选语言中打分
+func classify(text string, candidates []string) (string, float64) {
+	lang, confidence := score(ngrams(text), candidates)
+	if lang == "" || confidence == 1 {
+		return lang, confidence
+	}
+	return lang, confidence * agreement(text, lang, candidates)
+}
+
+// score 返回得分最高的语言及置信度，只有一个候选语言时置信度为 1
+func score(grams map[string]int, candidates []string) (string, float64) {
+	if len(grams) == 0 {
+		return "", 0
+	}
+	best, second := math.Inf(-1), math.Inf(-1)
+	bestLang := ""
+	total := 0
+	for _, n := range grams {
+		total += n
+	}
+	for _, lang := range candidates {
+		p, ok := profiles[lang]
+		if !ok {
+			continue
+		}
+		likelihood := 0.0
+		for gram, n := range grams {
+			likelihood += float64(n) * math.Log((float64(p.counts[gram])+smoothing)/p.denominator)
+		}
+		if likelihood > best {
+			second = best
+			best = likelihood
+			bestLang = lang
+		} else if likelihood > second {
+			second = likelihood
+		}
+	}
+	if bestLang == "" {
+		return "", 0
+	}
+	if math.IsInf(second, -1) {
+		return bestLang, 1
+	}
+	// 对数似然差随文本长度线性增长，直接换算会让几乎所有句子的置信度接近 1，
+	// 这里按 n-gram 数的平方根归一化：平均每个 n-gram 的差值体现区分度，平方根体现样本量，
+	// 短文本与相近语言的置信度都较低，差值为 0 时为 0
+	margin := (best - second) / math.Sqrt(float64(total))
+	return bestLang, 1 - math.Exp(-margin/confidenceScale)
+}
+
+// agreement 逐句判断语言，返回判断结果为 lang 的句子所占的比例，按句子的长度与置信度加权
+// 各句分别是不同语言的混合文本整体上仍可能有明显的最优语言，按句子比较才能发现；
+// 难以区分的短句权重很低，不影响结果
+func agreement(text, lang string, candidates []string) float64 {
+	sentences := strings.FieldsFunc(text, func(r rune) bool {
+		return strings.ContainsRune(".!?;。！？；\n", r)
+	})
+	if len(sentences) < 2 {
+		return 1
+	}
+	matched, total := 0.0, 0.0
+	for _, sentence := range sentences {
+		grams := ngrams(sentence)
+		sentenceLang, confidence := score(grams, candidates)
+		weight := 0.0
+		for _, n := range grams {
+			weight += float64(n)
+		}
+		weight *= confidence
+		total += weight
+		if sentenceLang == lang {
+			matched += weight
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return matched / total
+}
+
+// ngrams 提取 1 至 3 元字符组，单词首尾补空格，忽略数字与标点
+// 单字符可以体现各语言特有的字母，三元组体现常见词形
+func ngrams(text string) map[string]int {
+	grams := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r) && !unicode.Is(unicode.Mc, r)
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				if n == 1 && runes[i] == ' ' {
+					continue
+				}
+				grams[string(runes[i:i+n])]++
+			}
+		}
+	}
+	return grams
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
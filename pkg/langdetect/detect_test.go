@@ -0,0 +1,54 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		lang string
+		// 置信度应不低于 min 且低于 max
+		min, max float64
+	}{
+		{"english", "Please review the attached document and send me your comments by Friday.", "en", 0.9, 1.01},
+		{"german", "Bitte prüfen Sie das beigefügte Dokument und senden Sie mir Ihre Kommentare bis Freitag.", "de", 0.9, 1.01},
+		{"french", "Nous nous verrons demain matin au bureau pour discuter du nouveau projet.", "fr", 0.9, 1.01},
+		{"russian", "Привет, как дела? Сегодня хорошая погода, пойдём гулять в парк.", "ru", 0.8, 1.01},
+		{"chinese", "今天天气很好，我们去公园散步吧。", "zh", 0.9, 1.01},
+		{"japanese", "今日はいい天気ですね。", "ja", 0.9, 1.01},
+		{"korean", "안녕하세요, 만나서 반갑습니다.", "ko", 0.9, 1.01},
+
+		// 短文本区分度低
+		{"short word", "Hello", "", 0, 0.5},
+		{"short russian", "Привет, как дела?", "", 0, 0.5},
+		{"short czech", "Dobrý den, jak se máte?", "", 0, 0.5},
+		{"too few letters", "OK 123 !!!", "", 0, 0.01},
+
+		// 相近语言
+		{"norwegian or swedish", "Jeg har det bra, takk.", "", 0, 0.5},
+		{"swedish or danish", "Hej, hur mår du?", "", 0, 0.5},
+
+		// 混合语言的句子各自明确，整体置信度较低
+		{"english and german", "The meeting is at ten. Die Besprechung ist um zehn.", "", 0, 0.8},
+		{"english and french", "We will meet tomorrow morning at the office. Nous nous verrons demain matin au bureau.", "", 0, 0.8},
+		{"latin and han", "Hello 你好", "", 0, 0.5},
+	}
+	for _, c := range cases {
+		result := Detect(c.text)
+		if c.lang != "" && result.Lang != c.lang {
+			t.Errorf("%s: lang = %q, want %q", c.name, result.Lang, c.lang)
+		}
+		if result.Confidence < c.min || result.Confidence >= c.max {
+			t.Errorf("%s: confidence = %.2f (%s), want [%.2f, %.2f)",
+				c.name, result.Confidence, result.Lang, c.min, c.max)
+		}
+	}
+}
+
+func TestConfidenceGrowsWithLength(t *testing.T) {
+	short := Detect("Das ist gut.")
+	long := Detect("Das ist gut. Wir gehen morgen zusammen in den Park und essen danach im Restaurant.")
+	if short.Lang != "de" || long.Lang != "de" || long.Confidence <= short.Confidence {
+		t.Errorf("short = %+v, long = %+v", short, long)
+	}
+}
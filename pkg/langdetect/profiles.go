@@ -0,0 +1,97 @@
+package langdetect
+
+// samples 各语言的训练语料，包含《世界人权宣言》第一条及若干日常用语
+// 初始化时据此生成 n-gram 频率表
+var samples = map[string]string{
+	"en": "All human beings are born free and equal in dignity and rights. They are endowed with reason and conscience and should act towards one another in a spirit of brotherhood. Everyone has the right to education. This is the first time that we have seen such a thing, and we would like to know what you think about it. The weather is very nice today, so we are going to the market with our friends and the children. Please send me the report when it is ready and let me know if there is anything else I can do for you. Our company has been working in this market for many years. We offer our customers high quality services and new solutions. Tomorrow there will be a meeting in the office, where the director will talk about new projects and plans for the next year. If you have any questions, please contact us by phone or email.",
+	"fr": "Tous les êtres humains naissent libres et égaux en dignité et en droits. Ils sont doués de raison et de conscience et doivent agir les uns envers les autres dans un esprit de fraternité. Toute personne a droit à l'éducation. C'est la première fois que nous voyons une telle chose, et nous aimerions savoir ce que vous en pensez. Il fait très beau aujourd'hui, alors nous allons au marché avec nos amis et les enfants. Merci de m'envoyer le rapport quand il sera prêt et de me dire si je peux faire autre chose pour vous. Notre entreprise travaille sur ce marché depuis de nombreuses années. Nous proposons à nos clients des services de qualité et de nouvelles solutions. Demain, une réunion aura lieu au bureau, où le directeur parlera des nouveaux projets et des projets pour l'année prochaine. Si vous avez des questions, veuillez nous contacter par téléphone ou par courriel.",
+	"de": "Alle Menschen sind frei und gleich an Würde und Rechten geboren. Sie sind mit Vernunft und Gewissen begabt und sollen einander im Geist der Brüderlichkeit begegnen. Jeder hat das Recht auf Bildung. Das ist das erste Mal, dass wir so etwas sehen, und wir möchten wissen, was Sie darüber denken. Das Wetter ist heute sehr schön, deshalb gehen wir mit unseren Freunden und den Kindern auf den Markt. Bitte schicken Sie mir den Bericht, wenn er fertig ist, und sagen Sie mir, ob ich noch etwas für Sie tun kann. Unser Unternehmen ist seit vielen Jahren auf dem Markt tätig. Wir bieten unseren Kunden hochwertige Dienstleistungen und neue Lösungen. Morgen findet im Büro eine Besprechung statt, bei der der Direktor über neue Projekte und die Pläne für das nächste Jahr sprechen wird. Wenn Sie Fragen haben, kontaktieren Sie uns bitte telefonisch oder per E-Mail.",
+	"es": "Todos los seres humanos nacen libres e iguales en dignidad y derechos y, dotados como están de razón y conciencia, deben comportarse fraternalmente los unos con los otros. Toda persona tiene derecho a la educación. Es la primera vez que vemos una cosa así, y nos gustaría saber qué piensa usted de ello. Hoy hace muy buen tiempo, así que vamos al mercado con nuestros amigos y los niños. Por favor, envíeme el informe cuando esté listo y dígame si puedo hacer algo más por usted. Nuestra empresa lleva muchos años trabajando en el mercado. Ofrecemos a nuestros clientes servicios de calidad y nuevas soluciones. Mañana habrá una reunión en la oficina, donde el director hablará de los nuevos proyectos y de los planes para el próximo año. Si tiene alguna pregunta, póngase en contacto con nosotros por teléfono o por correo electrónico.",
+	"it": "Tutti gli esseri umani nascono liberi ed eguali in dignità e diritti. Essi sono dotati di ragione e di coscienza e devono agire gli uni verso gli altri in spirito di fratellanza. Ogni individuo ha diritto all'istruzione. È la prima volta che vediamo una cosa del genere e vorremmo sapere che cosa ne pensa. Oggi il tempo è molto bello, quindi andiamo al mercato con i nostri amici e i bambini. Per favore, mi mandi la relazione quando è pronta e mi faccia sapere se posso fare qualcos'altro per lei. La nostra azienda lavora sul mercato da molti anni. Offriamo ai nostri clienti servizi di qualità e nuove soluzioni. Domani si terrà una riunione in ufficio, durante la quale il direttore parlerà dei nuovi progetti e dei piani per il prossimo anno. Se avete domande, contattateci per telefono o per posta elettronica.",
+	"pt": "Todos os seres humanos nascem livres e iguais em dignidade e em direitos. Dotados de razão e de consciência, devem agir uns para com os outros em espírito de fraternidade. Toda a pessoa tem direito à educação. É a primeira vez que vemos uma coisa assim, e gostaríamos de saber o que você pensa sobre isso. Hoje o tempo está muito bom, então vamos ao mercado com os nossos amigos e as crianças. Por favor, envie-me o relatório quando estiver pronto e diga-me se posso fazer mais alguma coisa por você. A nossa empresa trabalha no mercado há muitos anos. Oferecemos aos nossos clientes serviços de qualidade e novas soluções. Amanhã haverá uma reunião no escritório, onde o diretor vai falar sobre os novos projetos e os planos para o próximo ano. Se tiver alguma dúvida, entre em contato conosco por telefone ou por e-mail.",
+	"nl": "Alle mensen worden vrij en gelijk in waardigheid en rechten geboren. Zij zijn begiftigd met verstand en geweten, en behoren zich jegens elkander in een geest van broederschap te gedragen. Iedereen heeft recht op onderwijs. Het is de eerste keer dat we zoiets zien, en we willen graag weten wat u daarvan vindt. Het weer is vandaag erg mooi, dus we gaan met onze vrienden en de kinderen naar de markt. Stuur me alstublieft het rapport als het klaar is en laat me weten of ik nog iets voor u kan doen. Ons bedrijf is al vele jaren actief op de markt. Wij bieden onze klanten hoogwaardige diensten en nieuwe oplossingen. Morgen is er een vergadering op kantoor, waar de directeur zal vertellen over nieuwe projecten en de plannen voor volgend jaar. Als u vragen heeft, neem dan telefonisch of per e-mail contact met ons op.",
+	"sv": "Alla människor är födda fria och lika i värde och rättigheter. De har utrustats med förnuft och samvete och bör handla gentemot varandra i en anda av broderskap. Var och en har rätt till utbildning. Det är första gången vi ser något sådant, och vi skulle vilja veta vad du tycker om det. Vädret är mycket fint i dag, så vi går till torget med våra vänner och barnen. Skicka mig rapporten när den är klar och låt mig veta om det är något mer jag kan göra för dig. Vårt företag har varit verksamt på marknaden i många år. Vi erbjuder våra kunder tjänster av hög kvalitet och nya lösningar. I morgon hålls ett möte på kontoret, där direktören kommer att berätta om nya projekt och planerna för nästa år. Om du har några frågor är du välkommen att kontakta oss via telefon eller e-post.",
+	"da": "Alle mennesker er født frie og lige i værdighed og rettigheder. De er udstyret med fornuft og samvittighed, og de bør handle mod hverandre i en broderskabets ånd. Enhver har ret til uddannelse. Det er første gang, vi ser noget lignende, og vi vil gerne vide, hvad du synes om det. Vejret er meget godt i dag, så vi går på torvet med vores venner og børnene. Send mig venligst rapporten, når den er færdig, og lad mig vide, om der er noget andet, jeg kan gøre for dig. Vores virksomhed har været aktiv på markedet i mange år. Vi tilbyder vores kunder tjenester af høj kvalitet og nye løsninger. I morgen holdes der et møde på kontoret, hvor direktøren vil fortælle om nye projekter og planerne for næste år. Hvis du har spørgsmål, er du velkommen til at kontakte os via telefon eller e-mail.",
+	"nb": "Alle mennesker er født frie og med samme menneskeverd og menneskerettigheter. De er utstyrt med fornuft og samvittighet og bør handle mot hverandre i brorskapets ånd. Enhver har rett til utdanning. Det er første gang vi ser noe slikt, og vi vil gjerne vite hva du synes om det. Været er veldig fint i dag, så vi går på torget med vennene våre og barna. Vennligst send meg rapporten når den er ferdig, og gi meg beskjed om det er noe mer jeg kan gjøre for deg. Selskapet vårt har vært aktivt i markedet i mange år. Vi tilbyr kundene våre tjenester av høy kvalitet og nye løsninger. I morgen blir det holdt et møte på kontoret, der direktøren skal fortelle om nye prosjekter og planene for neste år. Hvis du har spørsmål, kan du gjerne kontakte oss på telefon eller e-post.",
+	"fi": "Kaikki ihmiset syntyvät vapaina ja tasavertaisina arvoltaan ja oikeuksiltaan. Heille on annettu järki ja omatunto, ja heidän on toimittava toisiaan kohtaan veljeyden hengessä. Jokaisella on oikeus saada opetusta. Tämä on ensimmäinen kerta, kun näemme jotain tällaista, ja haluaisimme tietää, mitä mieltä olet siitä. Tänään on todella kaunis sää, joten menemme torille ystäviemme ja lasten kanssa. Lähetä minulle raportti, kun se on valmis, ja kerro, voinko tehdä sinulle vielä jotain muuta. Yrityksemme on toiminut markkinoilla jo monta vuotta. Tarjoamme asiakkaillemme laadukkaita palveluja ja uusia ratkaisuja. Huomenna toimistolla pidetään kokous, jossa johtaja kertoo uusista hankkeista ja ensi vuoden suunnitelmista. Jos teillä on kysyttävää, ottakaa meihin yhteyttä puhelimitse tai sähköpostilla.",
+	"pl": "Wszyscy ludzie rodzą się wolni i równi pod względem swej godności i swych praw. Są oni obdarzeni rozumem i sumieniem i powinni postępować wobec innych w duchu braterstwa. Każdy człowiek ma prawo do nauki. To pierwszy raz, kiedy widzimy coś takiego, i chcielibyśmy wiedzieć, co pan o tym myśli. Dzisiaj jest bardzo ładna pogoda, więc idziemy na targ z naszymi przyjaciółmi i dziećmi. Proszę przesłać mi raport, kiedy będzie gotowy, i dać mi znać, czy mogę jeszcze coś dla pana zrobić. Nasza firma działa na rynku od wielu lat. Oferujemy naszym klientom usługi wysokiej jakości i nowe rozwiązania. Jutro w biurze odbędzie się spotkanie, na którym dyrektor opowie o nowych projektach i planach na przyszły rok. Jeśli mają Państwo jakiekolwiek pytania, prosimy o kontakt telefoniczny lub mailowy.",
+	"cs": "Všichni lidé rodí se svobodní a sobě rovní co do důstojnosti a práv. Jsou nadáni rozumem a svědomím a mají spolu jednat v duchu bratrství. Každý má právo na vzdělání. Je to poprvé, co vidíme něco takového, a rádi bychom věděli, co si o tom myslíte. Dnes je velmi hezké počasí, takže jdeme na trh s našimi přáteli a dětmi. Pošlete mi prosím zprávu, až bude hotová, a dejte mi vědět, jestli pro vás mohu udělat ještě něco dalšího. Naše společnost působí na trhu již mnoho let. Našim zákazníkům nabízíme kvalitní služby a nová řešení. Zítra se v kanceláři uskuteční schůzka, na které ředitel promluví o nových projektech a plánech na příští rok. Pokud máte jakékoli dotazy, kontaktujte nás prosím telefonicky nebo e-mailem.",
+	"sk": "Všetci ľudia sa rodia slobodní a sebe rovní, čo sa týka ich dôstojnosti a práv. Sú obdarení rozumom a svedomím a majú spolu jednať v bratskom duchu. Každý má právo na vzdelanie. Je to prvýkrát, čo vidíme niečo také, a radi by sme vedeli, čo si o tom myslíte. Dnes je veľmi pekné počasie, takže ideme na trh s našimi priateľmi a deťmi. Pošlite mi, prosím, správu, keď bude hotová, a dajte mi vedieť, či pre vás môžem urobiť ešte niečo ďalšie. Naša spoločnosť pôsobí na trhu už mnoho rokov. Našim zákazníkom ponúkame kvalitné služby a nové riešenia. Zajtra sa v kancelárii uskutoční stretnutie, na ktorom riaditeľ porozpráva o nových projektoch a plánoch na budúci rok. Ak máte akékoľvek otázky, kontaktujte nás telefonicky alebo e-mailom.",
+	"ro": "Toate ființele umane se nasc libere și egale în demnitate și în drepturi. Ele sunt înzestrate cu rațiune și conștiință și trebuie să se comporte unele față de altele în spiritul fraternității. Orice persoană are dreptul la învățătură. Este prima dată când vedem așa ceva și am dori să știm ce credeți despre asta. Astăzi vremea este foarte frumoasă, așa că mergem la piață cu prietenii noștri și cu copiii. Vă rog să îmi trimiteți raportul când este gata și să îmi spuneți dacă mai pot face ceva pentru dumneavoastră. Compania noastră activează pe piață de mulți ani. Oferim clienților noștri servicii de calitate și soluții noi. Mâine va avea loc o întâlnire la birou, unde directorul va vorbi despre proiectele noi și planurile pentru anul viitor. Dacă aveți întrebări, vă rugăm să ne contactați prin telefon sau prin e-mail.",
+	"hu": "Minden emberi lény szabadnak születik és egyenlő méltósága és joga van. Az emberek, ésszel és lelkiismerettel bírván, egymással szemben testvéri szellemben kell hogy viseltessenek. Minden személynek joga van a neveléshez. Ez az első alkalom, hogy ilyesmit látunk, és szeretnénk tudni, mit gondol erről. Ma nagyon szép idő van, ezért a barátainkkal és a gyerekekkel a piacra megyünk. Kérem, küldje el nekem a jelentést, amikor elkészült, és szóljon, ha tehetek még valamit az Ön érdekében. Cégünk már sok éve működik a piacon. Ügyfeleinknek minőségi szolgáltatásokat és új megoldásokat kínálunk. Holnap megbeszélést tartunk az irodában, ahol az igazgató az új projektekről és a jövő évi tervekről fog beszélni. Ha bármilyen kérdése van, kérjük, vegye fel velünk a kapcsolatot telefonon vagy e-mailben.",
+	"tr": "Bütün insanlar hür, haysiyet ve haklar bakımından eşit doğarlar. Akıl ve vicdana sahiptirler ve birbirlerine karşı kardeşlik zihniyeti ile hareket etmelidirler. Herkesin eğitim hakkı vardır. Böyle bir şeyi ilk kez görüyoruz ve bu konuda ne düşündüğünüzü bilmek istiyoruz. Bugün hava çok güzel, bu yüzden arkadaşlarımız ve çocuklarla birlikte pazara gidiyoruz. Lütfen rapor hazır olduğunda bana gönderin ve sizin için yapabileceğim başka bir şey olup olmadığını bana bildirin. Şirketimiz uzun yıllardır piyasada faaliyet göstermektedir. Müşterilerimize kaliteli hizmetler ve yeni çözümler sunuyoruz. Yarın ofiste bir toplantı yapılacak ve müdür yeni projeler ile gelecek yılın planları hakkında konuşacak. Herhangi bir sorunuz varsa lütfen bizimle telefon veya e-posta yoluyla iletişime geçin.",
+	"id": "Semua orang dilahirkan merdeka dan mempunyai martabat dan hak-hak yang sama. Mereka dikaruniai akal dan hati nurani dan hendaknya bergaul satu sama lain dalam semangat persaudaraan. Setiap orang berhak mendapat pendidikan. Ini adalah pertama kalinya kami melihat hal seperti itu, dan kami ingin tahu apa pendapat Anda tentang hal itu. Cuaca hari ini sangat bagus, jadi kami pergi ke pasar bersama teman-teman dan anak-anak. Tolong kirimkan laporannya kepada saya jika sudah siap dan beri tahu saya jika ada hal lain yang bisa saya lakukan untuk Anda. Perusahaan kami telah bekerja di pasar ini selama bertahun-tahun. Kami menawarkan layanan berkualitas dan solusi baru kepada pelanggan kami. Besok akan ada rapat di kantor, di mana direktur akan berbicara tentang proyek-proyek baru dan rencana untuk tahun depan. Jika Anda memiliki pertanyaan, silakan hubungi kami melalui telepon atau surel.",
+	"vi": "Tất cả mọi người sinh ra đều được tự do và bình đẳng về nhân phẩm và quyền lợi. Mọi con người đều được tạo hóa ban cho lý trí và lương tâm và cần phải đối xử với nhau trong tình anh em. Mọi người đều có quyền được học hành. Đây là lần đầu tiên chúng tôi thấy một điều như vậy, và chúng tôi muốn biết bạn nghĩ gì về điều đó. Hôm nay trời rất đẹp, vì vậy chúng tôi đi chợ cùng với bạn bè và các con. Vui lòng gửi cho tôi báo cáo khi nó đã sẵn sàng và cho tôi biết nếu tôi có thể làm gì khác cho bạn. Công ty chúng tôi đã hoạt động trên thị trường nhiều năm. Chúng tôi cung cấp cho khách hàng các dịch vụ chất lượng cao và những giải pháp mới. Ngày mai sẽ có một cuộc họp tại văn phòng, nơi giám đốc sẽ nói về các dự án mới và kế hoạch cho năm tới. Nếu bạn có bất kỳ câu hỏi nào, vui lòng liên hệ với chúng tôi qua điện thoại hoặc thư điện tử.",
+	"hr": "Sva ljudska bića rađaju se slobodna i jednaka u dostojanstvu i pravima. Ona su obdarena razumom i sviješću i trebaju jedna prema drugima postupati u duhu bratstva. Svatko ima pravo na obrazovanje. Ovo je prvi put da vidimo nešto takvo, i željeli bismo znati što mislite o tome. Danas je vrijeme vrlo lijepo, pa idemo na tržnicu s našim prijateljima i djecom. Molim vas da mi pošaljete izvješće kada bude gotovo i javite mi mogu li još nešto učiniti za vas. Naša tvrtka već dugi niz godina posluje na tržištu. Našim klijentima nudimo kvalitetne usluge i nova rješenja. Sutra će se u uredu održati sastanak na kojem će direktor govoriti o novim projektima i planovima za sljedeću godinu. Ako imate bilo kakvih pitanja, molimo vas da nas kontaktirate telefonom ili e-poštom.",
+	"sl": "Vsi ljudje se rodijo svobodni in imajo enako dostojanstvo in enake pravice. Obdarjeni so z razumom in vestjo in bi morali ravnati drug z drugim kakor bratje. Vsakdo ima pravico do izobraževanja. To je prvič, da vidimo kaj takega, in radi bi vedeli, kaj mislite o tem. Danes je zelo lepo vreme, zato gremo na tržnico s prijatelji in otroki. Prosim, pošljite mi poročilo, ko bo pripravljeno, in mi sporočite, ali lahko še kaj naredim za vas. Naše podjetje že vrsto let deluje na trgu. Našim strankam ponujamo kakovostne storitve in nove rešitve. Jutri bo v pisarni sestanek, na katerem bo direktor predstavil nove projekte in načrte za prihodnje leto. Če imate kakršna koli vprašanja, nas kontaktirajte po telefonu ali elektronski pošti.",
+	"ca": "Tots els éssers humans neixen lliures i iguals en dignitat i en drets. Són dotats de raó i de consciència, i han de comportar-se fraternalment els uns amb els altres. Tota persona té dret a l'educació. És la primera vegada que veiem una cosa així, i ens agradaria saber què en penseu. Avui fa molt bon temps, així que anem al mercat amb els nostres amics i els nens. Si us plau, envieu-me l'informe quan estigui enllestit i feu-me saber si puc fer alguna cosa més per vosaltres. La nostra empresa treballa al mercat des de fa molts anys. Oferim als nostres clients serveis de qualitat i noves solucions. Demà hi haurà una reunió a l'oficina, on el director parlarà dels nous projectes i dels plans per a l'any vinent. Si teniu cap pregunta, poseu-vos en contacte amb nosaltres per telèfon o per correu electrònic.",
+	"lt": "Visi žmonės gimsta laisvi ir lygūs savo orumu ir teisėmis. Jiems suteiktas protas ir sąžinė, ir jie turi elgtis vienas kito atžvilgiu kaip broliai. Kiekvienas žmogus turi teisę į mokslą. Tai pirmas kartas, kai matome kažką panašaus, ir norėtume sužinoti, ką jūs apie tai manote. Šiandien oras labai gražus, todėl einame į turgų su draugais ir vaikais. Prašau atsiųsti man ataskaitą, kai ji bus paruošta, ir pranešti, ar galiu dar kuo nors jums padėti. Mūsų įmonė rinkoje dirba jau daugelį metų. Savo klientams siūlome kokybiškas paslaugas ir naujus sprendimus. Rytoj biure vyks susitikimas, kuriame direktorius papasakos apie naujus projektus ir ateinančių metų planus. Jei turite klausimų, susisiekite su mumis telefonu arba elektroniniu paštu.",
+	"lv": "Visi cilvēki piedzimst brīvi un vienlīdzīgi savā pašcieņā un tiesībās. Viņi ir apveltīti ar saprātu un sirdsapziņu, un viņiem jāizturas citam pret citu brālības garā. Ikvienam ir tiesības uz izglītību. Šī ir pirmā reize, kad mēs redzam kaut ko tādu, un mēs gribētu zināt, ko jūs par to domājat. Šodien ir ļoti jauks laiks, tāpēc mēs ejam uz tirgu kopā ar draugiem un bērniem. Lūdzu, nosūtiet man atskaiti, kad tā būs gatava, un paziņojiet, vai es varu vēl kaut ko darīt jūsu labā. Mūsu uzņēmums tirgū darbojas jau daudzus gadus. Mēs piedāvājam saviem klientiem kvalitatīvus pakalpojumus un jaunus risinājumus. Rīt birojā notiks sanāksme, kurā direktors pastāstīs par jaunajiem projektiem un nākamā gada plāniem. Ja jums ir kādi jautājumi, lūdzu, sazinieties ar mums pa tālruni vai e-pastu.",
+	"et": "Kõik inimesed sünnivad vabadena ja võrdsetena oma väärikuselt ja õigustelt. Neile on antud mõistus ja südametunnistus ja nende suhtumist üksteisesse peab kandma vendluse vaim. Igaühel on õigus haridusele. See on esimene kord, kui me midagi sellist näeme, ja me tahaksime teada, mida te sellest arvate. Täna on väga ilus ilm, nii et me läheme sõprade ja lastega turule. Palun saatke mulle aruanne, kui see on valmis, ja andke teada, kas ma saan teie heaks veel midagi teha. Meie ettevõte on turul tegutsenud juba palju aastaid. Pakume oma klientidele kvaliteetseid teenuseid ja uusi lahendusi. Homme toimub kontoris koosolek, kus direktor räägib uutest projektidest ja järgmise aasta plaanidest. Kui teil on küsimusi, võtke meiega ühendust telefoni või e-posti teel.",
+
+	"ru": "Все люди рождаются свободными и равными в своем достоинстве и правах. Они наделены разумом и совестью и должны поступать в отношении друг друга в духе братства. Каждый человек имеет право на образование. Это первый раз, когда мы видим что-то подобное, и мы хотели бы знать, что вы об этом думаете. Сегодня очень хорошая погода, поэтому мы идём на рынок с нашими друзьями и детьми. Пожалуйста, пришлите мне отчёт, когда он будет готов, и сообщите, могу ли я ещё что-нибудь для вас сделать. Наша компания работает на рынке уже много лет. Мы предлагаем нашим клиентам качественные услуги и новые решения. Завтра в офисе пройдёт встреча, на которой директор расскажет о новых проектах и планах на следующий год. Если у вас есть какие-либо вопросы, пожалуйста, свяжитесь с нами по телефону или электронной почте.",
+	"uk": "Всі люди народжуються вільними і рівними у своїй гідності та правах. Вони наділені розумом і совістю і повинні діяти у відношенні один до одного в дусі братерства. Кожна людина має право на освіту. Це перший раз, коли ми бачимо щось подібне, і ми хотіли б знати, що ви про це думаєте. Сьогодні дуже гарна погода, тому ми йдемо на ринок з нашими друзями та дітьми. Будь ласка, надішліть мені звіт, коли він буде готовий, і повідомте, чи можу я ще щось для вас зробити. Наша компанія працює на ринку вже багато років. Ми пропонуємо нашим клієнтам якісні послуги та нові рішення. Завтра в офісі відбудеться зустріч, на якій директор розповість про нові проєкти та плани на наступний рік. Якщо у вас є будь-які питання, будь ласка, зв'яжіться з нами телефоном або електронною поштою.",
+	"bg": "Всички хора се раждат свободни и равни по достойнство и права. Те са надарени с разум и съвест и следва да се отнасят помежду си в дух на братство. Всеки човек има право на образование. Това е първият път, когато виждаме нещо подобно, и бихме искали да знаем какво мислите за това. Днес времето е много хубаво, затова отиваме на пазара с нашите приятели и децата. Моля, изпратете ми доклада, когато е готов, и ми кажете дали мога да направя още нещо за вас. Нашата компания работи на пазара от много години. Предлагаме на нашите клиенти качествени услуги и нови решения. Утре в офиса ще се проведе среща, на която директорът ще разкаже за новите проекти и плановете за следващата година. Ако имате някакви въпроси, моля, свържете се с нас по телефона или по електронната поща.",
+	"sr": "Сва људска бића рађају се слободна и једнака у достојанству и правима. Она су обдарена разумом и свешћу и треба једни према другима да поступају у духу братства. Свако има право на образовање. Ово је први пут да видимо нешто такво, и желели бисмо да знамо шта мислите о томе. Данас је време веома лепо, па идемо на пијацу са нашим пријатељима и децом. Молим вас да ми пошаљете извештај када буде готов и јавите ми да ли могу још нешто да урадим за вас. Наша компанија ради на тржишту већ много година. Нудимо нашим клијентима квалитетне услуге и нова решења. Сутра ће у канцеларији бити одржан састанак на коме ће директор говорити о новим пројектима и плановима за следећу годину. Ако имате било каква питања, молимо вас да нас контактирате телефоном или електронском поштом.",
+	"be": "Усе людзі нараджаюцца свабоднымі і роўнымі ў сваёй годнасці і правах. Яны надзелены розумам і сумленнем і павінны ставіцца адзін да аднаго ў духу брацтва. Кожны чалавек мае права на адукацыю. Гэта першы раз, калі мы бачым нешта падобнае, і мы хацелі б ведаць, што вы пра гэта думаеце. Сёння вельмі добрае надвор'е, таму мы ідзём на рынак з нашымі сябрамі і дзецьмі. Калі ласка, дашліце мне справаздачу, калі яна будзе гатовая, і паведаміце, ці магу я яшчэ нешта для вас зрабіць. Наша кампанія працуе на рынку ўжо шмат гадоў. Мы прапануем нашым кліентам якасныя паслугі і новыя рашэнні. Заўтра ў офісе адбудзецца сустрэча, на якой дырэктар раскажа пра новыя праекты і планы на наступны год. Калі ў вас ёсць якія-небудзь пытанні, калі ласка, звяжыцеся з намі па тэлефоне або электроннай пошце.",
+	"kk": "Барлық адамдар тумысынан азат және қадір-қасиеті мен құқықтары тең болып дүниеге келеді. Адамдарға ақыл-парасат пен ар-ождан берілген, сондықтан олар бір-бірімен туысқандық рухта қарым-қатынас жасаулары тиіс. Әркімнің білім алуға құқығы бар. Біз мұндай нәрсені бірінші рет көріп отырмыз және сіз бұл туралы не ойлайтыныңызды білгіміз келеді. Бүгін ауа райы өте жақсы, сондықтан біз достарымызбен және балалармен базарға барамыз.",
+
+	"ar": "يولد جميع الناس أحرارًا متساوين في الكرامة والحقوق. وقد وهبوا عقلاً وضميرًا وعليهم أن يعامل بعضهم بعضًا بروح الإخاء. لكل شخص الحق في التعليم. هذه هي المرة الأولى التي نرى فيها شيئًا كهذا، ونود أن نعرف ما رأيك في ذلك. الطقس جميل جدًا اليوم، لذلك نذهب إلى السوق مع أصدقائنا والأطفال. من فضلك أرسل لي التقرير عندما يكون جاهزًا وأخبرني إذا كان هناك أي شيء آخر يمكنني القيام به من أجلك.",
+	"fa": "تمام افراد بشر آزاد به دنیا می‌آیند و از لحاظ حیثیت و حقوق با هم برابرند. همه دارای عقل و وجدان هستند و باید نسبت به یکدیگر با روح برادری رفتار کنند. هر کس حق دارد که از آموزش و پرورش بهره‌مند شود. این اولین بار است که چنین چیزی را می‌بینیم و دوست داریم بدانیم نظر شما درباره آن چیست. امروز هوا خیلی خوب است، پس با دوستانمان و بچه‌ها به بازار می‌رویم. لطفاً وقتی گزارش آماده شد آن را برای من بفرستید و بگویید آیا کار دیگری هست که بتوانم برای شما انجام دهم.",
+	"ur": "تمام انسان آزاد اور حقوق و عزت کے اعتبار سے برابر پیدا ہوئے ہیں۔ انہیں ضمیر اور عقل ودیعت ہوئی ہے۔ اس لیے انہیں ایک دوسرے کے ساتھ بھائی چارے کا سلوک کرنا چاہیے۔ ہر شخص کو تعلیم کا حق ہے۔ یہ پہلی بار ہے کہ ہم ایسی چیز دیکھ رہے ہیں، اور ہم جاننا چاہیں گے کہ آپ اس کے بارے میں کیا سوچتے ہیں۔ آج موسم بہت اچھا ہے، اس لیے ہم اپنے دوستوں اور بچوں کے ساتھ بازار جا رہے ہیں۔ براہ کرم رپورٹ تیار ہونے پر مجھے بھیج دیں اور بتائیں کہ کیا میں آپ کے لیے کچھ اور کر سکتا ہوں۔",
+
+	"hi": "सभी मनुष्यों को गौरव और अधिकारों के मामले में जन्मजात स्वतंत्रता और समानता प्राप्त है। उन्हें बुद्धि और अंतरात्मा की देन प्राप्त है और परस्पर उन्हें भाईचारे के भाव से बर्ताव करना चाहिए। हर व्यक्ति को शिक्षा का अधिकार है। यह पहली बार है कि हम ऐसी चीज़ देख रहे हैं, और हम जानना चाहेंगे कि आप इसके बारे में क्या सोचते हैं। आज मौसम बहुत अच्छा है, इसलिए हम अपने दोस्तों और बच्चों के साथ बाज़ार जा रहे हैं। कृपया रिपोर्ट तैयार होने पर मुझे भेज दें और बताएं कि क्या मैं आपके लिए कुछ और कर सकता हूं।",
+	"mr": "सर्व मानवी व्यक्ति जन्मतःच स्वतंत्र आहेत व त्यांना समान प्रतिष्ठा व समान अधिकार आहेत. त्यांना विचारशक्ती व सदसद्विवेकबुद्धी लाभलेली आहे व त्यांनी एकमेकांशी बंधुत्वाच्या भावनेने आचरण करावे. प्रत्येक व्यक्तीला शिक्षणाचा हक्क आहे. आम्ही असे काहीतरी पहिल्यांदाच पाहत आहोत, आणि तुम्हाला याबद्दल काय वाटते ते आम्हाला जाणून घ्यायचे आहे. आज हवामान खूप छान आहे, म्हणून आम्ही आमच्या मित्रांसोबत आणि मुलांसोबत बाजारात जात आहोत. कृपया अहवाल तयार झाल्यावर मला पाठवा आणि मी तुमच्यासाठी आणखी काही करू शकतो का ते कळवा.",
+	"ne": "सबै व्यक्तिहरू जन्मजात स्वतन्त्र हुन् ती सबैको समान अधिकार र महत्व छ। निजहरूमा विचार शक्ति र सद्विचार भएकोले निजहरूले आपसमा भ्रातृत्वको भावनाबाट व्यवहार गर्नु पर्छ। प्रत्येक व्यक्तिलाई शिक्षाको अधिकार छ। हामीले यस्तो कुरा पहिलो पटक देखेका छौं, र हामी यसबारे तपाईंलाई के लाग्छ भनेर जान्न चाहन्छौं। आज मौसम धेरै राम्रो छ, त्यसैले हामी हाम्रा साथीहरू र बच्चाहरूसँग बजार जाँदैछौं। कृपया प्रतिवेदन तयार भएपछि मलाई पठाउनुहोस् र म तपाईंको लागि अरू केही गर्न सक्छु कि भनेर जानकारी दिनुहोस्।",
+}
+
+// profile 单个语言的 n-gram 计数
+type profile struct {
+	counts map[string]int
+	// 平滑后的分母：总数 + smoothing * 词表大小
+	denominator float64
+}
+
+var profiles = buildProfiles()
+
+func buildProfiles() map[string]*profile {
+	vocabulary := make(map[string]struct{})
+	result := make(map[string]*profile, len(samples))
+	for lang, text := range samples {
+		counts := ngrams(text)
+		result[lang] = &profile{counts: counts}
+		for gram := range counts {
+			vocabulary[gram] = struct{}{}
+		}
+	}
+	for _, p := range result {
+		total := 0
+		for _, n := range p.counts {
+			total += n
+		}
+		p.denominator = float64(total) + smoothing*float64(len(vocabulary))
+	}
+	return result
+}
+
+// Languages 返回支持识别的语言代码
+func Languages() []string {
+	seen := make(map[string]struct{})
+	var langs []string
+	add := func(lang string) {
+		if _, ok := seen[lang]; !ok {
+			seen[lang] = struct{}{}
+			langs = append(langs, lang)
+		}
+	}
+	add("ja")
+	for _, s := range scriptTables {
+		if lang, ok := uniqueScriptLang[s.script]; ok {
+			add(lang)
+		}
+		for _, lang := range scriptCandidates[s.script] {
+			add(lang)
+		}
+	}
+	return langs
+}
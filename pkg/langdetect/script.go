@@ -0,0 +1,134 @@
+package langdetect
+
+import "unicode"
+
+type script int
+
+const (
+	scriptOther script = iota
+	scriptLatin
+	scriptCyrillic
+	scriptArabic
+	scriptDevanagari
+	scriptHan
+	scriptKana
+	scriptHangul
+	scriptGreek
+	scriptHebrew
+	scriptThai
+	scriptGeorgian
+	scriptArmenian
+	scriptBengali
+	scriptTamil
+	scriptTelugu
+	scriptGujarati
+	scriptGurmukhi
+	scriptKannada
+	scriptMalayalam
+	scriptKhmer
+	scriptLao
+	scriptMyanmar
+	scriptSinhala
+	scriptEthiopic
+)
+
+var scriptTables = []struct {
+	script script
+	table  *unicode.RangeTable
+}{
+	{scriptLatin, unicode.Latin},
+	{scriptCyrillic, unicode.Cyrillic},
+	{scriptArabic, unicode.Arabic},
+	{scriptDevanagari, unicode.Devanagari},
+	{scriptHan, unicode.Han},
+	{scriptKana, unicode.Hiragana},
+	{scriptKana, unicode.Katakana},
+	{scriptHangul, unicode.Hangul},
+	{scriptGreek, unicode.Greek},
+	{scriptHebrew, unicode.Hebrew},
+	{scriptThai, unicode.Thai},
+	{scriptGeorgian, unicode.Georgian},
+	{scriptArmenian, unicode.Armenian},
+	{scriptBengali, unicode.Bengali},
+	{scriptTamil, unicode.Tamil},
+	{scriptTelugu, unicode.Telugu},
+	{scriptGujarati, unicode.Gujarati},
+	{scriptGurmukhi, unicode.Gurmukhi},
+	{scriptKannada, unicode.Kannada},
+	{scriptMalayalam, unicode.Malayalam},
+	{scriptKhmer, unicode.Khmer},
+	{scriptLao, unicode.Lao},
+	{scriptMyanmar, unicode.Myanmar},
+	{scriptSinhala, unicode.Sinhala},
+	{scriptEthiopic, unicode.Ethiopic},
+}
+
+// uniqueScriptLang 文字系统基本只用于一种语言，无需 n-gram 判断
+var uniqueScriptLang = map[script]string{
+	scriptHan:       "zh",
+	scriptHangul:    "ko",
+	scriptGreek:     "el",
+	scriptHebrew:    "he",
+	scriptThai:      "th",
+	scriptGeorgian:  "ka",
+	scriptArmenian:  "hy",
+	scriptBengali:   "bn",
+	scriptTamil:     "ta",
+	scriptTelugu:    "te",
+	scriptGujarati:  "gu",
+	scriptGurmukhi:  "pa",
+	scriptKannada:   "kn",
+	scriptMalayalam: "ml",
+	scriptKhmer:     "km",
+	scriptLao:       "lo",
+	scriptMyanmar:   "my",
+	scriptSinhala:   "si",
+	scriptEthiopic:  "am",
+}
+
+// scriptCandidates 多种语言共用的文字系统，需要用 n-gram 模型区分
+var scriptCandidates = map[script][]string{
+	scriptLatin: {
+		"en", "fr", "de", "es", "it", "pt", "nl", "sv", "da", "nb", "fi", "pl", "cs",
+		"sk", "ro", "hu", "tr", "id", "vi", "hr", "sl", "ca", "lt", "lv", "et",
+	},
+	scriptCyrillic:   {"ru", "uk", "bg", "sr", "be", "kk"},
+	scriptArabic:     {"ar", "fa", "ur"},
+	scriptDevanagari: {"hi", "mr", "ne"},
+}
+
+// countScripts 统计各文字系统的字母数
+func countScripts(text string) (map[script]int, int) {
+	counts := make(map[script]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		counts[scriptOf(r)]++
+	}
+	return counts, letters
+}
+
+func scriptOf(r rune) script {
+	for _, t := range scriptTables {
+		if unicode.Is(t.table, r) {
+			return t.script
+		}
+	}
+	return scriptOther
+}
+
+func dominantScript(counts map[script]int) (script, int) {
+	best, bestCount := scriptOther, 0
+	for s, n := range counts {
+		if s == scriptOther {
+			continue
+		}
+		if n > bestCount || (n == bestCount && s < best) {
+			best, bestCount = s, n
+		}
+	}
+	return best, bestCount
+}
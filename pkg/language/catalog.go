@@ -0,0 +1,62 @@
+package language
+
+import (
+	"strings"
+
+	xlang "golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// catalogCodes 对外展示的常用语言
+var catalogCodes = []string{
+	"en", "en-US", "en-GB", "zh-Hans", "zh-Hant", "ja", "ko", "fr", "de", "es", "es-419",
+	"it", "pt-BR", "pt-PT", "ru", "ar", "hi", "bn", "ur", "fa", "tr", "vi", "th", "id", "ms",
+	"fil", "nl", "sv", "da", "nb", "fi", "pl", "cs", "sk", "ro", "hu", "el", "he", "uk", "bg",
+	"sr", "hr", "sl", "ca", "lt", "lv", "et", "be", "kk", "mr", "ne", "ta", "te", "gu", "pa",
+	"kn", "ml", "km", "lo", "my", "si", "am", "ka", "hy", "sw",
+}
+
+var (
+	catalog   = buildCatalog()
+	nameIndex = buildNameIndex()
+)
+
+func buildCatalog() []Language {
+	list := make([]Language, 0, len(catalogCodes))
+	for _, code := range catalogCodes {
+		list = append(list, *describe(xlang.MustParse(code)))
+	}
+	return list
+}
+
+// buildNameIndex 目录语言的英文名、本地名及其主语言名到语言标签的索引
+func buildNameIndex() map[string]xlang.Tag {
+	index := make(map[string]xlang.Tag)
+	add := func(name string, tag xlang.Tag) {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			return
+		}
+		if _, ok := index[name]; !ok {
+			index[name] = tag
+		}
+	}
+	for _, code := range catalogCodes {
+		tag := xlang.MustParse(code)
+		add(display.English.Tags().Name(tag), tag)
+		add(display.Self.Name(tag), tag)
+	}
+	for _, code := range catalogCodes {
+		base, _ := xlang.MustParse(code).Base()
+		tag := xlang.Make(base.String())
+		add(display.English.Languages().Name(base), tag)
+	}
+	return index
+}
+
+// Catalog 返回常用语言目录
+func Catalog() []Language {
+	list := make([]Language, len(catalog))
+	copy(list, catalog)
+	return list
+}
@@ -0,0 +1,146 @@
+package language
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	xlang "golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+const (
+	DirectionLTR = "ltr"
+	DirectionRTL = "rtl"
+)
+
+var (
+	ErrEmptyCode = errors.New("language: code is empty")
+	ErrUnknown   = errors.New("language: unknown language")
+)
+
+// InvalidCodeError 语言代码不合法或无法识别
+type InvalidCodeError struct {
+	Code string
+	Err  error
+}
+
+func (e *InvalidCodeError) Error() string {
+	return fmt.Sprintf("language: invalid code %q: %v", e.Code, e.Err)
+}
+
+func (e *InvalidCodeError) Unwrap() error {
+	return e.Err
+}
+
+// Language 语言信息，Code 为规范化后的 BCP-47 标签
+type Language struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	NativeName string `json:"native_name"`
+	Script     string `json:"script"`
+	Direction  string `json:"direction"`
+}
+
+// rtlScripts 从右向左书写的文字
+var rtlScripts = map[string]bool{
+	"Arab": true, "Hebr": true, "Thaa": true, "Syrc": true,
+	"Nkoo": true, "Adlm": true, "Mand": true, "Samr": true, "Rohg": true,
+}
+
+// Canonicalize 解析并规范化语言代码，例如 zh_hant -> zh-Hant，pt-br -> pt-BR，iw -> he
+// 无法解析为 BCP-47 时按目录中的英文名或本地名匹配，例如 English -> en
+func Canonicalize(code string) (string, error) {
+	tag, err := parse(code)
+	if err != nil {
+		return "", err
+	}
+	return tag.String(), nil
+}
+
+// Lookup 返回语言代码对应的语言信息
+func Lookup(code string) (*Language, error) {
+	tag, err := parse(code)
+	if err != nil {
+		return nil, err
+	}
+	return describe(tag), nil
+}
+
+// Direction 返回语言的书写方向，无法识别时按从左向右处理
+func Direction(code string) string {
+	lang, err := Lookup(code)
+	if err != nil {
+		return DirectionLTR
+	}
+	return lang.Direction
+}
+
+func parse(code string) (xlang.Tag, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return xlang.Und, ErrEmptyCode
+	}
+	tag, err := xlang.Parse(code)
+	if err != nil {
+		if byName, ok := nameIndex[strings.ToLower(code)]; ok {
+			return byName, nil
+		}
+		return xlang.Und, &InvalidCodeError{Code: code, Err: err}
+	}
+	if tag == xlang.Und {
+		return xlang.Und, &InvalidCodeError{Code: code, Err: ErrUnknown}
+	}
+	return tag, nil
+}
+
+func describe(tag xlang.Tag) *Language {
+	script, _ := tag.Script()
+	direction := DirectionLTR
+	if rtlScripts[script.String()] {
+		direction = DirectionRTL
+	}
+	return &Language{
+		Code:       tag.String(),
+		Name:       display.English.Tags().Name(tag),
+		NativeName: display.Self.Name(tag),
+		Script:     script.String(),
+		Direction:  direction,
+	}
+}
+
+// Same 判断两个语言代码是否为同一种语言
+// 主语言相同且未显式指定不同的文字或地区时视为相同，例如 zh 与 zh-CN 相同，zh-Hans 与 zh-Hant 不同
+func Same(a, b string) bool {
+	ta, errA := parse(a)
+	tb, errB := parse(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+	}
+	baseA, scriptA, regionA := ta.Raw()
+	baseB, scriptB, regionB := tb.Raw()
+	if baseA != baseB {
+		return false
+	}
+	if scriptA != (xlang.Script{}) && scriptB != (xlang.Script{}) && scriptA != scriptB {
+		return false
+	}
+	if regionA != (xlang.Region{}) && regionB != (xlang.Region{}) && regionA != regionB {
+		return false
+	}
+	return true
+}
+
+// Base 返回语言代码的主语言，例如 zh-CN -> zh，无法解析时取第一个子标签
+func Base(code string) string {
+	tag, err := parse(code)
+	if err != nil {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if i := strings.IndexAny(code, "-_"); i >= 0 {
+			code = code[:i]
+		}
+		return code
+	}
+	base, _ := tag.Base()
+	return base.String()
+}
@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+const (
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+// BreakerConfig FailureThreshold 为连续失败多少次后熔断，Cooldown 为熔断后多久放行一次探测请求
+type BreakerConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// CircuitOpenError 服务商处于熔断状态，请求未发出
+type CircuitOpenError struct {
+	Provider string
+	RetryAt  time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("llm: circuit open for provider %s until %s", e.Provider, e.RetryAt.Format(time.RFC3339))
+}
+
+// BreakerState 熔断器当前状态，用于健康检查
+type BreakerState struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// CircuitBreaker 单个服务商的熔断器
+// 连续失败达到阈值后熔断，冷却期内直接拒绝；冷却结束后只放行一个探测请求，成功则恢复，失败则重新熔断
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	provider  string
+	conf      BreakerConfig
+	state     string
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	lastError string
+}
+
+func NewCircuitBreaker(provider string, conf BreakerConfig) *CircuitBreaker {
+	if conf.FailureThreshold <= 0 {
+		conf.FailureThreshold = defaultBreakerFailures
+	}
+	if conf.Cooldown <= 0 {
+		conf.Cooldown = defaultBreakerCooldown
+	}
+	return &CircuitBreaker{
+		provider: provider,
+		conf:     conf,
+		state:    BreakerClosed,
+	}
+}
+
+// Allow 判断是否可以发出请求，放行后调用方必须调用 Success、Failure 或 Release 之一
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		retryAt := b.openedAt.Add(b.conf.Cooldown)
+		if time.Now().Before(retryAt) {
+			return &CircuitOpenError{Provider: b.provider, RetryAt: retryAt}
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return &CircuitOpenError{Provider: b.provider, RetryAt: time.Now().Add(b.conf.Cooldown)}
+		}
+		b.probing = true
+		return nil
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+	b.lastError = ""
+}
+
+func (b *CircuitBreaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if err != nil {
+		b.lastError = err.Error()
+	}
+	if b.state == BreakerHalfOpen || b.failures >= b.conf.FailureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Release 放行的请求没有得出服务商是否可用的结论时调用，例如调用方取消或参数错误
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := BreakerState{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if b.state != BreakerClosed {
+		openedAt, retryAt := b.openedAt, b.openedAt.Add(b.conf.Cooldown)
+		state.OpenedAt, state.RetryAt = &openedAt, &retryAt
+	}
+	return state
+}
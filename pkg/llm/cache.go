@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/cache"
+	"golang.org/x/text/unicode/norm"
+)
+
+const cacheKeyPrefix = "llm:translate:"
+
+// CacheConfig Provider、Model 为被包装客户端的服务商与模型，参与缓存键的计算
+type CacheConfig struct {
+	Provider string
+	Model    string
+	TTL      time.Duration
+}
+
+type cachedClient struct {
+	client ILLMClient
+	cache  cache.ICache
+	conf   CacheConfig
+}
+
+// cacheEntry 缓存的译文，只缓存成功的结果
+type cacheEntry struct {
+	Text  string `json:"text"`
+	Model string `json:"model"`
+}
+
+// NewCachedClient 为单个服务商的客户端增加译文缓存
+// 内容、语言、模板、风格、术语等会影响译文的输入都相同时直接返回缓存的译文，请求的 NoCache 为 true 时跳过缓存
+func NewCachedClient(client ILLMClient, c cache.ICache, conf CacheConfig) ILLMClient {
+	return &cachedClient{
+		client: client,
+		cache:  c,
+		conf:   conf,
+	}
+}
+
+func (c *cachedClient) Translate(ctx context.Context, req *TranslateRequest) (*TranslateResult, error) {
+	if req.NoCache {
+		return c.client.Translate(ctx, req)
+	}
+	key := c.key(req)
+	if result, ok := c.get(ctx, key); ok {
+		return result, nil
+	}
+	result, err := c.client.Translate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	c.set(ctx, key, result)
+	return result, nil
+}
+
+func (c *cachedClient) TranslateStream(
+	ctx context.Context, req *TranslateRequest, handler StreamHandler) (*TranslateResult, error) {
+	if req.NoCache {
+		return translateStream(ctx, c.client, req, handler)
+	}
+	key := c.key(req)
+	if result, ok := c.get(ctx, key); ok {
+		if handler != nil {
+			handler(StreamEvent{Delta: result.Text})
+		}
+		return result, nil
+	}
+	result, err := translateStream(ctx, c.client, req, handler)
+	if err != nil {
+		return nil, err
+	}
+	c.set(ctx, key, result)
+	return result, nil
+}
+
+// Chat 用于评估等场景，不缓存
+func (c *cachedClient) Chat(ctx context.Context, req *ChatRequest) (*TranslateResult, error) {
+	chatClient, ok := c.client.(IChatLLMClient)
+	if !ok {
+		return nil, ErrChatUnsupported
+	}
+	return chatClient.Chat(ctx, req)
+}
+
+func (c *cachedClient) Health() ProviderHealth {
+	return providerHealth(c.conf.Provider, c.client)
+}
+
+// get 缓存不可用时按未命中处理
+func (c *cachedClient) get(ctx context.Context, key string) (*TranslateResult, bool) {
+	value, ok, err := c.cache.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err = json.Unmarshal([]byte(value), &entry); err != nil || entry.Text == "" {
+		return nil, false
+	}
+	return &TranslateResult{
+		Text:     entry.Text,
+		Provider: c.conf.Provider,
+		Model:    entry.Model,
+		Cached:   true,
+	}, true
+}
+
+func (c *cachedClient) set(ctx context.Context, key string, result *TranslateResult) {
+	value, err := json.Marshal(cacheEntry{Text: result.Text, Model: result.Model})
+	if err != nil {
+		return
+	}
+	_ = c.cache.Set(ctx, key, string(value), c.conf.TTL)
+}
+
+func (c *cachedClient) key(req *TranslateRequest) string {
+	tmpl := req.Template
+	if tmpl == nil {
+		tmpl = DefaultPromptTemplate()
+	}
+	payload, _ := json.Marshal(struct {
+		Provider   string         `json:"provider"`
+		Model      string         `json:"model"`
+		Lang       string         `json:"lang"`
+		TargetLang string         `json:"target_lang"`
+		Content    string         `json:"content"`
+		Context    string         `json:"context"`
+		Template   string         `json:"template"`
+		Style      Style          `json:"style"`
+		Glossary   []GlossaryTerm `json:"glossary"`
+		References []Reference    `json:"references"`
+		Examples   []Example      `json:"examples,omitempty"`
+	}{
+		Provider:   c.conf.Provider,
+		Model:      c.conf.Model,
+		Lang:       req.Lang,
+		TargetLang: req.TargetLang,
+		Content:    normalizeContent(req.Content),
+		Context:    normalizeContent(req.Context),
+		Template:   tmpl.Fingerprint(),
+		Style:      req.Style,
+		Glossary:   req.Glossary,
+		References: req.References,
+		Examples:   req.Examples,
+	})
+	sum := sha256.Sum256(payload)
+	return cacheKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// normalizeContent 统一换行符与 Unicode 组合形式并去掉行尾空白，保留换行与缩进，避免不同格式的原文命中同一译文
+func normalizeContent(s string) string {
+	s = norm.NFC.String(strings.ReplaceAll(s, "\r\n", "\n"))
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
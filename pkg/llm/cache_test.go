@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/cache"
+)
+
+func TestCachedClient(t *testing.T) {
+	stub := &stubClient{name: "p1"}
+	client := NewCachedClient(stub, cache.NewLRU(0), CacheConfig{Provider: "p1", Model: "m1"})
+	ctx := context.Background()
+
+	first, err := client.Translate(ctx, testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 换行符与行尾空白不同的相同原文命中缓存
+	same := testRequest()
+	same.Content = "Hello  \r\n"
+	second, err := client.Translate(ctx, same)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached || !second.Cached || second.Text != first.Text || second.Provider != "p1" || stub.count() != 1 {
+		t.Errorf("first = %+v, second = %+v, calls = %d", first, second, stub.count())
+	}
+
+	// 流式调用命中缓存时一次推送完整译文
+	var deltas []string
+	result, err := client.(IStreamLLMClient).TranslateStream(ctx, testRequest(), func(event StreamEvent) {
+		deltas = append(deltas, event.Delta)
+	})
+	if err != nil || !result.Cached || len(deltas) != 1 || deltas[0] != first.Text || stub.count() != 1 {
+		t.Errorf("stream = %+v %v, deltas = %q, calls = %d", result, err, deltas, stub.count())
+	}
+
+	// NoCache 既不读取也不写入缓存
+	bypass := testRequest()
+	bypass.NoCache = true
+	for i := 0; i < 2; i++ {
+		if result, err = client.Translate(ctx, bypass); err != nil || result.Cached {
+			t.Errorf("no cache result = %+v %v", result, err)
+		}
+	}
+	bypass.Content = "Uncached"
+	if _, err = client.Translate(ctx, bypass); err != nil {
+		t.Fatal(err)
+	}
+	bypass.NoCache = false
+	if result, err = client.Translate(ctx, bypass); err != nil || result.Cached {
+		t.Errorf("result translated with NoCache was cached: %+v", result)
+	}
+	if stub.count() != 5 {
+		t.Errorf("calls = %d, want 5", stub.count())
+	}
+}
+
+func TestCachedClientErrorsNotCached(t *testing.T) {
+	stub := &stubClient{name: "p1", errs: []error{&APIError{StatusCode: 500}}}
+	client := NewCachedClient(stub, cache.NewLRU(0), CacheConfig{Provider: "p1"})
+	if _, err := client.Translate(context.Background(), testRequest()); err == nil {
+		t.Fatal("error not returned")
+	}
+	result, err := client.Translate(context.Background(), testRequest())
+	if err != nil || result.Cached || stub.count() != 2 {
+		t.Errorf("result = %+v %v, calls = %d", result, err, stub.count())
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	custom, err := NewPromptTemplate("custom", 1, "Translate into {{.TargetLang}}.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited, err := NewPromptTemplate("custom", 1, "Translate into {{.TargetLang}}, keep it short.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := func() *TranslateRequest {
+		return &TranslateRequest{
+			Lang: "en", TargetLang: "fr", Content: "Open the file", Username: "alice",
+			Style:    Style{Tone: "formal"},
+			Glossary: []GlossaryTerm{{Term: "file", Translation: "fichier"}},
+		}
+	}
+	client := &cachedClient{conf: CacheConfig{Provider: "p1", Model: "m1"}}
+	baseKey := client.key(base())
+	changes := map[string]func(req *TranslateRequest){
+		"content":          func(req *TranslateRequest) { req.Content = "Open the folder" },
+		"source language":  func(req *TranslateRequest) { req.Lang = "de" },
+		"target language":  func(req *TranslateRequest) { req.TargetLang = "es" },
+		"context":          func(req *TranslateRequest) { req.Context = "Previous sentence." },
+		"template":         func(req *TranslateRequest) { req.Template = custom },
+		"tone":             func(req *TranslateRequest) { req.Style.Tone = "casual" },
+		"formality":        func(req *TranslateRequest) { req.Style.Formality = "formal" },
+		"domain":           func(req *TranslateRequest) { req.Style.Domain = "legal" },
+		"glossary":         func(req *TranslateRequest) { req.Glossary[0].Translation = "dossier" },
+		"glossary removed": func(req *TranslateRequest) { req.Glossary = nil },
+		"do not translate": func(req *TranslateRequest) { req.Glossary[0].DoNotTranslate = true },
+		"references": func(req *TranslateRequest) {
+			req.References = []Reference{{Source: "Open", Target: "Ouvrir"}}
+		},
+		"examples": func(req *TranslateRequest) {
+			req.Examples = []Example{{Source: "Close", Target: "Fermer"}}
+		},
+	}
+	for name, change := range changes {
+		req := base()
+		change(req)
+		if client.key(req) == baseKey {
+			t.Errorf("%s does not change the cache key", name)
+		}
+	}
+	customReq := base()
+	customReq.Template = custom
+	editedReq := base()
+	editedReq.Template = edited
+	if client.key(customReq) == client.key(editedReq) {
+		t.Error("template text does not change the cache key")
+	}
+
+	// 服务商与模型不同的缓存互不影响，用户与 NoCache 不影响缓存键
+	other := &cachedClient{conf: CacheConfig{Provider: "p1", Model: "m2"}}
+	if other.key(base()) == baseKey {
+		t.Error("model does not change the cache key")
+	}
+	req := base()
+	req.Username = "bob"
+	req.NoCache = true
+	if client.key(req) != baseKey {
+		t.Error("username changes the cache key")
+	}
+	req = base()
+	req.Template = DefaultPromptTemplate()
+	if client.key(req) != baseKey {
+		t.Error("default template differs from no template")
+	}
+}
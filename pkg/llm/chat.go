@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// IChatLLMClient 支持自定义系统提示词的客户端，用于译文评估等非翻译场景
+type IChatLLMClient interface {
+	ILLMClient
+	Chat(ctx context.Context, req *ChatRequest) (*TranslateResult, error)
+}
+
+// ChatRequest 单轮对话，Lang、TargetLang、Username 仅用于路由选择
+type ChatRequest struct {
+	System     string
+	Content    string
+	Lang       string
+	TargetLang string
+	Username   string
+}
+
+func (c *llmClient) Chat(ctx context.Context, req *ChatRequest) (*TranslateResult, error) {
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, ErrEmptyContent
+	}
+	return c.complete(ctx, []chatMessage{
+		{Role: roleSystem, Content: req.System},
+		{Role: roleUser, Content: req.Content},
+	})
+}
+
+// Chat 与 Translate 使用相同的路由规则和回退顺序，不支持对话的服务商会被跳过
+func (r *router) Chat(ctx context.Context, req *ChatRequest) (*TranslateResult, error) {
+	routeReq := &TranslateRequest{
+		Lang:       req.Lang,
+		TargetLang: req.TargetLang,
+		Content:    req.Content,
+		Username:   req.Username,
+	}
+	return r.dispatch(ctx, routeReq, func(client ILLMClient) (*TranslateResult, bool, error) {
+		chatClient, ok := client.(IChatLLMClient)
+		if !ok {
+			return nil, false, nil
+		}
+		result, err := chatClient.Chat(ctx, req)
+		return result, true, err
+	})
+}
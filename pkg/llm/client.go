@@ -1,15 +1,293 @@
 package llm
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type ILLMClient interface {
-	Translate(lang, content string, targetLang string) (string, error)
+	Translate(ctx context.Context, req *TranslateRequest) (*TranslateResult, error)
+}
+
+// TranslateRequest 一次翻译调用的输入，Username 仅用于路由选择
+type TranslateRequest struct {
+	Lang       string
+	TargetLang string
+	Content    string
+	Username   string
+	// Context 紧邻 Content 之前的原文，仅供模型参考，不会被翻译
+	Context string
+	// Glossary 必须遵循的术语
+	Glossary []GlossaryTerm
+	// References 翻译记忆中相似原文的译文，供模型参考用词
+	References []Reference
+	// Examples 同一系列中已翻译的文档，按时间从早到晚排列，作为示例对话放在原文之前，保持术语与风格一致
+	Examples []Example
+	// Template 系统提示词模板，为空时使用内置模板；Style 为译文风格要求
+	Template *PromptTemplate
+	Style    Style
+	// NoCache 跳过译文缓存，强制调用模型
+	NoCache bool
+}
+
+type Reference struct {
+	Source     string
+	Target     string
+	Similarity float64
+}
+
+// Example 一组原文与译文
+type Example struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+type GlossaryTerm struct {
+	Term           string
+	Translation    string
+	DoNotTranslate bool
+}
+
+// TranslateResult 翻译结果以及实际产出结果的服务商
+type TranslateResult struct {
+	Text     string
+	Provider string
+	Model    string
+	// Usage 本次调用的 token 用量，Latency 为产出结果的那次调用的耗时，不含回退前失败的尝试
+	Usage   Usage
+	Latency time.Duration
+	// Cached 译文来自缓存，未调用模型
+	Cached bool
+	// Attempts 按调用顺序记录经过的服务商，只有经过路由时才会填充
+	Attempts []Attempt
+}
+
+// Usage 服务商返回的 token 用量，服务商未返回时为零值
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Reported 服务商是否返回了用量
+func (u Usage) Reported() bool {
+	return u.TotalTokens > 0
+}
+
+type Attempt struct {
+	Provider string `json:"provider"`
+	Error    string `json:"error,omitempty"`
+	CostMs   int64  `json:"cost_ms"`
+}
+
+const (
+	defaultTimeout     = 60 * time.Second
+	chatCompletionPath = "/chat/completions"
+	// 错误响应体最多保留的字节数，避免日志被超大响应撑爆
+	maxErrorBodySize = 4 << 10
+)
+
+// ClientConfig OpenAI 兼容接口的连接配置
+// BaseURL 需包含版本前缀，例如 https://api.openai.com/v1 或 http://127.0.0.1:11434/v1
+type ClientConfig struct {
+	Name    string
+	BaseURL string
+	Model   string
+	ApiKey  string
+	Timeout time.Duration
+}
+
+type Option func(*llmClient)
+
+// WithHTTPClient 使用自定义的 http.Client，便于测试时指向 httptest 服务
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *llmClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport 替换 http.Client 的 Transport，保留超时设置，例如用于录制与回放
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *llmClient) {
+		c.httpClient.Transport = transport
+	}
+}
+
+type llmClient struct {
+	name       string
+	baseURL    string
+	model      string
+	apiKey     string
+	httpClient *http.Client
 }
 
-type llmClient struct{}
+func NewLLMClient(conf ClientConfig, opts ...Option) ILLMClient {
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	c := &llmClient{
+		name:       conf.Name,
+		baseURL:    strings.TrimRight(conf.BaseURL, "/"),
+		model:      conf.Model,
+		apiKey:     conf.ApiKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+	for _, f := range opts {
+		if f != nil {
+			f(c)
+		}
+	}
+	return c
+}
+
+func (c *llmClient) Translate(ctx context.Context, req *TranslateRequest) (*TranslateResult, error) {
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, ErrEmptyContent
+	}
+	messages, err := buildTranslateMessages(req)
+	if err != nil {
+		return nil, err
+	}
+	return c.complete(ctx, messages)
+}
+
+// complete 发送非流式请求并转换为 TranslateResult
+func (c *llmClient) complete(ctx context.Context, messages []chatMessage) (*TranslateResult, error) {
+	start := time.Now()
+	resp, err := c.chatCompletion(ctx, &chatCompletionRequest{
+		Model:    c.model,
+		Messages: messages,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, ErrNoChoices
+	}
+	model := resp.Model
+	if model == "" {
+		model = c.model
+	}
+	return &TranslateResult{
+		Text:     strings.TrimSpace(resp.Choices[0].Message.Content),
+		Provider: c.name,
+		Model:    model,
+		Usage:    resp.Usage.toUsage(),
+		Latency:  time.Since(start),
+	}, nil
+}
+
+func (c *llmClient) chatCompletion(
+	ctx context.Context, reqBody *chatCompletionRequest) (*chatCompletionResponse, error) {
+	httpResp, err := c.post(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, &RequestError{Err: err}
+	}
+	var resp chatCompletionResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, &DecodeError{Body: truncate(body), Err: err}
+	}
+	if resp.Error != nil {
+		// 部分兼容实现会以 200 状态码返回错误对象
+		return nil, resp.Error.toAPIError(httpResp.StatusCode)
+	}
+	return &resp, nil
+}
+
+// post 发送请求，非 2xx 响应会被转换为 APIError，调用方负责关闭响应体
+func (c *llmClient) post(ctx context.Context, reqBody *chatCompletionRequest) (*http.Response, error) {
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("llm: marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, c.baseURL+chatCompletionPath, bytes.NewReader(payload))
+	if err != nil {
+		return nil, &RequestError{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if reqBody.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &RequestError{Err: err}
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		defer httpResp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(httpResp.Body, maxErrorBodySize))
+		apiErr := newAPIError(httpResp.StatusCode, body)
+		apiErr.RetryAfter = parseRetryAfter(httpResp.Header.Get("Retry-After"), time.Now())
+		return nil, apiErr
+	}
+	return httpResp, nil
+}
+
+func newAPIError(statusCode int, body []byte) *APIError {
+	var wrapper struct {
+		Error *errorBody `json:"error"`
+	}
+	if err := json.Unmarshal(body, &wrapper); err == nil && wrapper.Error != nil {
+		return wrapper.Error.toAPIError(statusCode)
+	}
+	message := strings.TrimSpace(truncate(body))
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	return &APIError{StatusCode: statusCode, Message: message}
+}
+
+// parseRetryAfter 解析 Retry-After 响应头，支持秒数与 HTTP 日期两种格式，无法解析时返回 0
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
 
-func NewLLMClient() ILLMClient {
-	return &llmClient{}
+func truncate(body []byte) string {
+	if len(body) > maxErrorBodySize {
+		return string(body[:maxErrorBodySize])
+	}
+	return string(body)
 }
 
-func (c *llmClient) Translate(lang, content string, targetLang string) (string, error) {
-	return "", nil
+// IsAPIError 判断错误是否为服务端返回的错误，并返回对应的 APIError
+func IsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
 }
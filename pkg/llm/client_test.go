@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) ILLMClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewLLMClient(ClientConfig{
+		Name:    "test",
+		BaseURL: server.URL + "/v1",
+		Model:   "test-model",
+		ApiKey:  "secret",
+	}, WithHTTPClient(server.Client()))
+}
+
+func testRequest() *TranslateRequest {
+	return &TranslateRequest{Lang: "en", TargetLang: "zh-CN", Content: "Hello"}
+}
+
+func TestTranslate(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1"+chatCompletionPath {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("authorization = %q", got)
+		}
+		var req chatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.Model != "test-model" || req.Stream || len(req.Messages) == 0 {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		fmt.Fprint(w, `{"model":"test-model-0613","choices":[{"message":{"role":"assistant","content":" 你好 "}}],
+			"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`)
+	})
+	result, err := client.Translate(context.Background(), testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "你好" || result.Provider != "test" || result.Model != "test-model-0613" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.Usage.TotalTokens != 15 || !result.Usage.Reported() {
+		t.Errorf("usage = %+v", result.Usage)
+	}
+}
+
+func TestTranslateStream(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept"); got != "text/event-stream" {
+			t.Errorf("accept = %q", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"model":"m1","choices":[{"delta":{"role":"assistant"}}]}`,
+			`{"choices":[{"delta":{"content":"你"}}]}`,
+			`{"choices":[{"delta":{"content":"好"}}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+	var deltas []string
+	result, err := client.(IStreamLLMClient).TranslateStream(context.Background(), testRequest(),
+		func(event StreamEvent) {
+			deltas = append(deltas, event.Delta)
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(deltas, "|") != "你|好" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if result.Text != "你好" || result.Model != "m1" || result.Usage.TotalTokens != 7 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestTranslateEmptyContent(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("empty content sent upstream")
+	})
+	if _, err := client.Translate(context.Background(),
+		&TranslateRequest{Lang: "en", TargetLang: "zh-CN", Content: "  "}); !errors.Is(err, ErrEmptyContent) {
+		t.Fatalf("err = %v, want ErrEmptyContent", err)
+	}
+}
+
+func TestTranslateAPIError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"message":"slow down","type":"rate_limit_error","code":429}}`)
+	})
+	_, err := client.Translate(context.Background(), testRequest())
+	apiErr, ok := IsAPIError(err)
+	if !ok {
+		t.Fatalf("err = %v, want APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Message != "slow down" ||
+		apiErr.Type != "rate_limit_error" || apiErr.Code != "429" {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+	if !apiErr.IsRateLimited() || apiErr.RetryAfter != 3*time.Second {
+		t.Errorf("rate limited = %v, retry after = %v", apiErr.IsRateLimited(), apiErr.RetryAfter)
+	}
+}
+
+func TestTranslateAPIErrorPlainBody(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+	})
+	_, err := client.Translate(context.Background(), testRequest())
+	apiErr, ok := IsAPIError(err)
+	if !ok || !apiErr.IsServerError() || apiErr.Message != "upstream unavailable" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestTranslateErrorBodyWithOK(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error":{"message":"invalid api key","type":"auth_error","code":null}}`)
+	})
+	_, err := client.Translate(context.Background(), testRequest())
+	apiErr, ok := IsAPIError(err)
+	if !ok {
+		t.Fatalf("err = %v, want APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusOK || apiErr.Message != "invalid api key" || apiErr.Code != "" {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+}
+
+func TestTranslateDecodeError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[`)
+	})
+	_, err := client.Translate(context.Background(), testRequest())
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("err = %v, want DecodeError", err)
+	}
+	if decodeErr.Body != `{"choices":[` {
+		t.Errorf("body = %q", decodeErr.Body)
+	}
+}
+
+func TestTranslateNoChoices(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[]}`)
+	})
+	if _, err := client.Translate(context.Background(), testRequest()); !errors.Is(err, ErrNoChoices) {
+		t.Fatalf("err = %v, want ErrNoChoices", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{" 5 ", 5 * time.Second},
+		{"-1", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+	for _, c := range cases {
+		if got := parseRetryAfter(c.value, now); got != c.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", c.value, got, c.want)
+		}
+	}
+}
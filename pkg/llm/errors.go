@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrEmptyContent = errors.New("llm: content is empty")
+	ErrNoChoices    = errors.New("llm: response contains no choices")
+	ErrNoProvider   = errors.New("llm: no provider available")
+	// ErrChatUnsupported 客户端未实现 IChatLLMClient
+	ErrChatUnsupported = errors.New("llm: client does not support chat")
+)
+
+// APIError 服务端返回的非 2xx 响应
+type APIError struct {
+	StatusCode int
+	Type       string
+	Code       string
+	Message    string
+	// RetryAfter 服务端通过 Retry-After 要求的等待时间，未返回时为 0
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("llm: api error, status: %d, code: %s, message: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("llm: api error, status: %d, message: %s", e.StatusCode, e.Message)
+}
+
+// IsRateLimited 是否被服务端限流
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsServerError 是否为服务端内部错误
+func (e *APIError) IsServerError() bool {
+	return e.StatusCode >= http.StatusInternalServerError
+}
+
+// IsAuthError 是否为鉴权失败
+func (e *APIError) IsAuthError() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// RequestError 请求未能得到响应，例如连接失败或超时
+type RequestError struct {
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("llm: request failed: %s", e.Err.Error())
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError 响应体不是合法的 chat completions JSON
+type DecodeError struct {
+	Body string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("llm: decode response failed: %s", e.Err.Error())
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// AllProvidersFailedError 路由中的所有服务商均调用失败
+type AllProvidersFailedError struct {
+	Attempts []Attempt
+	Err      error
+}
+
+func (e *AllProvidersFailedError) Error() string {
+	return fmt.Sprintf("llm: all %d providers failed, last error: %s", len(e.Attempts), e.Err.Error())
+}
+
+func (e *AllProvidersFailedError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+)
+
+const (
+	fakeModel = "fake"
+	// fakeJudgeResponse Chat 目前只用于质量评分，固定返回满分
+	fakeJudgeResponse = `{"score": 100, "reason": "fake provider"}`
+)
+
+// fakeTagPattern 行首已有的语言标记，再次翻译时替换而不是叠加，回译后可得到原文
+var fakeTagPattern = regexp.MustCompile(`^\[[A-Za-z0-9-]+\] `)
+
+type fakeClient struct {
+	name string
+}
+
+// NewFakeClient 创建不依赖网络的客户端，译文为在每个非空行前加上 "[目标语言] " 标记，
+// 相同的输入总是得到相同的输出，token 用量按原文估算，便于开发环境与离线测试跑通完整流程
+func NewFakeClient(name string) ILLMClient {
+	return &fakeClient{name: name}
+}
+
+func (f *fakeClient) Translate(ctx context.Context, req *TranslateRequest) (*TranslateResult, error) {
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, ErrEmptyContent
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.result(req.Content, fakeTranslate(req.Content, req.TargetLang)), nil
+}
+
+func (f *fakeClient) TranslateStream(
+	ctx context.Context, req *TranslateRequest, handler StreamHandler) (*TranslateResult, error) {
+	result, err := f.Translate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if handler != nil {
+		for _, line := range strings.SplitAfter(result.Text, "\n") {
+			handler(StreamEvent{Delta: line})
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeClient) Chat(ctx context.Context, req *ChatRequest) (*TranslateResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.result(req.System+req.Content, fakeJudgeResponse), nil
+}
+
+func (f *fakeClient) result(input, output string) *TranslateResult {
+	prompt, completion := segmenter.EstimateTokens(input), segmenter.EstimateTokens(output)
+	return &TranslateResult{
+		Text:     output,
+		Provider: f.name,
+		Model:    fakeModel,
+		Usage: Usage{
+			PromptTokens:     prompt,
+			CompletionTokens: completion,
+			TotalTokens:      prompt + completion,
+		},
+		Latency: time.Millisecond,
+	}
+}
+
+// fakeTranslate 保留行首空白与空行，使分段拼接与格式处理的结果与真实模型一致
+func fakeTranslate(content, targetLang string) string {
+	tag := fmt.Sprintf("[%s] ", targetLang)
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	for i, line := range lines {
+		text := strings.TrimLeft(line, " \t")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(text)]
+		lines[i] = indent + tag + fakeTagPattern.ReplaceAllString(text, "")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// 录制/回放模式
+const (
+	FixtureModeRecord = "record"
+	FixtureModeReplay = "replay"
+)
+
+// ErrFixtureNotFound 回放模式下没有与请求对应的录制文件
+var ErrFixtureNotFound = errors.New("llm: fixture not found")
+
+// fixture 一次请求与响应的录制，响应体单独保存在同名的 .body 文件中，回放时逐字节返回
+type fixture struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Request    string      `json:"request"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+}
+
+type fixtureTransport struct {
+	dir  string
+	mode string
+	next http.RoundTripper
+}
+
+// NewFixtureTransport 录制模式下把真实请求与响应写入 dir，回放模式下只读取 dir 中的录制，不发出网络请求
+// 录制以请求方法、URL 与请求体的哈希命名，不包含 Authorization 等请求头，录制文件中不会出现 api key
+func NewFixtureTransport(dir, mode string, next http.RoundTripper) (http.RoundTripper, error) {
+	if mode != FixtureModeRecord && mode != FixtureModeReplay {
+		return nil, fmt.Errorf("llm: unknown fixture mode %q", mode)
+	}
+	if mode == FixtureModeRecord {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("llm: create fixture dir: %w", err)
+		}
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &fixtureTransport{dir: dir, mode: mode, next: next}, nil
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	key := fixtureKey(req, reqBody)
+	if t.mode == FixtureModeReplay {
+		return t.replay(req, key)
+	}
+	return t.record(req, key, reqBody)
+}
+
+func (t *fixtureTransport) replay(req *http.Request, key string) (*http.Response, error) {
+	meta, err := os.ReadFile(filepath.Join(t.dir, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s (%s)", ErrFixtureNotFound, req.Method, req.URL.String(), key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err = json.Unmarshal(meta, &f); err != nil {
+		return nil, fmt.Errorf("llm: decode fixture %s: %w", key, err)
+	}
+	body, err := os.ReadFile(filepath.Join(t.dir, key+".body"))
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.StatusCode, http.StatusText(f.StatusCode)),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *fixtureTransport) record(req *http.Request, key string, reqBody []byte) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	meta, err := json.MarshalIndent(&fixture{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Request:    string(reqBody),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// 先写响应体再写元数据，回放时以元数据是否存在判断录制是否完整
+	if err = os.WriteFile(filepath.Join(t.dir, key+".body"), body, 0644); err != nil {
+		return nil, fmt.Errorf("llm: write fixture: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(t.dir, key+".json"), meta, 0644); err != nil {
+		return nil, fmt.Errorf("llm: write fixture: %w", err)
+	}
+	return resp, nil
+}
+
+func fixtureKey(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL.String()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newFixtureClient(t *testing.T, baseURL, dir, mode string) ILLMClient {
+	t.Helper()
+	transport, err := NewFixtureTransport(dir, mode, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewLLMClient(ClientConfig{
+		Name:    "fixture",
+		BaseURL: baseURL,
+		Model:   "test-model",
+		ApiKey:  "secret",
+	}, WithTransport(transport))
+}
+
+func TestFixtureRecordReplay(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"你\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"好\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	dir := t.TempDir()
+	baseURL := server.URL + "/v1"
+
+	record := newFixtureClient(t, baseURL, dir, FixtureModeRecord).(IStreamLLMClient)
+	recorded, err := record.TranslateStream(context.Background(), testRequest(), func(StreamEvent) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	if hits != 1 || recorded.Text != "你好" {
+		t.Fatalf("hits = %d, text = %q", hits, recorded.Text)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "secret") {
+			t.Errorf("fixture %s contains the api key", file.Name())
+		}
+	}
+
+	// 回放时服务已关闭，结果只能来自录制
+	replay := newFixtureClient(t, baseURL, dir, FixtureModeReplay).(IStreamLLMClient)
+	var deltas []string
+	replayed, err := replay.TranslateStream(context.Background(), testRequest(), func(event StreamEvent) {
+		deltas = append(deltas, event.Delta)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Text != recorded.Text || strings.Join(deltas, "|") != "你|好" {
+		t.Errorf("text = %q, deltas = %q", replayed.Text, deltas)
+	}
+
+	other := testRequest()
+	other.Content = "Goodbye"
+	if _, err = replay.Translate(context.Background(), other); !errors.Is(err, ErrFixtureNotFound) {
+		t.Errorf("err = %v, want ErrFixtureNotFound", err)
+	}
+}
+
+func TestFixtureUnknownMode(t *testing.T) {
+	if _, err := NewFixtureTransport(t.TempDir(), "live", nil); err == nil {
+		t.Error("unknown mode accepted")
+	}
+}
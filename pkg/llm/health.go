@@ -0,0 +1,42 @@
+package llm
+
+// ProviderHealth 服务商的健康状态，Breaker、Limiter 为 nil 表示未启用熔断或限流
+type ProviderHealth struct {
+	Provider string        `json:"provider"`
+	Breaker  *BreakerState `json:"breaker,omitempty"`
+	Limiter  *LimiterStats `json:"limiter,omitempty"`
+}
+
+// IHealthLLMClient 可以报告健康状态的客户端
+type IHealthLLMClient interface {
+	Health() ProviderHealth
+}
+
+// IHealthReporter 汇总多个服务商健康状态的客户端，例如路由
+type IHealthReporter interface {
+	ProvidersHealth() []ProviderHealth
+}
+
+// providerHealth 被包装的客户端能报告健康状态时使用其结果
+func providerHealth(provider string, client ILLMClient) ProviderHealth {
+	if healthClient, ok := client.(IHealthLLMClient); ok {
+		health := healthClient.Health()
+		health.Provider = provider
+		return health
+	}
+	return ProviderHealth{Provider: provider}
+}
+
+// ProvidersHealth 按注册顺序返回所有服务商的健康状态
+func (r *router) ProvidersHealth() []ProviderHealth {
+	names := r.registry.Names()
+	list := make([]ProviderHealth, 0, len(names))
+	for _, name := range names {
+		client, ok := r.registry.Get(name)
+		if !ok {
+			continue
+		}
+		list = append(list, providerHealth(name, client))
+	}
+	return list
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const judgeSystemPrompt = "You are a meticulous translation quality reviewer. " +
+	"Compare the translation with the source text and rate its overall quality on a scale from 0 to 100, " +
+	"considering accuracy, completeness, fluency, terminology and preservation of formatting. " +
+	"100 means a flawless professional translation, 50 means understandable but with clear errors, " +
+	"0 means wrong or untranslated. " +
+	"Respond with a single JSON object only, in the form {\"score\": <integer 0-100>, \"reason\": \"<one short sentence>\"}."
+
+// JudgeRequest 由模型评估译文质量
+type JudgeRequest struct {
+	Lang        string
+	TargetLang  string
+	Source      string
+	Translation string
+	Username    string
+}
+
+// JudgeResult Score 取值 [0, 1]，Result 为评分调用本身的结果，用于统计用量
+type JudgeResult struct {
+	Score  float64
+	Reason string
+	Result *TranslateResult
+}
+
+// Judge 让模型对译文打分，客户端需要实现 IChatLLMClient
+func Judge(ctx context.Context, client ILLMClient, req *JudgeRequest) (*JudgeResult, error) {
+	chatClient, ok := client.(IChatLLMClient)
+	if !ok {
+		return nil, ErrChatUnsupported
+	}
+	source := req.Lang
+	if source == "" || source == autoDetect {
+		source = "unknown"
+	}
+	content := fmt.Sprintf("<source lang=%q>\n%s\n</source>\n<translation lang=%q>\n%s\n</translation>",
+		source, req.Source, req.TargetLang, req.Translation)
+	result, err := chatClient.Chat(ctx, &ChatRequest{
+		System:     judgeSystemPrompt,
+		Content:    content,
+		Lang:       req.Lang,
+		TargetLang: req.TargetLang,
+		Username:   req.Username,
+	})
+	if err != nil {
+		return nil, err
+	}
+	judge, err := parseJudgeResult(result.Text)
+	if err != nil {
+		return nil, err
+	}
+	judge.Result = result
+	return judge, nil
+}
+
+// parseJudgeResult 模型可能在 JSON 前后附带说明或代码块标记，只取第一个对象
+func parseJudgeResult(text string) (*JudgeResult, error) {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, &DecodeError{Body: truncate([]byte(text)), Err: fmt.Errorf("no json object in judge reply")}
+	}
+	var reply struct {
+		Score  float64 `json:"score"`
+		Reason string  `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &reply); err != nil {
+		return nil, &DecodeError{Body: truncate([]byte(text)), Err: err}
+	}
+	score := reply.Score / 100
+	if score < 0 {
+		score = 0
+	}
+	if score > 1 {
+		score = 1
+	}
+	return &JudgeResult{Score: score, Reason: reply.Reason}, nil
+}
@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jovian1994/cxh-1207-be-interview/pkg/segmenter"
+	"golang.org/x/time/rate"
+)
+
+// limitBurstWindow 令牌桶容量为该时长内的额度，避免空闲后一次性打满服务商的分钟限额
+const limitBurstWindow = 10 * time.Second
+
+// callTimeoutKey 外层 resilientClient 的单次调用超时，限流器放行后才开始计时
+type callTimeoutKey struct{}
+
+// upstreamContext 为放行后的上游调用设置超时，ctx 中没有超时时原样返回
+func upstreamContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout, ok := ctx.Value(callTimeoutKey{}).(time.Duration); ok && timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func() {}
+}
+
+// LimitConfig 单个服务商的出站限制，为 0 的字段不限制
+type LimitConfig struct {
+	Provider string
+	// RPM、TPM 每分钟的请求数与 token 数，token 按原文估算，包含提示词与译文
+	RPM int
+	TPM int
+	// MaxInFlight 同时进行中的请求数
+	MaxInFlight int
+}
+
+// LimiterStats 限流器的排队情况，等待时间包含排队与等待令牌的时间
+type LimiterStats struct {
+	QueueDepth  int     `json:"queue_depth"`
+	InFlight    int     `json:"in_flight"`
+	Admitted    int64   `json:"admitted"`
+	AvgWaitMs   float64 `json:"avg_wait_ms"`
+	MaxWaitMs   int64   `json:"max_wait_ms"`
+	LastWaitMs  int64   `json:"last_wait_ms"`
+	RPM         int     `json:"rpm,omitempty"`
+	TPM         int     `json:"tpm,omitempty"`
+	MaxInFlight int     `json:"max_in_flight,omitempty"`
+}
+
+type limitedClient struct {
+	client   ILLMClient
+	conf     LimitConfig
+	requests *rate.Limiter
+	tokens   *rate.Limiter
+	slots    chan struct{}
+
+	mu        sync.Mutex
+	queued    int
+	inFlight  int
+	admitted  int64
+	totalWait time.Duration
+	maxWait   time.Duration
+	lastWait  time.Duration
+}
+
+// NewLimitedClient 按服务商的 RPM、TPM 与并发数限制出站请求，超出时在本地排队等待而不是触发服务商的 429
+func NewLimitedClient(client ILLMClient, conf LimitConfig) ILLMClient {
+	l := &limitedClient{
+		client:   client,
+		conf:     conf,
+		requests: perMinuteLimiter(conf.RPM),
+		tokens:   perMinuteLimiter(conf.TPM),
+	}
+	if conf.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, conf.MaxInFlight)
+	}
+	return l
+}
+
+func perMinuteLimiter(limit int) *rate.Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	burst := max(int(int64(limit)*int64(limitBurstWindow)/int64(time.Minute)), 1)
+	return rate.NewLimiter(rate.Limit(float64(limit)/time.Minute.Seconds()), burst)
+}
+
+func (l *limitedClient) Translate(ctx context.Context, req *TranslateRequest) (*TranslateResult, error) {
+	release, err := l.acquire(ctx, estimateCallTokens(req.Context, req.Content))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	ctx, cancel := upstreamContext(ctx)
+	defer cancel()
+	return l.client.Translate(ctx, req)
+}
+
+func (l *limitedClient) TranslateStream(
+	ctx context.Context, req *TranslateRequest, handler StreamHandler) (*TranslateResult, error) {
+	release, err := l.acquire(ctx, estimateCallTokens(req.Context, req.Content))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	ctx, cancel := upstreamContext(ctx)
+	defer cancel()
+	return translateStream(ctx, l.client, req, handler)
+}
+
+func (l *limitedClient) Chat(ctx context.Context, req *ChatRequest) (*TranslateResult, error) {
+	chatClient, ok := l.client.(IChatLLMClient)
+	if !ok {
+		return nil, ErrChatUnsupported
+	}
+	release, err := l.acquire(ctx, estimateCallTokens(req.System, req.Content))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	ctx, cancel := upstreamContext(ctx)
+	defer cancel()
+	return chatClient.Chat(ctx, req)
+}
+
+func (l *limitedClient) Health() ProviderHealth {
+	health := providerHealth(l.conf.Provider, l.client)
+	stats := l.stats()
+	health.Limiter = &stats
+	return health
+}
+
+func (l *limitedClient) stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := LimiterStats{
+		QueueDepth:  l.queued,
+		InFlight:    l.inFlight,
+		Admitted:    l.admitted,
+		MaxWaitMs:   l.maxWait.Milliseconds(),
+		LastWaitMs:  l.lastWait.Milliseconds(),
+		RPM:         l.conf.RPM,
+		TPM:         l.conf.TPM,
+		MaxInFlight: l.conf.MaxInFlight,
+	}
+	if l.admitted > 0 {
+		stats.AvgWaitMs = float64(l.totalWait.Milliseconds()) / float64(l.admitted)
+	}
+	return stats
+}
+
+// acquire 依次等待请求数、token 数与并发名额，调用方取消时放弃排队
+// 单次请求估算的 token 超过桶容量时按桶容量计算，否则永远等不到
+func (l *limitedClient) acquire(ctx context.Context, tokens int) (func(), error) {
+	start := time.Now()
+	l.mu.Lock()
+	l.queued++
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.queued--
+		l.mu.Unlock()
+	}()
+
+	if l.requests != nil {
+		if err := l.requests.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if l.tokens != nil && tokens > 0 {
+		if err := l.tokens.WaitN(ctx, min(tokens, l.tokens.Burst())); err != nil {
+			return nil, err
+		}
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	wait := time.Since(start)
+	l.mu.Lock()
+	l.inFlight++
+	l.admitted++
+	l.totalWait += wait
+	l.lastWait = wait
+	l.maxWait = max(l.maxWait, wait)
+	l.mu.Unlock()
+	return func() {
+		l.mu.Lock()
+		l.inFlight--
+		l.mu.Unlock()
+		if l.slots != nil {
+			<-l.slots
+		}
+	}, nil
+}
+
+// estimateCallTokens 译文长度按与原文相当估算
+func estimateCallTokens(prompt, content string) int {
+	return segmenter.EstimateTokens(prompt) + 2*segmenter.EstimateTokens(content)
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiterRPM(t *testing.T) {
+	// 每分钟 60 次，令牌桶容量为 10 秒的额度，即 10 次，之后每秒放行一次
+	stub := &stubClient{name: "p1"}
+	client := NewLimitedClient(stub, LimitConfig{Provider: "p1", RPM: 60})
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if _, err := client.Translate(ctx, testRequest()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("burst waited %s", elapsed)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err := client.Translate(ctx, testRequest()); err == nil {
+		t.Error("request beyond the burst was not queued")
+	}
+	if stub.count() != 10 {
+		t.Errorf("calls = %d", stub.count())
+	}
+}
+
+func TestLimiterTPM(t *testing.T) {
+	// 每分钟 600 token，桶容量 100，超过容量的请求按容量计算，不会永远等待
+	stub := &stubClient{name: "p1"}
+	client := NewLimitedClient(stub, LimitConfig{Provider: "p1", TPM: 600})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	large := &TranslateRequest{Lang: "en", TargetLang: "fr", Content: strings.Repeat("word ", 500)}
+	if _, err := client.Translate(ctx, large); err != nil {
+		t.Fatalf("oversized request: %v", err)
+	}
+	// 桶已用完，下一个请求需要等待约 10 秒的额度
+	if _, err := client.Translate(ctx, testRequest()); err == nil {
+		t.Error("request after the bucket was drained was not queued")
+	}
+}
+
+func TestLimiterInFlight(t *testing.T) {
+	stub := &stubClient{name: "p1", delay: 20 * time.Millisecond}
+	client := NewLimitedClient(stub, LimitConfig{Provider: "p1", MaxInFlight: 2})
+	var wg sync.WaitGroup
+	done, stopped := make(chan struct{}), make(chan int)
+	go func() {
+		maxSeen := 0
+		for {
+			select {
+			case <-done:
+				stopped <- maxSeen
+				return
+			default:
+			}
+			maxSeen = max(maxSeen, client.(IHealthLLMClient).Health().Limiter.InFlight)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Translate(context.Background(), testRequest()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	maxSeen := <-stopped
+	// 6 个请求每次最多 2 个，至少需要 3 轮
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("6 requests finished in %s with 2 slots", elapsed)
+	}
+	stats := client.(IHealthLLMClient).Health().Limiter
+	if maxSeen > 2 || stats.Admitted != 6 || stats.InFlight != 0 || stats.QueueDepth != 0 ||
+		stats.MaxWaitMs < 30 || stats.MaxInFlight != 2 {
+		t.Errorf("max in flight = %d, stats = %+v", maxSeen, stats)
+	}
+}
+
+func TestLimiterCancelWhileQueued(t *testing.T) {
+	stub := &stubClient{name: "p1", delay: 200 * time.Millisecond}
+	client := NewLimitedClient(stub, LimitConfig{Provider: "p1", MaxInFlight: 1})
+	go client.Translate(context.Background(), testRequest())
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Translate(ctx, testRequest()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("queued request err = %v", err)
+	}
+	if stub.count() != 1 {
+		t.Errorf("calls = %d, cancelled request reached the provider", stub.count())
+	}
+}
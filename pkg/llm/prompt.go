@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// autoDetect 与 models.AutoDetect 保持一致，pkg 层不依赖 models
+const autoDetect = "auto-detect"
+
+// placeholderPattern 占位符保护替换出的 ⟦n⟧ 标记与文档格式的 <x id="n"/> 行内占位元素
+var placeholderPattern = regexp.MustCompile(`⟦\s*\d+\s*⟧|<x id="\d+"/>`)
+
+// placeholderInstruction 原文含有占位标记时固定附加在系统提示词末尾，不受模板内容影响
+const placeholderInstruction = "\n\nThe text contains placeholders such as ⟦1⟧ and <x id=\"1\"/> that stand for content " +
+	"which must not be translated. Copy every placeholder into the translation exactly as written, " +
+	"keep each one exactly once, do not translate, renumber, merge or drop any of them, " +
+	"and place them where they belong in the translated sentence."
+
+// buildTranslateMessages 使用请求指定的模板渲染系统提示词，未指定时使用内置模板
+// 原文含有占位标记时无论使用哪个模板都会附加保留占位标记的要求
+func buildTranslateMessages(req *TranslateRequest) ([]chatMessage, error) {
+	tmpl := req.Template
+	if tmpl == nil {
+		tmpl = defaultPromptTemplate
+	}
+	system, err := tmpl.Render(&PromptData{
+		SourceLang:   req.Lang,
+		TargetLang:   req.TargetLang,
+		AutoDetect:   req.Lang == "" || req.Lang == autoDetect,
+		Tone:         req.Style.Tone,
+		Formality:    req.Style.Formality,
+		Domain:       req.Style.Domain,
+		Instructions: req.Style.instructions(),
+		Glossary:     glossarySection(req.Glossary),
+		References:   referencesSection(req.References),
+		Context:      contextSection(req.Context),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if placeholderPattern.MatchString(req.Content) {
+		system += placeholderInstruction
+	}
+	messages := make([]chatMessage, 0, 2+2*len(req.Examples))
+	messages = append(messages, chatMessage{Role: roleSystem, Content: system})
+	for _, example := range req.Examples {
+		messages = append(messages,
+			chatMessage{Role: roleUser, Content: example.Source},
+			chatMessage{Role: roleAssistant, Content: example.Target})
+	}
+	return append(messages, chatMessage{Role: roleUser, Content: req.Content}), nil
+}
+
+func glossarySection(terms []GlossaryTerm) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	var translate, keep []GlossaryTerm
+	for _, term := range terms {
+		if term.DoNotTranslate {
+			keep = append(keep, term)
+		} else {
+			translate = append(translate, term)
+		}
+	}
+	var sb strings.Builder
+	if len(translate) > 0 {
+		sb.WriteString("\n\nApply this glossary strictly, translating each source term exactly as given:\n")
+		for _, term := range translate {
+			sb.WriteString(fmt.Sprintf("- %q => %q\n", term.Term, term.Translation))
+		}
+	}
+	if len(keep) > 0 {
+		sb.WriteString("\n\nKeep the following terms exactly as they are, do not translate them:\n")
+		for _, term := range keep {
+			sb.WriteString(fmt.Sprintf("- %q\n", term.Term))
+		}
+	}
+	return sb.String()
+}
+
+func referencesSection(refs []Reference) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nPreviously approved translations of similar text are given below. ")
+	sb.WriteString("Reuse their wording where the meaning is the same, and translate the differences faithfully.\n")
+	for _, ref := range refs {
+		sb.WriteString(fmt.Sprintf("<reference similarity=\"%.0f%%\">\n<source>\n%s\n</source>\n<translation>\n%s\n</translation>\n</reference>\n",
+			ref.Similarity*100, ref.Source, ref.Target))
+	}
+	return sb.String()
+}
+
+func contextSection(context string) string {
+	if context == "" {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nThe user's text continues a longer document. ")
+	sb.WriteString("The passage immediately before it is given below for reference only; ")
+	sb.WriteString("keep terminology and style consistent with it, but do not translate or repeat it.\n")
+	sb.WriteString("<context>\n")
+	sb.WriteString(context)
+	sb.WriteString("\n</context>")
+	return sb.String()
+}
@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlaceholderInstruction(t *testing.T) {
+	custom, err := NewPromptTemplate("terse", 1, "Translate into {{.TargetLang}}.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		content  string
+		template *PromptTemplate
+		want     bool
+	}{
+		{"Hello world", nil, false},
+		{"Run ⟦1⟧ before ⟦2⟧", nil, true},
+		{"Run ⟦1⟧ first", custom, true},
+		{`Click <x id="1"/>here<x id="2"/>`, custom, true},
+		{"Plain text", custom, false},
+	}
+	for _, c := range cases {
+		req := &TranslateRequest{TargetLang: "fr", Content: c.content, Template: c.template}
+		messages, err := buildTranslateMessages(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		system := messages[0].Content
+		if got := strings.HasSuffix(system, placeholderInstruction); got != c.want {
+			t.Errorf("%q: instruction appended = %v, want %v", c.content, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry 按名称管理已配置的翻译服务商
+type Registry struct {
+	mu      sync.RWMutex
+	clients map[string]ILLMClient
+	names   []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		clients: make(map[string]ILLMClient),
+	}
+}
+
+// Register 注册服务商，名称重复时返回错误
+func (r *Registry) Register(name string, client ILLMClient) error {
+	if name == "" {
+		return fmt.Errorf("llm: provider name is empty")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.clients[name]; ok {
+		return fmt.Errorf("llm: provider %s already registered", name)
+	}
+	r.clients[name] = client
+	r.names = append(r.names, name)
+	return nil
+}
+
+func (r *Registry) Get(name string) (ILLMClient, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.clients[name]
+	return client, ok
+}
+
+// Names 按注册顺序返回所有服务商名称
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, len(r.names))
+	copy(names, r.names)
+	return names
+}
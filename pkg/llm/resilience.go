@@ -0,0 +1,199 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultMaxRetries    = 2
+	defaultBaseDelay     = 500 * time.Millisecond
+	defaultMaxDelay      = 10 * time.Second
+	defaultMaxRetryAfter = time.Minute
+)
+
+// ResilienceConfig 单个服务商的超时、重试与熔断策略，为 0 的字段使用默认值
+type ResilienceConfig struct {
+	Provider string
+	// CallTimeout 每次调用上游的超时时间，重试时重新计时，不含在限流器中排队的时间，0 表示只受调用方 context 控制
+	CallTimeout time.Duration
+	// MaxRetries 遇到 429、5xx 或网络错误时的重试次数，小于 0 表示不重试
+	MaxRetries int
+	// BaseDelay、MaxDelay 指数退避的初始与最大间隔，实际间隔带随机抖动
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxRetryAfter 服务端要求等待的时间超过该值时不再重试，直接返回错误以便路由切换服务商
+	MaxRetryAfter time.Duration
+	// Breaker 为 nil 时不熔断
+	Breaker *CircuitBreaker
+}
+
+type resilientClient struct {
+	client ILLMClient
+	conf   ResilienceConfig
+}
+
+// NewResilientClient 为单个服务商的客户端增加超时、退避重试与熔断
+func NewResilientClient(client ILLMClient, conf ResilienceConfig) ILLMClient {
+	if conf.MaxRetries == 0 {
+		conf.MaxRetries = defaultMaxRetries
+	}
+	if conf.BaseDelay <= 0 {
+		conf.BaseDelay = defaultBaseDelay
+	}
+	if conf.MaxDelay <= 0 {
+		conf.MaxDelay = defaultMaxDelay
+	}
+	if conf.MaxRetryAfter <= 0 {
+		conf.MaxRetryAfter = defaultMaxRetryAfter
+	}
+	return &resilientClient{
+		client: client,
+		conf:   conf,
+	}
+}
+
+func (r *resilientClient) Translate(ctx context.Context, req *TranslateRequest) (*TranslateResult, error) {
+	return r.call(ctx, func(ctx context.Context) (*TranslateResult, error) {
+		return r.client.Translate(ctx, req)
+	}, nil)
+}
+
+func (r *resilientClient) TranslateStream(
+	ctx context.Context, req *TranslateRequest, handler StreamHandler) (*TranslateResult, error) {
+	emitted := false
+	wrapped := func(event StreamEvent) {
+		emitted = true
+		if handler != nil {
+			handler(event)
+		}
+	}
+	return r.call(ctx, func(ctx context.Context) (*TranslateResult, error) {
+		return translateStream(ctx, r.client, req, wrapped)
+	}, func() {
+		// 失败的那次调用已推送了部分内容，重试前通知调用方丢弃
+		if emitted && handler != nil {
+			handler(StreamEvent{Reset: true})
+		}
+		emitted = false
+	})
+}
+
+func (r *resilientClient) Chat(ctx context.Context, req *ChatRequest) (*TranslateResult, error) {
+	chatClient, ok := r.client.(IChatLLMClient)
+	if !ok {
+		return nil, ErrChatUnsupported
+	}
+	return r.call(ctx, func(ctx context.Context) (*TranslateResult, error) {
+		return chatClient.Chat(ctx, req)
+	}, nil)
+}
+
+func (r *resilientClient) Health() ProviderHealth {
+	health := providerHealth(r.conf.Provider, r.client)
+	if r.conf.Breaker != nil {
+		state := r.conf.Breaker.State()
+		health.Breaker = &state
+	}
+	return health
+}
+
+// call 执行调用并在可重试的错误上退避重试，beforeRetry 在每次重试前调用
+func (r *resilientClient) call(ctx context.Context,
+	fn func(ctx context.Context) (*TranslateResult, error), beforeRetry func()) (*TranslateResult, error) {
+	breaker := r.conf.Breaker
+	for attempt := 0; ; attempt++ {
+		if breaker != nil {
+			if err := breaker.Allow(); err != nil {
+				return nil, err
+			}
+		}
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if _, limited := r.client.(*limitedClient); limited && r.conf.CallTimeout > 0 {
+			// 超时交给限流器在放行后开始计时，排队等待不会耗尽超时
+			callCtx = context.WithValue(ctx, callTimeoutKey{}, r.conf.CallTimeout)
+		} else if r.conf.CallTimeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, r.conf.CallTimeout)
+		}
+		result, err := fn(callCtx)
+		cancel()
+		if err == nil {
+			if breaker != nil {
+				breaker.Success()
+			}
+			return result, nil
+		}
+		if breaker != nil {
+			r.report(breaker, ctx, err)
+		}
+		if ctx.Err() != nil || attempt >= r.conf.MaxRetries {
+			return nil, err
+		}
+		delay, ok := r.retryDelay(attempt, err)
+		if !ok {
+			return nil, err
+		}
+		if beforeRetry != nil {
+			beforeRetry()
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+// report 根据错误类型更新熔断器：服务端故障与网络错误计为失败，
+// 其他服务端响应说明服务商可用，调用方取消或本地错误不计入
+func (r *resilientClient) report(breaker *CircuitBreaker, ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		breaker.Release()
+		return
+	}
+	var requestErr *RequestError
+	var decodeErr *DecodeError
+	if apiErr, ok := IsAPIError(err); ok {
+		switch {
+		case apiErr.IsServerError():
+			breaker.Failure(err)
+		case apiErr.IsRateLimited():
+			breaker.Release()
+		default:
+			breaker.Success()
+		}
+		return
+	}
+	if errors.As(err, &requestErr) || errors.As(err, &decodeErr) {
+		breaker.Failure(err)
+		return
+	}
+	breaker.Release()
+}
+
+// retryDelay 只重试 429、5xx 与网络错误，间隔为指数退避加随机抖动，服务端返回 Retry-After 时至少等待该时间
+func (r *resilientClient) retryDelay(attempt int, err error) (time.Duration, bool) {
+	var retryAfter time.Duration
+	if apiErr, ok := IsAPIError(err); ok {
+		if !apiErr.IsRateLimited() && !apiErr.IsServerError() {
+			return 0, false
+		}
+		retryAfter = apiErr.RetryAfter
+	} else {
+		var requestErr *RequestError
+		if !errors.As(err, &requestErr) {
+			return 0, false
+		}
+	}
+	if retryAfter > r.conf.MaxRetryAfter {
+		return 0, false
+	}
+	backoff := r.conf.BaseDelay << attempt
+	if backoff <= 0 || backoff > r.conf.MaxDelay {
+		backoff = r.conf.MaxDelay
+	}
+	delay := backoff/2 + time.Duration(rand.Int64N(int64(backoff/2)+1))
+	return max(delay, retryAfter), true
+}
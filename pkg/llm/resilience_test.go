@@ -0,0 +1,254 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubClient 按顺序返回 errs 中的错误，用完后返回成功，delay 为每次调用的耗时
+// 流式调用失败前先推送一段增量，模拟生成到一半中断
+type stubClient struct {
+	name  string
+	delay time.Duration
+
+	mu    sync.Mutex
+	errs  []error
+	calls int
+	reqs  []*TranslateRequest
+}
+
+func (c *stubClient) Translate(ctx context.Context, req *TranslateRequest) (*TranslateResult, error) {
+	c.mu.Lock()
+	c.calls++
+	c.reqs = append(c.reqs, req)
+	var err error
+	if len(c.errs) > 0 {
+		err, c.errs = c.errs[0], c.errs[1:]
+	}
+	c.mu.Unlock()
+	if c.delay > 0 {
+		select {
+		case <-time.After(c.delay):
+		case <-ctx.Done():
+			return nil, &RequestError{Err: ctx.Err()}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &TranslateResult{Text: "[" + c.name + "] " + req.Content, Provider: c.name}, nil
+}
+
+func (c *stubClient) TranslateStream(
+	ctx context.Context, req *TranslateRequest, handler StreamHandler) (*TranslateResult, error) {
+	result, err := c.Translate(ctx, req)
+	if err != nil {
+		handler(StreamEvent{Delta: "partial"})
+		return nil, err
+	}
+	handler(StreamEvent{Delta: result.Text})
+	return result, nil
+}
+
+func (c *stubClient) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	b := NewCircuitBreaker("p1", BreakerConfig{FailureThreshold: 2, Cooldown: 30 * time.Millisecond})
+	failure := errors.New("boom")
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("closed breaker rejected: %v", err)
+		}
+		b.Failure(failure)
+	}
+	var openErr *CircuitOpenError
+	if err := b.Allow(); !errors.As(err, &openErr) || openErr.Provider != "p1" {
+		t.Fatalf("open breaker allowed: %v", err)
+	}
+	if state := b.State(); state.State != BreakerOpen || state.ConsecutiveFailures != 2 ||
+		state.LastError != "boom" || state.RetryAt == nil {
+		t.Errorf("state = %+v", state)
+	}
+
+	// 冷却结束后只放行一个探测请求，探测失败重新熔断
+	time.Sleep(40 * time.Millisecond)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	if b.State().State != BreakerHalfOpen || b.Allow() == nil {
+		t.Fatal("second probe allowed while half open")
+	}
+	b.Failure(failure)
+	if b.State().State != BreakerOpen || b.Allow() == nil {
+		t.Fatal("failed probe did not reopen the breaker")
+	}
+
+	// 没有结论的探测释放名额，成功的探测关闭熔断器
+	time.Sleep(40 * time.Millisecond)
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Release()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe after release rejected: %v", err)
+	}
+	b.Success()
+	if state := b.State(); state.State != BreakerClosed || state.ConsecutiveFailures != 0 || state.RetryAt != nil {
+		t.Errorf("state after success = %+v", state)
+	}
+}
+
+func TestResilientRetry(t *testing.T) {
+	serverErr := &APIError{StatusCode: 500}
+	cases := []struct {
+		name       string
+		errs       []error
+		maxRetries int
+		calls      int
+		ok         bool
+	}{
+		{"rate limit and server error", []error{&APIError{StatusCode: 429}, serverErr}, 0, 3, true},
+		{"network error", []error{&RequestError{Err: errors.New("reset")}}, 0, 2, true},
+		{"bad request", []error{&APIError{StatusCode: 400}}, 0, 1, false},
+		{"unauthorized", []error{&APIError{StatusCode: 401}}, 0, 1, false},
+		{"decode error", []error{&DecodeError{Err: errors.New("eof")}}, 0, 1, false},
+		{"retries exhausted", []error{serverErr, serverErr, serverErr}, 0, 3, false},
+		{"retries disabled", []error{serverErr}, -1, 1, false},
+		{"one retry", []error{serverErr, serverErr}, 1, 2, false},
+	}
+	for _, c := range cases {
+		stub := &stubClient{name: "p1", errs: c.errs}
+		client := NewResilientClient(stub, ResilienceConfig{
+			Provider: "p1", MaxRetries: c.maxRetries, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond,
+		})
+		_, err := client.Translate(context.Background(), testRequest())
+		if (err == nil) != c.ok || stub.count() != c.calls {
+			t.Errorf("%s: err = %v, calls = %d, want ok %v and %d calls", c.name, err, stub.count(), c.ok, c.calls)
+		}
+	}
+}
+
+func TestResilientRetryAfter(t *testing.T) {
+	stub := &stubClient{name: "p1", errs: []error{&APIError{StatusCode: 429, RetryAfter: 50 * time.Millisecond}}}
+	client := NewResilientClient(stub, ResilienceConfig{Provider: "p1", BaseDelay: time.Millisecond})
+	start := time.Now()
+	if _, err := client.Translate(context.Background(), testRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("retried after %s, before Retry-After", elapsed)
+	}
+
+	// 要求等待的时间超过上限时直接返回，由路由切换服务商
+	stub = &stubClient{name: "p1", errs: []error{&APIError{StatusCode: 429, RetryAfter: time.Hour}}}
+	client = NewResilientClient(stub, ResilienceConfig{
+		Provider: "p1", BaseDelay: time.Millisecond, MaxRetryAfter: 100 * time.Millisecond,
+	})
+	start = time.Now()
+	_, err := client.Translate(context.Background(), testRequest())
+	if apiErr, ok := IsAPIError(err); !ok || !apiErr.IsRateLimited() || stub.count() != 1 {
+		t.Errorf("err = %v, calls = %d", err, stub.count())
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("capped Retry-After still waited %s", elapsed)
+	}
+}
+
+func TestResilientBreaker(t *testing.T) {
+	serverErr := &APIError{StatusCode: 503}
+	stub := &stubClient{name: "p1", errs: []error{serverErr, &APIError{StatusCode: 400}, serverErr, serverErr}}
+	breaker := NewCircuitBreaker("p1", BreakerConfig{FailureThreshold: 2, Cooldown: time.Minute})
+	client := NewResilientClient(stub, ResilienceConfig{Provider: "p1", MaxRetries: -1, Breaker: breaker})
+	ctx := context.Background()
+
+	// 400 说明服务商可用，清零连续失败次数
+	for i := 0; i < 3; i++ {
+		if _, err := client.Translate(ctx, testRequest()); err == nil {
+			t.Fatalf("call %d succeeded", i)
+		}
+		if state := breaker.State(); state.State != BreakerClosed {
+			t.Fatalf("call %d opened the breaker: %+v", i, state)
+		}
+	}
+	if _, err := client.Translate(ctx, testRequest()); err == nil {
+		t.Fatal("fourth call succeeded")
+	}
+	var openErr *CircuitOpenError
+	if _, err := client.Translate(ctx, testRequest()); !errors.As(err, &openErr) || stub.count() != 4 {
+		t.Errorf("err = %v, calls = %d", err, stub.count())
+	}
+	if health := client.(IHealthLLMClient).Health(); health.Breaker == nil || health.Breaker.State != BreakerOpen {
+		t.Errorf("health = %+v", health)
+	}
+
+	// 调用方取消不计为服务商失败
+	breaker = NewCircuitBreaker("p2", BreakerConfig{FailureThreshold: 1})
+	client = NewResilientClient(&stubClient{name: "p2", delay: time.Second},
+		ResilienceConfig{Provider: "p2", Breaker: breaker})
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := client.Translate(cancelled, testRequest()); err == nil || breaker.State().State != BreakerClosed {
+		t.Errorf("cancelled call: err = %v, breaker = %+v", err, breaker.State())
+	}
+}
+
+func TestResilientStreamReset(t *testing.T) {
+	stub := &stubClient{name: "p1", errs: []error{&APIError{StatusCode: 500}}}
+	client := NewResilientClient(stub, ResilienceConfig{Provider: "p1", BaseDelay: time.Millisecond})
+	var events []StreamEvent
+	result, err := client.(IStreamLLMClient).TranslateStream(context.Background(), testRequest(), func(event StreamEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []StreamEvent{{Delta: "partial"}, {Reset: true}, {Delta: result.Text}}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v", events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestCallTimeoutExcludesLimiterWait(t *testing.T) {
+	// 同时只能进行一个请求，后面的请求排队时间超过 CallTimeout 仍然成功
+	stub := &stubClient{name: "p1", delay: 30 * time.Millisecond}
+	limited := NewLimitedClient(stub, LimitConfig{Provider: "p1", MaxInFlight: 1})
+	client := NewResilientClient(limited, ResilienceConfig{
+		Provider: "p1", CallTimeout: 50 * time.Millisecond, MaxRetries: -1,
+	})
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = client.Translate(context.Background(), testRequest())
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("call %d: %v", i, err)
+		}
+	}
+
+	// 上游调用本身超时仍然失败
+	slow := &stubClient{name: "p1", delay: 200 * time.Millisecond}
+	client = NewResilientClient(NewLimitedClient(slow, LimitConfig{Provider: "p1", MaxInFlight: 1}), ResilienceConfig{
+		Provider: "p1", CallTimeout: 20 * time.Millisecond, MaxRetries: -1,
+	})
+	if _, err := client.Translate(context.Background(), testRequest()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("slow upstream err = %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Route 路由规则，所有非空条件都满足时命中，按 Providers 的顺序依次尝试
+type Route struct {
+	Name        string
+	SourceLangs []string
+	TargetLangs []string
+	Users       []string
+	// MinLength/MaxLength 按字符数计算，0 表示不限制
+	MinLength int
+	MaxLength int
+	Providers []string
+}
+
+func (r *Route) match(req *TranslateRequest) bool {
+	if !matchLang(r.SourceLangs, req.Lang) || !matchLang(r.TargetLangs, req.TargetLang) {
+		return false
+	}
+	if len(r.Users) > 0 && !contains(r.Users, req.Username) {
+		return false
+	}
+	length := utf8.RuneCountInString(req.Content)
+	if r.MinLength > 0 && length < r.MinLength {
+		return false
+	}
+	if r.MaxLength > 0 && length > r.MaxLength {
+		return false
+	}
+	return true
+}
+
+type router struct {
+	registry         *Registry
+	routes           []Route
+	defaultProviders []string
+}
+
+// NewRouter 创建按规则选择服务商的客户端，未命中任何规则时使用 defaultProviders，
+// defaultProviders 为空时按注册顺序使用全部服务商
+func NewRouter(registry *Registry, routes []Route, defaultProviders []string) ILLMClient {
+	return &router{
+		registry:         registry,
+		routes:           routes,
+		defaultProviders: defaultProviders,
+	}
+}
+
+func (r *router) Translate(ctx context.Context, req *TranslateRequest) (*TranslateResult, error) {
+	return r.dispatch(ctx, req, func(client ILLMClient) (*TranslateResult, bool, error) {
+		result, err := client.Translate(ctx, req)
+		return result, true, err
+	})
+}
+
+// dispatch 按路由规则依次调用服务商直到成功，call 返回的 bool 为 false 表示该服务商不支持此类调用，直接跳过
+func (r *router) dispatch(ctx context.Context, req *TranslateRequest,
+	call func(client ILLMClient) (*TranslateResult, bool, error)) (*TranslateResult, error) {
+	providers := r.selectProviders(req)
+	if len(providers) == 0 {
+		return nil, ErrNoProvider
+	}
+	attempts := make([]Attempt, 0, len(providers))
+	var lastErr error
+	for _, name := range providers {
+		client, ok := r.registry.Get(name)
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		result, supported, err := call(client)
+		if !supported {
+			continue
+		}
+		attempt := Attempt{
+			Provider: name,
+			CostMs:   time.Since(start).Milliseconds(),
+		}
+		if err == nil {
+			attempts = append(attempts, attempt)
+			if result.Provider == "" {
+				result.Provider = name
+			}
+			result.Attempts = attempts
+			return result, nil
+		}
+		attempt.Error = err.Error()
+		attempts = append(attempts, attempt)
+		lastErr = err
+		// 调用方已取消或超时，继续尝试下一个服务商没有意义
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		return nil, ErrNoProvider
+	}
+	return nil, &AllProvidersFailedError{Attempts: attempts, Err: lastErr}
+}
+
+func (r *router) selectProviders(req *TranslateRequest) []string {
+	for i := range r.routes {
+		if r.routes[i].match(req) {
+			return r.routes[i].Providers
+		}
+	}
+	if len(r.defaultProviders) > 0 {
+		return r.defaultProviders
+	}
+	return r.registry.Names()
+}
+
+// matchLang 规则为空时匹配任意语言，"zh" 可匹配 "zh-CN" 等地区变体
+func matchLang(patterns []string, lang string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	lang = strings.ToLower(lang)
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if p == "*" || p == lang || strings.HasPrefix(lang, p+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRegistry 按顺序注册 stubs，名称取自各 stub
+func newTestRegistry(t *testing.T, stubs ...*stubClient) *Registry {
+	t.Helper()
+	registry := NewRegistry()
+	for _, stub := range stubs {
+		if err := registry.Register(stub.name, stub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return registry
+}
+
+func attemptProviders(attempts []Attempt) []string {
+	names := make([]string, 0, len(attempts))
+	for _, attempt := range attempts {
+		names = append(names, attempt.Provider)
+	}
+	return names
+}
+
+func TestRouterSelectProviders(t *testing.T) {
+	registry := newTestRegistry(t, &stubClient{name: "p1"}, &stubClient{name: "p2"}, &stubClient{name: "p3"})
+	routes := []Route{
+		{Name: "vip", Users: []string{"vip"}, Providers: []string{"p3"}},
+		{Name: "chinese", SourceLangs: []string{"zh"}, TargetLangs: []string{"en", "ja"}, Providers: []string{"p2", "p1"}},
+		{Name: "long", MinLength: 10, MaxLength: 20, Providers: []string{"p3", "p2"}},
+	}
+	cases := []struct {
+		name     string
+		req      TranslateRequest
+		defaults []string
+		want     []string
+	}{
+		{"user", TranslateRequest{Lang: "zh", TargetLang: "en", Content: "你好", Username: "vip"}, nil, []string{"p3"}},
+		{"language variant", TranslateRequest{Lang: "zh-CN", TargetLang: "EN-us", Content: "你好"}, nil, []string{"p2", "p1"}},
+		{"language mismatch", TranslateRequest{Lang: "zh", TargetLang: "fr", Content: "你好"}, nil, []string{"p1", "p2", "p3"}},
+		{"length", TranslateRequest{Lang: "en", TargetLang: "fr", Content: "Hello world!"}, nil, []string{"p3", "p2"}},
+		{"too long", TranslateRequest{Lang: "en", TargetLang: "fr", Content: strings.Repeat("a", 21)}, nil, []string{"p1", "p2", "p3"}},
+		{"defaults", TranslateRequest{Lang: "en", TargetLang: "fr", Content: "Hi"}, []string{"p2"}, []string{"p2"}},
+	}
+	for _, c := range cases {
+		r := NewRouter(registry, routes, c.defaults).(*router)
+		if got := r.selectProviders(&c.req); !slices.Equal(got, c.want) {
+			t.Errorf("%s: providers = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRouterFallback(t *testing.T) {
+	p1 := &stubClient{name: "p1", errs: []error{&APIError{StatusCode: 500}}}
+	p2 := &stubClient{name: "p2", errs: []error{&APIError{StatusCode: 429}}}
+	p3 := &stubClient{name: "p3"}
+	registry := newTestRegistry(t, p1, p2, p3)
+	// 未注册的服务商跳过
+	client := NewRouter(registry, nil, []string{"p2", "missing", "p1", "p3"})
+	result, err := client.Translate(context.Background(), testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Provider != "p3" || !slices.Equal(attemptProviders(result.Attempts), []string{"p2", "p1", "p3"}) {
+		t.Errorf("provider = %s, attempts = %+v", result.Provider, result.Attempts)
+	}
+	if result.Attempts[0].Error == "" || result.Attempts[1].Error == "" || result.Attempts[2].Error != "" {
+		t.Errorf("attempt errors = %+v", result.Attempts)
+	}
+
+	// 全部失败时返回每次尝试与最后一个错误
+	p1.errs = []error{&APIError{StatusCode: 500}}
+	p2.errs = []error{&APIError{StatusCode: 400}}
+	client = NewRouter(registry, nil, []string{"p1", "p2"})
+	_, err = client.Translate(context.Background(), testRequest())
+	var failed *AllProvidersFailedError
+	if !errors.As(err, &failed) || len(failed.Attempts) != 2 {
+		t.Fatalf("err = %v", err)
+	}
+	if apiErr, ok := IsAPIError(err); !ok || apiErr.StatusCode != 400 {
+		t.Errorf("last error = %v", err)
+	}
+
+	if _, err = NewRouter(NewRegistry(), nil, nil).Translate(context.Background(), testRequest()); !errors.Is(err, ErrNoProvider) {
+		t.Errorf("empty registry err = %v", err)
+	}
+	if _, err = NewRouter(registry, nil, []string{"missing"}).Translate(context.Background(), testRequest()); !errors.Is(err, ErrNoProvider) {
+		t.Errorf("unknown providers err = %v", err)
+	}
+}
+
+func TestRouterStopsOnCancel(t *testing.T) {
+	p1 := &stubClient{name: "p1", delay: time.Second}
+	p2 := &stubClient{name: "p2"}
+	client := NewRouter(newTestRegistry(t, p1, p2), nil, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Translate(ctx, testRequest())
+	var failed *AllProvidersFailedError
+	if !errors.As(err, &failed) || len(failed.Attempts) != 1 || p2.count() != 0 {
+		t.Errorf("err = %v, p2 calls = %d", err, p2.count())
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.(IStreamLLMClient).TranslateStream(ctx, testRequest(), func(StreamEvent) {})
+	if !errors.As(err, &failed) || len(failed.Attempts) != 1 || p2.count() != 0 {
+		t.Errorf("stream err = %v, p2 calls = %d", err, p2.count())
+	}
+}
+
+func TestRouterStreamFallback(t *testing.T) {
+	p1 := &stubClient{name: "p1", errs: []error{&APIError{StatusCode: 503}}}
+	p2 := &stubClient{name: "p2"}
+	client := NewRouter(newTestRegistry(t, p1, p2), nil, nil)
+	var events []StreamEvent
+	result, err := client.(IStreamLLMClient).TranslateStream(context.Background(), testRequest(), func(event StreamEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []StreamEvent{{Delta: "partial"}, {Reset: true}, {Delta: "[p2] Hello"}}
+	if !slices.Equal(events, want) || result.Provider != "p2" ||
+		!slices.Equal(attemptProviders(result.Attempts), []string{"p1", "p2"}) {
+		t.Errorf("events = %+v, result = %+v", events, result)
+	}
+}
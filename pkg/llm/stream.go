@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// IStreamLLMClient 支持流式输出的客户端
+type IStreamLLMClient interface {
+	ILLMClient
+	// TranslateStream 边生成边回调增量文本，返回的结果中包含完整译文
+	TranslateStream(ctx context.Context, req *TranslateRequest, handler StreamHandler) (*TranslateResult, error)
+}
+
+// StreamEvent 流式输出事件
+// Reset 为 true 表示此前推送的增量全部作废，例如切换到了另一个服务商重新生成
+type StreamEvent struct {
+	Delta string
+	Reset bool
+}
+
+type StreamHandler func(event StreamEvent)
+
+const (
+	sseDataPrefix = "data:"
+	sseDone       = "[DONE]"
+)
+
+func (c *llmClient) TranslateStream(
+	ctx context.Context, req *TranslateRequest, handler StreamHandler) (*TranslateResult, error) {
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, ErrEmptyContent
+	}
+	messages, err := buildTranslateMessages(req)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	httpResp, err := c.post(ctx, &chatCompletionRequest{
+		Model:         c.model,
+		Messages:      messages,
+		Stream:        true,
+		StreamOptions: &streamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	model := c.model
+	var usage Usage
+	var text strings.Builder
+	reader := bufio.NewReader(httpResp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, &RequestError{Err: err}
+		}
+		eof := err != nil
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, sseDataPrefix) {
+			data := strings.TrimSpace(strings.TrimPrefix(line, sseDataPrefix))
+			if data == sseDone {
+				break
+			}
+			var chunk chatCompletionChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				return nil, &DecodeError{Body: truncate([]byte(data)), Err: err}
+			}
+			if chunk.Error != nil {
+				return nil, chunk.Error.toAPIError(httpResp.StatusCode)
+			}
+			if chunk.Model != "" {
+				model = chunk.Model
+			}
+			// 用量在最后一个事件中返回，该事件的 choices 为空
+			if chunk.Usage != nil {
+				usage = chunk.Usage.toUsage()
+			}
+			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+				delta := chunk.Choices[0].Delta.Content
+				text.WriteString(delta)
+				if handler != nil {
+					handler(StreamEvent{Delta: delta})
+				}
+			}
+		}
+		if eof {
+			break
+		}
+	}
+	if text.Len() == 0 {
+		return nil, ErrNoChoices
+	}
+	return &TranslateResult{
+		Text:     strings.TrimSpace(text.String()),
+		Provider: c.name,
+		Model:    model,
+		Usage:    usage,
+		Latency:  time.Since(start),
+	}, nil
+}
+
+func (r *router) TranslateStream(
+	ctx context.Context, req *TranslateRequest, handler StreamHandler) (*TranslateResult, error) {
+	providers := r.selectProviders(req)
+	if len(providers) == 0 {
+		return nil, ErrNoProvider
+	}
+	attempts := make([]Attempt, 0, len(providers))
+	var lastErr error
+	for _, name := range providers {
+		client, ok := r.registry.Get(name)
+		if !ok {
+			continue
+		}
+		emitted := false
+		wrapped := func(event StreamEvent) {
+			emitted = true
+			if handler != nil {
+				handler(event)
+			}
+		}
+		start := time.Now()
+		result, err := translateStream(ctx, client, req, wrapped)
+		attempt := Attempt{
+			Provider: name,
+			CostMs:   time.Since(start).Milliseconds(),
+		}
+		if err == nil {
+			attempts = append(attempts, attempt)
+			if result.Provider == "" {
+				result.Provider = name
+			}
+			result.Attempts = attempts
+			return result, nil
+		}
+		attempt.Error = err.Error()
+		attempts = append(attempts, attempt)
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+		// 已推送的部分内容来自失败的服务商，回退前通知调用方丢弃
+		if emitted && handler != nil {
+			handler(StreamEvent{Reset: true})
+		}
+	}
+	if lastErr == nil {
+		return nil, ErrNoProvider
+	}
+	return nil, &AllProvidersFailedError{Attempts: attempts, Err: lastErr}
+}
+
+// translateStream 客户端不支持流式输出时退化为一次性推送完整译文
+func translateStream(
+	ctx context.Context, client ILLMClient, req *TranslateRequest, handler StreamHandler) (*TranslateResult, error) {
+	if streamClient, ok := client.(IStreamLLMClient); ok {
+		return streamClient.TranslateStream(ctx, req, handler)
+	}
+	result, err := client.Translate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if handler != nil {
+		handler(StreamEvent{Delta: result.Text})
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"sort"
+	"strings"
+)
+
+// Style 任务的译文风格，各字段为空时不做要求
+type Style struct {
+	Tone      string
+	Formality string
+	Domain    string
+}
+
+var tonePresets = map[string]string{
+	"neutral":      "Use a neutral, objective tone.",
+	"friendly":     "Use a warm and friendly tone.",
+	"professional": "Use a professional, businesslike tone.",
+	"casual":       "Use a relaxed, conversational tone.",
+	"persuasive":   "Use a persuasive, engaging tone.",
+}
+
+var formalityPresets = map[string]string{
+	"formal": "Use a formal register and polite forms of address (for example Sie, vous, usted, 您) " +
+		"wherever the target language distinguishes them.",
+	"informal": "Use an informal register and familiar forms of address (for example du, tu, tú, 你) " +
+		"wherever the target language distinguishes them.",
+}
+
+var domainPresets = map[string]string{
+	"general": "",
+	"legal": "The text is a legal document. Preserve the precise legal meaning, use the established legal " +
+		"terminology of the target language, and never simplify or paraphrase obligations, conditions or definitions.",
+	"marketing": "The text is marketing copy. Adapt idioms, slogans and wordplay so that the message feels " +
+		"natural and compelling to native readers instead of translating word for word.",
+	"ui": "The text consists of user interface strings. Keep translations short and consistent, use the usual " +
+		"imperative style for buttons and actions, and never translate placeholders, variables or keyboard shortcuts.",
+	"technical": "The text is technical documentation. Keep terminology precise and consistent, and leave code, " +
+		"commands, identifiers and units unchanged.",
+	"medical": "The text is medical content. Use standard medical terminology of the target language and keep " +
+		"dosages, units and measurements exactly as written.",
+	"finance": "The text is financial content. Use standard financial terminology of the target language and " +
+		"keep figures, currencies and dates exactly as written.",
+}
+
+// StyleOptions 各风格字段可选的值
+func StyleOptions() map[string][]string {
+	return map[string][]string{
+		"tone":      presetKeys(tonePresets),
+		"formality": presetKeys(formalityPresets),
+		"domain":    presetKeys(domainPresets),
+	}
+}
+
+// instructions 把风格转换为提示词中的要求
+func (s Style) instructions() string {
+	var parts []string
+	for _, text := range []string{domainPresets[s.Domain], tonePresets[s.Tone], formalityPresets[s.Formality]} {
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "\n\n" + strings.Join(parts, " ")
+}
+
+func presetKeys(presets map[string]string) []string {
+	keys := make([]string, 0, len(presets))
+	for k := range presets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultTemplateName 内置模板的名称，未指定模板的任务使用该模板
+const DefaultTemplateName = "default"
+
+// DefaultTemplateText 内置模板内容
+const DefaultTemplateText = "You are a professional translator. " +
+	"{{if .AutoDetect}}Detect the language of the user's text and translate it into {{.TargetLang}}." +
+	"{{else}}Translate the user's text from {{.SourceLang}} into {{.TargetLang}}.{{end}} " +
+	"Preserve the original formatting, line breaks and markup. " +
+	"Reply with the translation only, without explanations, notes or quotes." +
+	"{{.Instructions}}{{.Glossary}}{{.References}}{{.Context}}"
+
+// PromptData 系统提示词模板可用的变量
+// Instructions、Glossary、References、Context 为已经排版好的段落，为空时是空字符串，否则以空行开头
+type PromptData struct {
+	SourceLang   string
+	TargetLang   string
+	AutoDetect   bool
+	Tone         string
+	Formality    string
+	Domain       string
+	Instructions string
+	Glossary     string
+	References   string
+	Context      string
+}
+
+// PromptTemplate 编译后的系统提示词模板，Version 为 0 表示内置或配置文件中的模板
+type PromptTemplate struct {
+	Name    string
+	Version int
+	tmpl    *template.Template
+	// digest 模板原文的摘要，配置文件中的模板版本号恒为 0，修改内容后需要靠摘要区分
+	digest string
+}
+
+// NewPromptTemplate 编译模板并用示例数据试渲染，引用不存在的变量等错误在此返回
+func NewPromptTemplate(name string, version int, text string) (*PromptTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("llm: parse prompt template %s: %w", name, err)
+	}
+	sum := sha256.Sum256([]byte(text))
+	p := &PromptTemplate{Name: name, Version: version, tmpl: tmpl, digest: hex.EncodeToString(sum[:8])}
+	if _, err = p.Render(samplePromptData); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Fingerprint 标识模板的名称、版本与内容
+func (p *PromptTemplate) Fingerprint() string {
+	return fmt.Sprintf("%s@%d#%s", p.Name, p.Version, p.digest)
+}
+
+var defaultPromptTemplate = mustPromptTemplate(DefaultTemplateName, 0, DefaultTemplateText)
+
+// DefaultPromptTemplate 返回内置模板
+func DefaultPromptTemplate() *PromptTemplate {
+	return defaultPromptTemplate
+}
+
+func mustPromptTemplate(name string, version int, text string) *PromptTemplate {
+	p, err := NewPromptTemplate(name, version, text)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Render 渲染系统提示词
+func (p *PromptTemplate) Render(data *PromptData) (string, error) {
+	var sb strings.Builder
+	if err := p.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("llm: render prompt template %s: %w", p.Name, err)
+	}
+	return sb.String(), nil
+}
+
+var samplePromptData = &PromptData{
+	SourceLang:   "en",
+	TargetLang:   "zh-Hans",
+	Tone:         "neutral",
+	Formality:    "formal",
+	Domain:       "general",
+	Instructions: "\n\nUse a neutral, objective tone.",
+	Glossary:     "\n\nApply this glossary strictly, translating each source term exactly as given:\n- \"term\" => \"术语\"\n",
+	References:   "",
+	Context:      "",
+}
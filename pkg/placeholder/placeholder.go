@@ -0,0 +1,300 @@
+package placeholder
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kind 占位符类型
+type Kind string
+
+const (
+	KindTemplate Kind = "template" // {{count}}
+	KindBrace    Kind = "brace"    // {name}、{0}
+	KindPrintf   Kind = "printf"   // %s、%1$d
+	KindICU      Kind = "icu"      // ICU plural/select 的结构部分与 #
+	KindHTML     Kind = "html"     // <b>、</a>、<br/>
+)
+
+// Mode 占位符不一致时的处理方式
+const (
+	ModeOff    = "off"    // 不保护占位符
+	ModeWarn   = "warn"   // 记录问题，任务照常完成
+	ModeStrict = "strict" // 重试后仍不一致时任务失败
+)
+
+// Modes 可选的处理方式
+func Modes() []string {
+	return []string{ModeOff, ModeWarn, ModeStrict}
+}
+
+// IssueKind 译文中占位符的问题
+type IssueKind string
+
+const (
+	IssueMissing    IssueKind = "missing"    // 占位符被删除或改写
+	IssueDuplicated IssueKind = "duplicated" // 占位符出现了多次
+	IssueUnexpected IssueKind = "unexpected" // 出现了原文中不存在的标记
+)
+
+type Issue struct {
+	Kind        IssueKind `json:"kind"`
+	Placeholder string    `json:"placeholder"`
+}
+
+// Token 被替换掉的原文片段
+type Token struct {
+	Kind Kind
+	Text string
+}
+
+// Masked 替换后的文本，Text 中第 i 个 Token 以 ⟦i+1⟧ 表示
+type Masked struct {
+	Text   string
+	Tokens []Token
+}
+
+var (
+	sentinelPattern  = regexp.MustCompile(`⟦\s*(\d+)\s*⟧`)
+	icuHeadPattern   = regexp.MustCompile(`^\{\s*[A-Za-z_][\w.]*\s*,\s*(plural|select|selectordinal)\s*,`)
+	icuOptionPattern = regexp.MustCompile(
+		`^\s*(?:offset:\s*\d+\s+)?(?:=\d+|[A-Za-z_][\w-]*)\s*\{`)
+	bracePattern  = regexp.MustCompile(`^\{\s*[\w.-]+\s*(?:,[^{}]*)?\}`)
+	printfPattern = regexp.MustCompile(`^%(?:\d+\$)?[-+#0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?[sdifFeEgGxXoucpqvtTb%]`)
+	htmlPattern   = regexp.MustCompile(`^(?:<!--[\s\S]*?-->|</?[A-Za-z][\w:-]*(?:\s+[^<>]*?)?\s*/?>)`)
+)
+
+func sentinel(i int) string {
+	return fmt.Sprintf("⟦%d⟧", i+1)
+}
+
+type masker struct {
+	out     bytes.Buffer
+	tokens  []Token
+	pending bytes.Buffer
+}
+
+// Mask 把占位符、ICU 结构与 HTML 标签替换为模型不会翻译的标记，ICU 选项中的文字仍然交给模型翻译
+func Mask(text string) *Masked {
+	m := &masker{}
+	m.scan(text, false)
+	return &Masked{Text: m.out.String(), Tokens: m.tokens}
+}
+
+func (m *masker) add(kind Kind, text string) {
+	m.out.WriteString(sentinel(len(m.tokens)))
+	m.tokens = append(m.tokens, Token{Kind: kind, Text: text})
+}
+
+// flush 连续的 ICU 结构合并为一个标记，例如 "} other {"
+func (m *masker) flush() {
+	if m.pending.Len() > 0 {
+		m.add(KindICU, m.pending.String())
+		m.pending.Reset()
+	}
+}
+
+// scan plural 为 true 时处于 plural 选项内，# 表示数值
+func (m *masker) scan(s string, plural bool) {
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		switch s[i] {
+		case '{':
+			if strings.HasPrefix(rest, "{{") {
+				if end := strings.Index(rest[2:], "}}"); end >= 0 {
+					m.add(KindTemplate, rest[:end+4])
+					i += end + 4
+					continue
+				}
+			}
+			if loc := icuHeadPattern.FindStringSubmatchIndex(rest); loc != nil {
+				if n, ok := m.icu(rest, loc[1], rest[loc[2]:loc[3]] != "select"); ok {
+					i += n
+					continue
+				}
+			}
+			if loc := bracePattern.FindStringIndex(rest); loc != nil {
+				m.add(KindBrace, rest[:loc[1]])
+				i += loc[1]
+				continue
+			}
+		case '%':
+			if loc := printfPattern.FindStringIndex(rest); loc != nil {
+				m.add(KindPrintf, rest[:loc[1]])
+				i += loc[1]
+				continue
+			}
+		case '<':
+			if loc := htmlPattern.FindStringIndex(rest); loc != nil {
+				m.add(KindHTML, rest[:loc[1]])
+				i += loc[1]
+				continue
+			}
+		case '#':
+			if plural {
+				m.add(KindICU, "#")
+				i++
+				continue
+			}
+		}
+		m.out.WriteByte(s[i])
+		i++
+	}
+}
+
+// icu 解析从 s 开头的 ICU plural/select，head 为 "{name, plural," 的长度，返回消耗的字节数
+// 括号不配对时撤销已生成的标记并返回 false，由调用方按普通文本处理
+func (m *masker) icu(s string, head int, plural bool) (int, bool) {
+	outLen, tokenLen := m.out.Len(), len(m.tokens)
+	fail := func() (int, bool) {
+		m.out.Truncate(outLen)
+		m.tokens = m.tokens[:tokenLen]
+		m.pending.Reset()
+		return 0, false
+	}
+	m.pending.WriteString(s[:head])
+	i := head
+	for {
+		rest := s[i:]
+		trimmed := strings.TrimLeft(rest, " \t\r\n")
+		if strings.HasPrefix(trimmed, "}") {
+			n := len(rest) - len(trimmed) + 1
+			m.pending.WriteString(rest[:n])
+			m.flush()
+			return i + n, true
+		}
+		loc := icuOptionPattern.FindStringIndex(rest)
+		if loc == nil {
+			return fail()
+		}
+		m.pending.WriteString(rest[:loc[1]])
+		i += loc[1]
+		end := matchBrace(s[i:])
+		if end < 0 {
+			return fail()
+		}
+		if end > 0 {
+			m.flush()
+			m.scan(s[i:i+end], plural)
+		}
+		m.pending.WriteByte('}')
+		i += end + 1
+	}
+}
+
+// matchBrace 返回与已消耗的 { 配对的 } 在 s 中的位置
+func matchBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// Restore 把译文中的标记还原为原文片段，并检查每个占位符是否恰好出现一次
+// 重复出现的占位符同样会被还原，无法识别的标记保留原样
+func (m *Masked) Restore(translation string) (string, []Issue) {
+	counts := make([]int, len(m.Tokens))
+	var issues []Issue
+	restored := sentinelPattern.ReplaceAllStringFunc(translation, func(s string) string {
+		i, ok := m.lookup(s)
+		if !ok {
+			issues = append(issues, Issue{Kind: IssueUnexpected, Placeholder: s})
+			return s
+		}
+		counts[i]++
+		return m.Tokens[i].Text
+	})
+	for i, n := range counts {
+		switch {
+		case n == 0:
+			issues = append(issues, Issue{Kind: IssueMissing, Placeholder: m.Tokens[i].Text})
+		case n > 1:
+			issues = append(issues, Issue{Kind: IssueDuplicated, Placeholder: m.Tokens[i].Text})
+		}
+	}
+	return restored, issues
+}
+
+func (m *Masked) lookup(s string) (int, bool) {
+	match := sentinelPattern.FindStringSubmatch(s)
+	if match == nil {
+		return 0, false
+	}
+	i, err := strconv.Atoi(match[1])
+	if err != nil || i < 1 || i > len(m.Tokens) {
+		return 0, false
+	}
+	return i - 1, true
+}
+
+// StreamRestorer 还原流式增量中的标记，标记被拆分到两个增量中时等待后一个增量再输出，流结束时调用 Flush
+type StreamRestorer struct {
+	masked *Masked
+	buf    string
+}
+
+// StreamRestorer m 为 nil 时增量原样输出
+func (m *Masked) StreamRestorer() *StreamRestorer {
+	return &StreamRestorer{masked: m}
+}
+
+// Restore 返回可以输出的部分，末尾未完成的标记留到下一个增量
+func (r *StreamRestorer) Restore(delta string) string {
+	if r.masked == nil {
+		return delta
+	}
+	r.buf += delta
+	emit := r.buf
+	if open := strings.LastIndex(r.buf, "⟦"); open >= 0 && !strings.Contains(r.buf[open:], "⟧") &&
+		len(r.buf)-open <= len("⟦⟧")+8 {
+		emit, r.buf = r.buf[:open], r.buf[open:]
+	} else if n := partialOpen(r.buf); n > 0 {
+		// ⟦ 的 UTF-8 编码被拆分到两个增量中
+		emit, r.buf = r.buf[:len(r.buf)-n], r.buf[len(r.buf)-n:]
+	} else {
+		r.buf = ""
+	}
+	return r.masked.restoreKnown(emit)
+}
+
+// Flush 输出缓冲中剩余的内容，流结束时仍未完成的标记原样输出
+func (r *StreamRestorer) Flush() string {
+	rest := r.buf
+	r.buf = ""
+	if r.masked == nil {
+		return rest
+	}
+	return r.masked.restoreKnown(rest)
+}
+
+// restoreKnown 还原可以识别的标记，其余保留原样
+func (m *Masked) restoreKnown(s string) string {
+	return sentinelPattern.ReplaceAllStringFunc(s, func(s string) string {
+		if i, ok := m.lookup(s); ok {
+			return m.Tokens[i].Text
+		}
+		return s
+	})
+}
+
+// partialOpen s 末尾是 ⟦ 的前几个字节时返回其长度
+func partialOpen(s string) int {
+	for n := len("⟦") - 1; n > 0; n-- {
+		if len(s) >= n && strings.HasPrefix("⟦", s[len(s)-n:]) {
+			return n
+		}
+	}
+	return 0
+}
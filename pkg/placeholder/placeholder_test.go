@@ -0,0 +1,155 @@
+package placeholder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	cases := []struct {
+		name   string
+		text   string
+		masked string
+		tokens []Token
+	}{
+		{
+			name:   "printf",
+			text:   "Deleted %d of %s files, 100% done %1$-5.2f %%",
+			masked: "Deleted ⟦1⟧ of ⟦2⟧ files, 100% done ⟦3⟧ ⟦4⟧",
+			tokens: []Token{{KindPrintf, "%d"}, {KindPrintf, "%s"}, {KindPrintf, "%1$-5.2f"}, {KindPrintf, "%%"}},
+		},
+		{
+			name:   "template and brace",
+			text:   "Hi {{user.name}}, you have {count} new {0} {item.title, number}",
+			masked: "Hi ⟦1⟧, you have ⟦2⟧ new ⟦3⟧ ⟦4⟧",
+			tokens: []Token{{KindTemplate, "{{user.name}}"}, {KindBrace, "{count}"}, {KindBrace, "{0}"},
+				{KindBrace, "{item.title, number}"}},
+		},
+		{
+			// 空的大括号是普通文字
+			name:   "empty braces",
+			text:   "Use {} or { } in prose, not {x}",
+			masked: "Use {} or { } in prose, not ⟦1⟧",
+			tokens: []Token{{KindBrace, "{x}"}},
+		},
+		{
+			name:   "icu plural",
+			text:   "{count, plural, =0 {No items} one {# item} other {# items}}",
+			masked: "⟦1⟧No items⟦2⟧⟦3⟧ item⟦4⟧⟦5⟧ items⟦6⟧",
+			tokens: []Token{{KindICU, "{count, plural, =0 {"}, {KindICU, "} one {"}, {KindICU, "#"},
+				{KindICU, "} other {"}, {KindICU, "#"}, {KindICU, "}}"}},
+		},
+		{
+			// select 中的 # 是普通文字，选项中的占位符与标签仍然替换
+			name:   "icu select",
+			text:   "{gender, select, female {<b>She</b> is #1} other {They are {name}}}",
+			masked: "⟦1⟧⟦2⟧She⟦3⟧ is #1⟦4⟧They are ⟦5⟧⟦6⟧",
+			tokens: []Token{{KindICU, "{gender, select, female {"}, {KindHTML, "<b>"}, {KindHTML, "</b>"},
+				{KindICU, "} other {"}, {KindBrace, "{name}"}, {KindICU, "}}"}},
+		},
+		{
+			name:   "nested icu",
+			text:   "{n, plural, one {{g, select, f {her} other {their}} #} other {#}}",
+			masked: "⟦1⟧⟦2⟧her⟦3⟧their⟦4⟧ ⟦5⟧⟦6⟧⟦7⟧⟦8⟧",
+			tokens: []Token{{KindICU, "{n, plural, one {"}, {KindICU, "{g, select, f {"}, {KindICU, "} other {"},
+				{KindICU, "}}"}, {KindICU, "#"}, {KindICU, "} other {"}, {KindICU, "#"}, {KindICU, "}}"}},
+		},
+		{
+			// 括号不配对时撤销已生成的 ICU 标记，其中的标签重新编号
+			name:   "icu rollback",
+			text:   "{count, plural, one {<i>#</i> item} other {# items}",
+			masked: "{count, plural, one {⟦1⟧#⟦2⟧ item} other {# items}",
+			tokens: []Token{{KindHTML, "<i>"}, {KindHTML, "</i>"}},
+		},
+		{
+			name:   "html",
+			text:   `<!-- keep <b>this</b> --><a href="/x?a=1&b=2">Link</a><br/> 3 < 4`,
+			masked: "⟦1⟧⟦2⟧Link⟦3⟧⟦4⟧ 3 < 4",
+			tokens: []Token{{KindHTML, "<!-- keep <b>this</b> -->"}, {KindHTML, `<a href="/x?a=1&b=2">`},
+				{KindHTML, "</a>"}, {KindHTML, "<br/>"}},
+		},
+	}
+	for _, c := range cases {
+		m := Mask(c.text)
+		if m.Text != c.masked || !reflect.DeepEqual(m.Tokens, c.tokens) {
+			t.Errorf("%s: Mask = %q %q\nwant %q %q", c.name, m.Text, m.Tokens, c.masked, c.tokens)
+			continue
+		}
+		if restored, issues := m.Restore(m.Text); restored != c.text || len(issues) != 0 {
+			t.Errorf("%s: Restore = %q %v", c.name, restored, issues)
+		}
+	}
+}
+
+func TestRestoreIssues(t *testing.T) {
+	m := Mask("Hello {name}, you have %d <b>new</b> messages")
+	cases := []struct {
+		translation string
+		restored    string
+		issues      []Issue
+	}{
+		{
+			translation: "Bonjour ⟦1⟧, vous avez ⟦ 2 ⟧ ⟦3⟧nouveaux⟦4⟧ messages",
+			restored:    "Bonjour {name}, vous avez %d <b>nouveaux</b> messages",
+		},
+		{
+			translation: "Bonjour ⟦1⟧ ⟦1⟧, ⟦2⟧ messages ⟦7⟧",
+			restored:    "Bonjour {name} {name}, %d messages ⟦7⟧",
+			issues: []Issue{
+				{IssueUnexpected, "⟦7⟧"},
+				{IssueDuplicated, "{name}"},
+				{IssueMissing, "<b>"},
+				{IssueMissing, "</b>"},
+			},
+		},
+	}
+	for _, c := range cases {
+		restored, issues := m.Restore(c.translation)
+		if restored != c.restored || !reflect.DeepEqual(issues, c.issues) {
+			t.Errorf("Restore(%q) = %q %v\nwant %q %v", c.translation, restored, issues, c.restored, c.issues)
+		}
+	}
+}
+
+func TestStreamRestorer(t *testing.T) {
+	m := Mask("Hello {name}, you have %d messages")
+	cases := []struct {
+		name   string
+		deltas []string
+		// want 各增量的输出，最后一项为 Flush 的输出
+		want []string
+	}{
+		{"whole", []string{"Bonjour ⟦1⟧, ", "⟦2⟧ messages"}, []string{"Bonjour {name}, ", "%d messages", ""}},
+		{"split sentinel", []string{"Bonjour ⟦", "1", "⟧, ⟦2", "⟧ messages"},
+			[]string{"Bonjour ", "", "{name}, ", "%d messages", ""}},
+		{"split rune", []string{"Bonjour \xe2\x9f", "\xa61⟧!"}, []string{"Bonjour ", "{name}!", ""}},
+		// 流结束时仍未完成的标记原样输出
+		{"unfinished", []string{"Bonjour ⟦1⟧ ⟦2"}, []string{"Bonjour {name} ", "⟦2"}},
+		{"unknown", []string{"⟦9⟧ ok"}, []string{"⟦9⟧ ok", ""}},
+	}
+	for _, c := range cases {
+		r := m.StreamRestorer()
+		var got []string
+		for _, delta := range c.deltas {
+			got = append(got, r.Restore(delta))
+		}
+		got = append(got, r.Flush())
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: %q, want %q", c.name, got, c.want)
+		}
+		if r.Flush() != "" {
+			t.Errorf("%s: second flush not empty", c.name)
+		}
+	}
+
+	// 没有占位符时原样输出
+	var none *Masked
+	r := none.StreamRestorer()
+	if got := r.Restore("a ⟦") + r.Flush(); got != "a ⟦" {
+		t.Errorf("nil restorer = %q", got)
+	}
+	if out := strings.Join(Modes(), ","); out != "off,warn,strict" {
+		t.Errorf("Modes = %s", out)
+	}
+}
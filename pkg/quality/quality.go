@@ -0,0 +1,116 @@
+package quality
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// chrF 的默认参数：字符 n-gram 最大长度 6，召回率权重 β=2
+	chrFMaxOrder = 6
+	chrFBeta     = 2
+	// 长度小于该值的词不参与未翻译判断，避免 a、is 等短词误判
+	minUntranslatedTokenLen = 3
+)
+
+// ChrF 计算 hypothesis 相对 reference 的字符 n-gram F 值，忽略空白，取值 [0, 1]
+// 回译场景下 hypothesis 为回译结果，reference 为原文
+func ChrF(hypothesis, reference string) float64 {
+	hyp, ref := stripSpace(hypothesis), stripSpace(reference)
+	if len(hyp) == 0 && len(ref) == 0 {
+		return 1
+	}
+	if len(hyp) == 0 || len(ref) == 0 {
+		return 0
+	}
+	var precision, recall float64
+	orders := 0
+	for n := 1; n <= chrFMaxOrder; n++ {
+		hypCounts, hypTotal := charNgrams(hyp, n)
+		refCounts, refTotal := charNgrams(ref, n)
+		if hypTotal == 0 || refTotal == 0 {
+			break
+		}
+		matches := 0
+		for gram, count := range hypCounts {
+			matches += min(count, refCounts[gram])
+		}
+		precision += float64(matches) / float64(hypTotal)
+		recall += float64(matches) / float64(refTotal)
+		orders++
+	}
+	precision /= float64(orders)
+	recall /= float64(orders)
+	if precision+recall == 0 {
+		return 0
+	}
+	beta2 := float64(chrFBeta * chrFBeta)
+	return (1 + beta2) * precision * recall / (beta2*precision + recall)
+}
+
+// LengthRatio 译文与原文的字符数之比，原文为空时返回 0
+func LengthRatio(source, translation string) float64 {
+	sourceLen := utf8.RuneCountInString(strings.TrimSpace(source))
+	if sourceLen == 0 {
+		return 0
+	}
+	return float64(utf8.RuneCountInString(strings.TrimSpace(translation))) / float64(sourceLen)
+}
+
+// UntranslatedRatio 原文中原样出现在译文里的词所占比例，取值 [0, 1]
+// 数字、短词以及 keep 中无需翻译的术语不计入，原文没有可判断的词时返回 0
+func UntranslatedRatio(source, translation string, keep []string) float64 {
+	skip := make(map[string]bool)
+	for _, term := range keep {
+		for _, token := range tokenize(term) {
+			skip[token] = true
+		}
+	}
+	inTranslation := make(map[string]bool)
+	for _, token := range tokenize(translation) {
+		inTranslation[token] = true
+	}
+	total, untranslated := 0, 0
+	for _, token := range tokenize(source) {
+		if skip[token] || utf8.RuneCountInString(token) < minUntranslatedTokenLen {
+			continue
+		}
+		total++
+		if inTranslation[token] {
+			untranslated++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(untranslated) / float64(total)
+}
+
+func stripSpace(s string) []rune {
+	runes := make([]rune, 0, len(s))
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+func charNgrams(runes []rune, n int) (map[string]int, int) {
+	if len(runes) < n {
+		return nil, 0
+	}
+	counts := make(map[string]int)
+	for i := 0; i+n <= len(runes); i++ {
+		counts[string(runes[i:i+n])]++
+	}
+	return counts, len(runes) - n + 1
+}
+
+// tokenize 按非字母切分并转为小写，只包含数字或符号的片段会被丢弃
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r)
+	})
+}
@@ -0,0 +1,255 @@
+package segmenter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DefaultMaxTokens     = 1500
+	DefaultOverlapTokens = 100
+)
+
+// Segment 切分后的一段文本
+// 原文 = Leading + Text + Trailing 依次拼接，翻译后只替换 Text 即可按原样还原
+type Segment struct {
+	Index    int
+	Text     string
+	Leading  string
+	Trailing string
+	// Context 上一段末尾的原文，仅作为参考上下文提供给模型，不参与翻译
+	Context string
+}
+
+type Options struct {
+	MaxTokens     int
+	OverlapTokens int
+}
+
+var paragraphSep = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// Split 按段落、句子的边界把文本切分为不超过 MaxTokens 的片段
+func Split(text string, opt Options) []Segment {
+	if opt.MaxTokens <= 0 {
+		opt.MaxTokens = DefaultMaxTokens
+	}
+	if opt.OverlapTokens < 0 {
+		opt.OverlapTokens = 0
+	}
+
+	var units []string
+	for _, paragraph := range splitKeepSep(text, paragraphSep) {
+		if EstimateTokens(paragraph) <= opt.MaxTokens {
+			units = append(units, paragraph)
+			continue
+		}
+		for _, sentence := range splitSentences(paragraph) {
+			if EstimateTokens(sentence) <= opt.MaxTokens {
+				units = append(units, sentence)
+				continue
+			}
+			units = append(units, hardSplit(sentence, opt.MaxTokens)...)
+		}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentTokens := 0
+	for _, unit := range units {
+		// 纯空白并入当前片段，避免产生没有正文的片段
+		if strings.TrimSpace(unit) == "" {
+			current.WriteString(unit)
+			continue
+		}
+		tokens := EstimateTokens(unit)
+		if current.Len() > 0 && currentTokens+tokens > opt.MaxTokens {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+		current.WriteString(unit)
+		currentTokens += tokens
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	segments := make([]Segment, 0, len(chunks))
+	for i, chunk := range chunks {
+		body := strings.TrimLeftFunc(chunk, unicode.IsSpace)
+		leading := chunk[:len(chunk)-len(body)]
+		trimmed := strings.TrimRightFunc(body, unicode.IsSpace)
+		seg := Segment{
+			Index:    i,
+			Text:     trimmed,
+			Leading:  leading,
+			Trailing: body[len(trimmed):],
+		}
+		if i > 0 && opt.OverlapTokens > 0 {
+			seg.Context = tail(segments[i-1].Text, opt.OverlapTokens)
+		}
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+// Join 按顺序用译文替换各片段的 Text 并还原空白
+func Join(segments []Segment, translations []string) string {
+	var sb strings.Builder
+	for i, seg := range segments {
+		sb.WriteString(seg.Leading)
+		if i < len(translations) {
+			sb.WriteString(translations[i])
+		}
+		sb.WriteString(seg.Trailing)
+	}
+	return sb.String()
+}
+
+// EstimateTokens 粗略估算 token 数：CJK 字符每个算 1，其它字符每 4 个算 1
+func EstimateTokens(s string) int {
+	cjk, other := 0, 0
+	for _, r := range s {
+		if isCJK(r) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// splitKeepSep 切分文本，分隔符保留在前一段的末尾
+func splitKeepSep(text string, sep *regexp.Regexp) []string {
+	var parts []string
+	last := 0
+	for _, loc := range sep.FindAllStringIndex(text, -1) {
+		parts = append(parts, text[last:loc[1]])
+		last = loc[1]
+	}
+	if last < len(text) {
+		parts = append(parts, text[last:])
+	}
+	return parts
+}
+
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?', ';', '。', '！', '？', '；', '…':
+		return true
+	}
+	return false
+}
+
+// splitSentences 在句末标点处切分，西文标点要求后面跟空白，中文标点直接切分
+func splitSentences(text string) []string {
+	var parts []string
+	start := 0
+	for i, r := range text {
+		if !isSentenceEnd(r) {
+			continue
+		}
+		end := i + utf8.RuneLen(r)
+		if r < utf8.RuneSelf {
+			next, _ := utf8.DecodeRuneInString(text[end:])
+			if end < len(text) && !unicode.IsSpace(next) {
+				continue
+			}
+		}
+		// 句末的空白归属当前句子
+		for end < len(text) {
+			next, size := utf8.DecodeRuneInString(text[end:])
+			if !unicode.IsSpace(next) {
+				break
+			}
+			end += size
+		}
+		if end > start {
+			parts = append(parts, text[start:end])
+			start = end
+		}
+	}
+	if start < len(text) {
+		parts = append(parts, text[start:])
+	}
+	return parts
+}
+
+// hardSplit 没有可用的句子边界时按预算强制切分，尽量在空白处断开
+func hardSplit(text string, maxTokens int) []string {
+	var parts []string
+	for EstimateTokens(text) > maxTokens {
+		cut, lastSpace := 0, 0
+		cjk, other := 0, 0
+		for i, r := range text {
+			if isCJK(r) {
+				cjk++
+			} else {
+				other++
+			}
+			if cjk+(other+3)/4 > maxTokens {
+				break
+			}
+			cut = i + utf8.RuneLen(r)
+			if unicode.IsSpace(r) {
+				lastSpace = cut
+			}
+		}
+		if lastSpace > 0 {
+			cut = lastSpace
+		}
+		if cut == 0 {
+			_, cut = utf8.DecodeRuneInString(text)
+		}
+		parts = append(parts, text[:cut])
+		text = text[cut:]
+	}
+	if text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+// tail 取文本末尾不超过 maxTokens 的部分，优先从句子边界开始
+func tail(text string, maxTokens int) string {
+	if EstimateTokens(text) <= maxTokens {
+		return text
+	}
+	sentences := splitSentences(text)
+	result := ""
+	for i := len(sentences) - 1; i >= 0; i-- {
+		candidate := sentences[i] + result
+		if EstimateTokens(candidate) > maxTokens {
+			break
+		}
+		result = candidate
+	}
+	if result != "" {
+		return strings.TrimSpace(result)
+	}
+	// 最后一句也超出预算时按字符截取
+	runes := []rune(text)
+	start := len(runes)
+	cjk, other := 0, 0
+	for start > 0 {
+		if isCJK(runes[start-1]) {
+			cjk++
+		} else {
+			other++
+		}
+		if cjk+(other+3)/4 > maxTokens {
+			break
+		}
+		start--
+	}
+	return strings.TrimSpace(string(runes[start:]))
+}
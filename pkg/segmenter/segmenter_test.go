@@ -0,0 +1,177 @@
+package segmenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	cases := []struct {
+		text   string
+		tokens int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"你好世界", 4},
+		{"こんにちは", 5},
+		{"안녕", 2},
+		{"你好 ab", 3},
+	}
+	for _, c := range cases {
+		if got := EstimateTokens(c.text); got != c.tokens {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", c.text, got, c.tokens)
+		}
+	}
+}
+
+func TestSplitWithinBudget(t *testing.T) {
+	sentence := "The quick brown fox jumps over the lazy dog. "
+	cases := []struct {
+		name      string
+		text      string
+		maxTokens int
+		minParts  int
+	}{
+		{"short", "Hello world.", 100, 1},
+		{"paragraphs", strings.Repeat(strings.Repeat(sentence, 3)+"\n\n", 6), 40, 6},
+		{"sentences", strings.Repeat(sentence, 20), 30, 10},
+		{"no boundary", strings.Repeat("word ", 200), 25, 10},
+		{"no whitespace", strings.Repeat("x", 500), 20, 7},
+		{"cjk", strings.Repeat("这是一个用于测试的中文句子。", 30), 50, 8},
+		{"cjk without punctuation", strings.Repeat("中", 120), 50, 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			segments := Split(c.text, Options{MaxTokens: c.maxTokens})
+			if len(segments) < c.minParts {
+				t.Fatalf("got %d segments, want at least %d", len(segments), c.minParts)
+			}
+			for i, seg := range segments {
+				if seg.Index != i {
+					t.Errorf("segment %d has index %d", i, seg.Index)
+				}
+				if seg.Text == "" {
+					t.Errorf("segment %d is empty", i)
+				}
+				if tokens := EstimateTokens(seg.Text); tokens > c.maxTokens {
+					t.Errorf("segment %d has %d tokens, budget %d", i, tokens, c.maxTokens)
+				}
+			}
+			if got := Join(segments, texts(segments)); got != c.text {
+				t.Errorf("Join does not restore the original text:\n got %q\nwant %q", got, c.text)
+			}
+		})
+	}
+}
+
+func TestSplitPrefersParagraphs(t *testing.T) {
+	first := strings.Repeat("First paragraph sentence. ", 4)
+	second := strings.Repeat("Second paragraph sentence. ", 4)
+	text := first + "\n\n" + second
+	segments := Split(text, Options{MaxTokens: 40})
+	if len(segments) != 2 {
+		t.Fatalf("got %d segments, want 2: %#v", len(segments), segments)
+	}
+	if segments[0].Text != strings.TrimSpace(first) || segments[1].Text != strings.TrimSpace(second) {
+		t.Errorf("segments do not follow the paragraph boundary: %#v", segments)
+	}
+	if segments[0].Trailing != " \n\n" {
+		t.Errorf("Trailing = %q", segments[0].Trailing)
+	}
+}
+
+func TestSplitKeepsWhitespace(t *testing.T) {
+	text := "\n  Leading.\n\n\tMiddle.  \n\nTrailing.\n\n"
+	segments := Split(text, Options{MaxTokens: 3})
+	if len(segments) != 3 {
+		t.Fatalf("got %d segments, want 3: %#v", len(segments), segments)
+	}
+	if segments[0].Leading != "\n  " {
+		t.Errorf("Leading = %q", segments[0].Leading)
+	}
+	if got := Join(segments, texts(segments)); got != text {
+		t.Errorf("Join = %q, want %q", got, text)
+	}
+}
+
+func TestSplitOverlapContext(t *testing.T) {
+	text := "One sentence here. Two sentence here. Three sentence here.\n\n" +
+		"Four sentence here. Five sentence here.\n\nSix sentence here."
+	cases := []struct {
+		name    string
+		overlap int
+	}{
+		{"disabled", 0},
+		{"one sentence", 5},
+		{"whole segment", 100},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			segments := Split(text, Options{MaxTokens: 16, OverlapTokens: c.overlap})
+			if len(segments) < 2 {
+				t.Fatalf("got %d segments, want at least 2", len(segments))
+			}
+			if segments[0].Context != "" {
+				t.Errorf("first segment has context %q", segments[0].Context)
+			}
+			for i := 1; i < len(segments); i++ {
+				ctx := segments[i].Context
+				if c.overlap == 0 {
+					if ctx != "" {
+						t.Errorf("segment %d has context %q with overlap disabled", i, ctx)
+					}
+					continue
+				}
+				if ctx == "" || !strings.HasSuffix(segments[i-1].Text, ctx) {
+					t.Errorf("segment %d context %q is not the tail of %q", i, ctx, segments[i-1].Text)
+				}
+				if EstimateTokens(ctx) > c.overlap {
+					t.Errorf("segment %d context %q exceeds %d tokens", i, ctx, c.overlap)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitOverlapStartsAtSentence(t *testing.T) {
+	text := "Alpha beta gamma. Delta epsilon.\n\nZeta eta theta."
+	segments := Split(text, Options{MaxTokens: 10, OverlapTokens: 5})
+	if len(segments) != 2 {
+		t.Fatalf("got %d segments, want 2: %#v", len(segments), segments)
+	}
+	if segments[1].Context != "Delta epsilon." {
+		t.Errorf("Context = %q, want the last sentence", segments[1].Context)
+	}
+}
+
+func TestJoinOrder(t *testing.T) {
+	text := strings.Repeat("Sentence number here. ", 30)
+	segments := Split(text, Options{MaxTokens: 12})
+	translations := make([]string, len(segments))
+	for i := range segments {
+		translations[i] = "<" + strings.Repeat("x", i+1) + ">"
+	}
+	got := Join(segments, translations)
+
+	var want strings.Builder
+	for i, seg := range segments {
+		want.WriteString(seg.Leading + translations[i] + seg.Trailing)
+	}
+	if got != want.String() {
+		t.Errorf("Join = %q, want %q", got, want.String())
+	}
+	// 缺少的译文按空串处理，不影响其它片段的位置
+	if got := Join(segments[:2], translations[:1]); got != segments[0].Leading+translations[0]+segments[0].Trailing+
+		segments[1].Leading+segments[1].Trailing {
+		t.Errorf("Join with missing translations = %q", got)
+	}
+}
+
+func texts(segments []Segment) []string {
+	out := make([]string, len(segments))
+	for i, seg := range segments {
+		out[i] = seg.Text
+	}
+	return out
+}
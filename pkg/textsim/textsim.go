@@ -0,0 +1,115 @@
+package textsim
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// Normalize 去掉首尾空白并把连续空白合并为一个空格
+func Normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Hash 对归一化后的文本计算 sha256
+func Hash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// Similarity 基于编辑距离的相似度，取值 [0, 1]
+func Similarity(a, b string) float64 {
+	score, _ := SimilarityAtLeast(a, b, 0)
+	return score
+}
+
+// SimilarityAtLeast 计算相似度并判断是否达到 threshold
+// 未达到阈值时会提前结束计算，返回的分数不保证精确
+func SimilarityAtLeast(a, b string, threshold float64) (float64, bool) {
+	ra, rb := []rune(a), []rune(b)
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+	if maxLen == 0 {
+		return 1, true
+	}
+	// 相似度 >= threshold 等价于编辑距离 <= (1-threshold)*maxLen
+	limit := int((1 - threshold) * float64(maxLen))
+	distance, ok := boundedLevenshtein(ra, rb, limit)
+	if !ok {
+		return 1 - float64(limit+1)/float64(maxLen), false
+	}
+	score := 1 - float64(distance)/float64(maxLen)
+	return score, score >= threshold
+}
+
+// boundedLevenshtein 只计算对角线附近宽度为 limit 的区域，距离超过 limit 时返回 false
+func boundedLevenshtein(a, b []rune, limit int) (int, bool) {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(a)-len(b) > limit {
+		return 0, false
+	}
+	if len(b) == 0 {
+		return len(a), len(a) <= limit
+	}
+	const inf = int(^uint(0) >> 2)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		if j <= limit {
+			prev[j] = j
+		} else {
+			prev[j] = inf
+		}
+	}
+	for i := 1; i <= len(a); i++ {
+		lo, hi := i-limit, i+limit
+		if lo < 1 {
+			lo = 1
+		}
+		if hi > len(b) {
+			hi = len(b)
+		}
+		for j := range curr {
+			curr[j] = inf
+		}
+		if i <= limit {
+			curr[0] = i
+		}
+		rowMin := curr[0]
+		for j := lo; j <= hi; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			v := prev[j-1] + cost
+			if prev[j]+1 < v {
+				v = prev[j] + 1
+			}
+			if curr[j-1]+1 < v {
+				v = curr[j-1] + 1
+			}
+			curr[j] = v
+			if v < rowMin {
+				rowMin = v
+			}
+		}
+		if rowMin > limit {
+			return 0, false
+		}
+		prev, curr = curr, prev
+	}
+	if prev[len(b)] > limit {
+		return 0, false
+	}
+	return prev[len(b)], true
+}
+
+// RuneLen 文本的字符数
+func RuneLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -19,3 +19,11 @@ const (
 	userAlreadyExistCode = 91000
 	userNotExistCode     = 91001
 )
+
+const (
+	quotaExceededCode = 92000
+)
+
+const (
+	serviceBusyCode = 93000
+)
@@ -165,3 +165,27 @@ func UseNotExist(msg string) *APIError {
 		Message:   msg,
 	}
 }
+
+// QuotaExceeded 用户配额不足
+func QuotaExceeded(msg string) *APIError {
+	if msg == "" {
+		msg = "quota exceeded"
+	}
+	return &APIError{
+		Code:      http.StatusTooManyRequests,
+		ErrorCode: quotaExceededCode,
+		Message:   msg,
+	}
+}
+
+// ServiceBusy 服务繁忙，稍后重试
+func ServiceBusy(msg string) *APIError {
+	if msg == "" {
+		msg = "service busy"
+	}
+	return &APIError{
+		Code:      http.StatusServiceUnavailable,
+		ErrorCode: serviceBusyCode,
+		Message:   msg,
+	}
+}